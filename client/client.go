@@ -0,0 +1,283 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+)
+
+const contentTypeJSON = "application/json"
+
+// Client send requests to gorest server, it's shared by the generated
+// typed clients
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	header     http.Header
+}
+
+func New(baseURL string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		header:     make(http.Header),
+	}
+}
+
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
+// header is added to all the requests, like Authorization and
+// Accept-Language
+func (c *Client) SetHeader(key, value string) {
+	c.header.Set(key, value)
+}
+
+// Do send the request with input as json body, and decode the response
+// body to output, error response is returned as *goresterr.APIError
+func (c *Client) Do(ctx context.Context, method, p string, query url.Values, input, output interface{}) error {
+	return c.do(ctx, method, p, query, contentTypeJSON, input, output)
+}
+
+func (c *Client) do(ctx context.Context, method, p string, query url.Values, contentType string, input, output interface{}) error {
+	var body io.Reader
+	if input != nil {
+		data, err := json.Marshal(input)
+		if err != nil {
+			return fmt.Errorf("marshal request body failed: %s", err.Error())
+		}
+		body = bytes.NewReader(data)
+	}
+
+	u := c.baseURL + p
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	if input != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response body failed: %s", err.Error())
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return decodeAPIError(resp.StatusCode, data)
+	}
+
+	if output == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, output); err != nil {
+		return fmt.Errorf("unmarshal response body failed: %s", err.Error())
+	}
+	return nil
+}
+
+// response without api error in body, like the one from proxy, is
+// converted to api error with http status
+func decodeAPIError(status int, data []byte) *goresterr.APIError {
+	var apiErr goresterr.APIError
+	if err := json.Unmarshal(data, &apiErr); err == nil && apiErr.Code != "" {
+		if apiErr.Status == 0 {
+			apiErr.Status = status
+		}
+		return &apiErr
+	}
+
+	message := strings.TrimSpace(string(data))
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	return &goresterr.APIError{
+		ErrorCode: goresterr.ErrorCode{Code: http.StatusText(status), Status: status},
+		Type:      "error",
+		Message:   message,
+	}
+}
+
+func AsAPIError(err error) (*goresterr.APIError, bool) {
+	var apiErr *goresterr.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+func IsNotFound(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.Status == goresterr.NotFound.Status
+}
+
+// JoinPath join collection path with id and child name, id is escaped
+func JoinPath(collectionPath, id, child string) string {
+	return path.Join(collectionPath, url.PathEscape(id), child)
+}
+
+type ListOptions struct {
+	Filters  []resource.Filter
+	PageSize int
+	PageNum  int
+	//keyset pagination, empty for the first page
+	Continue string
+	Sorts    []resource.Sort
+	Fields   []string
+}
+
+// Query convert the options to query parameters which are parsed by
+// resource.Context
+func (o *ListOptions) Query() url.Values {
+	query := make(url.Values)
+	if o == nil {
+		return query
+	}
+
+	for _, filter := range o.Filters {
+		name := filter.Name
+		if filter.Modifier != "" && filter.Modifier != resource.Eq {
+			name += "_" + string(filter.Modifier)
+		}
+		query[name] = append(query[name], filter.Values...)
+	}
+
+	if o.PageSize > 0 {
+		query.Set(resource.FilterNamePageSize, strconv.Itoa(o.PageSize))
+	}
+	if o.PageNum > 0 {
+		query.Set(resource.FilterNamePageNum, strconv.Itoa(o.PageNum))
+	}
+	if o.Continue != "" {
+		query.Set(resource.FilterNameContinue, o.Continue)
+	}
+
+	if len(o.Sorts) > 0 {
+		sorts := make([]string, 0, len(o.Sorts))
+		for _, sort := range o.Sorts {
+			if sort.Order == resource.SortDesc {
+				sorts = append(sorts, "-"+sort.Field)
+			} else {
+				sorts = append(sorts, sort.Field)
+			}
+		}
+		query.Set(resource.FilterNameSort, strings.Join(sorts, ","))
+	}
+
+	if len(o.Fields) > 0 {
+		query.Set(resource.FilterNameFields, strings.Join(o.Fields, ","))
+	}
+	return query
+}
+
+type GetOptions struct {
+	Fields []string
+}
+
+func (o *GetOptions) Query() url.Values {
+	query := make(url.Values)
+	if o != nil && len(o.Fields) > 0 {
+		query.Set(resource.FilterNameFields, strings.Join(o.Fields, ","))
+	}
+	return query
+}
+
+type Collection[T any] struct {
+	Type         string                                              `json:"type,omitempty"`
+	ResourceType string                                              `json:"resourceType,omitempty"`
+	Links        map[resource.ResourceLinkType]resource.ResourceLink `json:"links,omitempty"`
+	Pagination   *resource.Pagination                                `json:"pagination,omitempty"`
+	Data         []*T                                                `json:"data"`
+}
+
+// NextContinue return the continue token in next link, empty means
+// current page is the last one
+func (c *Collection[T]) NextContinue() string {
+	next, ok := c.Links[resource.NextLink]
+	if ok == false {
+		return ""
+	}
+
+	u, err := url.Parse(string(next))
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get(resource.FilterNameContinue)
+}
+
+func List[T any](ctx context.Context, c *Client, collectionPath string, opts *ListOptions) (*Collection[T], error) {
+	var collection Collection[T]
+	if err := c.Do(ctx, http.MethodGet, collectionPath, opts.Query(), nil, &collection); err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+func Get[T any](ctx context.Context, c *Client, collectionPath, id string, opts *GetOptions) (*T, error) {
+	var r T
+	if err := c.Do(ctx, http.MethodGet, JoinPath(collectionPath, id, ""), opts.Query(), nil, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func Create[T any](ctx context.Context, c *Client, collectionPath string, r *T) (*T, error) {
+	var created T
+	if err := c.Do(ctx, http.MethodPost, collectionPath, nil, r, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func Update[T any](ctx context.Context, c *Client, collectionPath, id string, r *T) (*T, error) {
+	var updated T
+	if err := c.Do(ctx, http.MethodPut, JoinPath(collectionPath, id, ""), nil, r, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// Patch send patch as json merge patch, fields which are nil or omitted
+// are kept unchanged, null in map patch clears the field
+func Patch[T any](ctx context.Context, c *Client, collectionPath, id string, patch interface{}) (*T, error) {
+	var patched T
+	if err := c.do(ctx, http.MethodPatch, JoinPath(collectionPath, id, ""), nil, string(resource.MergePatch), patch, &patched); err != nil {
+		return nil, err
+	}
+	return &patched, nil
+}
+
+func Delete(ctx context.Context, c *Client, collectionPath, id string) error {
+	return c.Do(ctx, http.MethodDelete, JoinPath(collectionPath, id, ""), nil, nil, nil)
+}
+
+// Action post the input to the resource with action in query, output is
+// nil for action without output
+func Action(ctx context.Context, c *Client, collectionPath, id, action string, input, output interface{}) error {
+	query := url.Values{"action": []string{action}}
+	return c.Do(ctx, http.MethodPost, JoinPath(collectionPath, id, ""), query, input, output)
+}
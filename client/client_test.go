@@ -0,0 +1,147 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	ut "github.com/linkingthing/cement/unittest"
+	"github.com/linkingthing/gorest"
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+	"github.com/linkingthing/gorest/resource/schema"
+)
+
+var version = resource.APIVersion{
+	Group:   "testing",
+	Version: "v1",
+}
+
+type Cluster struct {
+	resource.ResourceBase `json:",inline"`
+	Name                  string `json:"name" rest:"required=true,minLen=2"`
+}
+
+type Scale struct {
+	Size int `json:"size"`
+}
+
+func (c Cluster) GetActions() []resource.Action {
+	return []resource.Action{{Name: "scale", Input: &Scale{}, Output: &Scale{}}}
+}
+
+type clusterHandler struct {
+	clusters    map[string]*Cluster
+	query       url.Values
+	contentType string
+}
+
+func (h *clusterHandler) Create(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	cluster := ctx.Resource.(*Cluster)
+	cluster.SetID(cluster.Name)
+	h.clusters[cluster.GetID()] = cluster
+	return cluster, nil
+}
+
+func (h *clusterHandler) List(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+	h.query = ctx.Request.URL.Query()
+	var clusters []*Cluster
+	for _, cluster := range h.clusters {
+		clusters = append(clusters, cluster)
+	}
+	return clusters, nil
+}
+
+func (h *clusterHandler) Get(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	if cluster, ok := h.clusters[ctx.Resource.GetID()]; ok {
+		return cluster, nil
+	}
+	return nil, nil
+}
+
+func (h *clusterHandler) Patch(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	h.contentType = ctx.Request.Header.Get("Content-Type")
+	cluster := ctx.Resource.(*Cluster)
+	h.clusters[cluster.GetID()] = cluster
+	return cluster, nil
+}
+
+func (h *clusterHandler) Delete(ctx *resource.Context) *goresterr.APIError {
+	delete(h.clusters, ctx.Resource.GetID())
+	return nil
+}
+
+func (h *clusterHandler) Action(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+	input := ctx.Resource.GetAction().Input.(*Scale)
+	return &Scale{Size: input.Size * 2}, nil
+}
+
+func TestClient(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	handler := &clusterHandler{clusters: make(map[string]*Cluster)}
+	schemas.MustImport(&version, Cluster{}, handler)
+	server := httptest.NewServer(gorest.NewAPIServer(schemas))
+	defer server.Close()
+
+	ctx := context.Background()
+	c := New(server.URL)
+	collectionPath := version.GetUrl() + "/clusters"
+	created, err := Create(ctx, c, collectionPath, &Cluster{Name: "c1"})
+	ut.Assert(t, err == nil, "create failed:%v", err)
+	ut.Equal(t, created.GetID(), "c1")
+
+	_, err = Create(ctx, c, collectionPath, &Cluster{Name: "c"})
+	apiErr, ok := AsAPIError(err)
+	ut.Assert(t, ok, "")
+	ut.Equal(t, apiErr.ErrorCode, goresterr.MinLengthExceeded)
+
+	cluster, err := Get[Cluster](ctx, c, collectionPath, "c1", nil)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, cluster.Name, "c1")
+	_, err = Get[Cluster](ctx, c, collectionPath, "c2", nil)
+	ut.Assert(t, IsNotFound(err), "")
+
+	clusters, err := List[Cluster](ctx, c, collectionPath, &ListOptions{
+		Filters: []resource.Filter{{Name: "name", Modifier: resource.Prefix, Values: []string{"c"}}},
+		Sorts:   []resource.Sort{{Field: "name", Order: resource.SortDesc}},
+		Fields:  []string{"name"},
+	})
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, len(clusters.Data), 1)
+	ut.Equal(t, clusters.Data[0].Name, "c1")
+	ut.Equal(t, handler.query.Get("name_prefix"), "c")
+	ut.Equal(t, handler.query.Get("sort"), "-name")
+	ut.Equal(t, handler.query.Get("fields"), "name")
+
+	patched, err := Patch[Cluster](ctx, c, collectionPath, "c1", map[string]interface{}{"name": "c11"})
+	ut.Assert(t, err == nil, "patch failed:%v", err)
+	ut.Equal(t, patched.Name, "c11")
+	ut.Equal(t, handler.contentType, string(resource.MergePatch))
+
+	var output Scale
+	ut.Assert(t, Action(ctx, c, collectionPath, "c1", "scale", &Scale{Size: 2}, &output) == nil, "")
+	ut.Equal(t, output.Size, 4)
+
+	ut.Assert(t, Delete(ctx, c, collectionPath, "c1") == nil, "")
+	ut.Equal(t, len(handler.clusters), 0)
+}
+
+func TestDecodeAPIError(t *testing.T) {
+	err := decodeAPIError(http.StatusBadGateway, []byte("bad gateway\n"))
+	ut.Equal(t, err.Status, http.StatusBadGateway)
+	ut.Equal(t, err.Message, "bad gateway")
+
+	err = decodeAPIError(http.StatusNotFound, []byte(`{"code":"NotFound","status":404,"type":"error","message":"not found"}`))
+	ut.Equal(t, err.ErrorCode, goresterr.NotFound)
+	ut.Equal(t, err.Message, "not found")
+}
+
+func TestNextContinue(t *testing.T) {
+	collection := &Collection[Cluster]{Links: map[resource.ResourceLinkType]resource.ResourceLink{
+		resource.NextLink: "http://127.0.0.1/apis/testing/v1/clusters?page_size=10&continue=abc",
+	}}
+	ut.Equal(t, collection.NextContinue(), "abc")
+	ut.Equal(t, (&Collection[Cluster]{}).NextContinue(), "")
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+
+	"github.com/linkingthing/gorest/client"
+	"github.com/linkingthing/gorest/resource"
+	"github.com/linkingthing/gorest/resource/schema"
+	"github.com/linkingthing/gorest/resource/schema/clientgen"
+	"github.com/linkingthing/gorest/resource/schema/resourcedoc"
+)
+
+// gorest-client-gen generate typed client from the discovery endpoints
+// of a running gorest server, like
+// gorest-client-gen -server http://127.0.0.1:8088 -group testing -version v1 -package testingv1 -output ./testingv1
+func main() {
+	var server, group, version, pkgName, output string
+	flag.StringVar(&server, "server", "http://127.0.0.1:8088", "address of the gorest server")
+	flag.StringVar(&group, "group", "", "api group")
+	flag.StringVar(&version, "version", "", "api version")
+	flag.StringVar(&pkgName, "package", "", "package name of the generated client, default is the base name of output")
+	flag.StringVar(&output, "output", ".", "directory of the generated client")
+	flag.Parse()
+
+	if group == "" || version == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if pkgName == "" {
+		pkgName = path.Base(output)
+	}
+
+	if err := generate(server, resource.APIVersion{Group: group, Version: version}, pkgName, output); err != nil {
+		fmt.Fprintf(os.Stderr, "generate client failed: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func generate(server string, v resource.APIVersion, pkgName, output string) error {
+	ctx := context.Background()
+	c := client.New(server)
+	var resources schema.APIResourceList
+	if err := c.Do(ctx, http.MethodGet, v.GetUrl(), nil, nil, &resources); err != nil {
+		return fmt.Errorf("get resources of %s failed: %s", v.GetUrl(), err.Error())
+	}
+
+	api := &clientgen.API{Version: v}
+	for _, r := range resources.Resources {
+		var doc resourcedoc.ResourceDocument
+		if err := c.Do(ctx, http.MethodGet, string(r.Link), nil, nil, &doc); err != nil {
+			return fmt.Errorf("get schema of %s failed: %s", r.ResourceType, err.Error())
+		}
+		api.Resources = append(api.Resources, &doc)
+	}
+
+	src, err := clientgen.Generate(pkgName, api)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(output, os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(path.Join(output, clientgen.FileName(v)), src, 0644)
+}
@@ -0,0 +1,148 @@
+package gorest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ut "github.com/linkingthing/cement/unittest"
+	"github.com/linkingthing/gorest/resource"
+	"github.com/linkingthing/gorest/resource/schema"
+)
+
+var version2 = resource.APIVersion{
+	Group:   "testing",
+	Version: "v2",
+}
+
+// kinds of v1 are the hubs, kinds of v2 with same name are declared in
+// test functions
+type hubBaz = Baz
+type hubQux = Qux
+
+func TestConversion(t *testing.T) {
+	//baz of v2 renames name to fullName
+	type Baz struct {
+		resource.ResourceBase `json:",inline"`
+		FullName              string `json:"fullName"`
+		Size                  int    `json:"size"`
+	}
+
+	schemas := schema.NewSchemaManager()
+	hub := &hubBaz{Name: "baz", Size: 10}
+	hub.SetID("b1")
+	hub.SetResourceVersion(1)
+	handler := &bazHandler{baz: hub}
+	schemas.MustImport(&version, hubBaz{}, handler)
+	schemas.MustImportConversion(&version2, Baz{}, &version, hubBaz{}, resource.NewConverter(
+		func(b *Baz) (*hubBaz, error) {
+			return &hubBaz{Name: b.FullName, Size: b.Size}, nil
+		},
+		func(b *hubBaz) (*Baz, error) {
+			return &Baz{FullName: b.Name, Size: b.Size}, nil
+		}))
+	s := NewAPIServer(schemas)
+
+	req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v2/bazs/b1", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	var result map[string]interface{}
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &result) == nil, "")
+	ut.Equal(t, result["fullName"], "baz")
+	ut.Equal(t, result["resourceVersion"], float64(1))
+	ut.Equal(t, result["links"].(map[string]interface{})["self"], "/apis/testing/v2/bazs/b1")
+	_, ok := result["name"]
+	ut.Assert(t, ok == false, "")
+	ut.Equal(t, w.Header().Get(ETagKey), `"1"`)
+
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v2/bazs", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	var collection struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &collection) == nil, "")
+	ut.Equal(t, len(collection.Data), 1)
+	ut.Equal(t, collection.Data[0]["fullName"], "baz")
+
+	req, _ = http.NewRequest(http.MethodPut, "/apis/testing/v2/bazs/b1", bytes.NewBufferString(`{"fullName":"baz2","size":20}`))
+	req.Header.Set(IfMatchKey, `"1"`)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, handler.baz.Name, "baz2")
+	ut.Equal(t, handler.baz.Size, 20)
+	ut.Equal(t, handler.baz.GetID(), "b1")
+	ut.Equal(t, w.Header().Get(ETagKey), `"2"`)
+
+	req, _ = http.NewRequest(http.MethodPut, "/apis/testing/v2/bazs/b1", bytes.NewBufferString(`{"fullName":"baz3","size":20}`))
+	req.Header.Set(IfMatchKey, `"1"`)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusConflict)
+
+	req, _ = http.NewRequest(http.MethodPatch, "/apis/testing/v2/bazs/b1", bytes.NewBufferString(`{"fullName":"baz4"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, handler.baz.Name, "baz4")
+	ut.Equal(t, handler.baz.Size, 20)
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &result) == nil, "")
+	ut.Equal(t, result["fullName"], "baz4")
+
+	//hub version is still served by the handler directly
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/bazs/b1", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &result) == nil, "")
+	ut.Equal(t, result["name"], "baz4")
+}
+
+func TestConversionWatch(t *testing.T) {
+	type Qux struct {
+		resource.ResourceBase `json:",inline"`
+		Title                 string `json:"title"`
+	}
+
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, hubQux{}, &quxHandler{quxs: make(map[string]*hubQux)})
+	schemas.MustImportConversion(&version2, Qux{}, &version, hubQux{}, resource.NewConverter(
+		func(q *Qux) (*hubQux, error) {
+			return &hubQux{Name: q.Title}, nil
+		},
+		func(q *hubQux) (*Qux, error) {
+			return &Qux{Title: q.Name}, nil
+		}))
+	server := httptest.NewServer(NewAPIServer(schemas))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/apis/testing/v2/quxes?watch=true")
+	ut.Assert(t, err == nil, "")
+	defer resp.Body.Close()
+	ut.Equal(t, resp.StatusCode, http.StatusOK)
+	reader := bufio.NewReader(resp.Body)
+
+	//created by v1, and watched by v2
+	createResp, err := http.Post(server.URL+"/apis/testing/v1/quxes", "application/json", bytes.NewBufferString(`{"name":"q1"}`))
+	ut.Assert(t, err == nil, "")
+	createResp.Body.Close()
+	ut.Equal(t, createResp.StatusCode, http.StatusCreated)
+
+	line, err := reader.ReadBytes('\n')
+	ut.Assert(t, err == nil, "")
+	var event struct {
+		Type     EventType              `json:"type"`
+		Resource map[string]interface{} `json:"resource"`
+	}
+	ut.Assert(t, json.Unmarshal(line, &event) == nil, "invalid event %s", line)
+	ut.Equal(t, event.Type, EventAdded)
+	ut.Equal(t, event.Resource["title"], "q1")
+	ut.Equal(t, event.Resource["id"], "q1")
+	ut.Equal(t, event.Resource["links"].(map[string]interface{})["remove"], "/apis/testing/v2/quxes/q1")
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"text/template"
@@ -27,6 +28,16 @@ const (
 	joinSqlTemplateContent string = "select {{.OwnedTable}}.* from {{.OwnedTable}} inner join {{.RelTable}} on ({{.OwnedTable}}.id={{.RelTable}}.{{.Owned}} and {{.RelTable}}.{{.Owner}}=$1)"
 )
 
+// reserved keys in conds which aren't column names
+const (
+	CondOrderBy   = "orderby"
+	CondLimit     = "limit"
+	CondOffset    = "offset"
+	CondSearch    = "search"
+	CondMatchList = "match_list"
+	CondColumns   = "columns"
+)
+
 var joinSqlTemplate *template.Template
 
 func init() {
@@ -62,13 +73,16 @@ func (b *BaseTx) insertSqlArgsAndID(r resource.Resource) (string, []interface{},
 	}
 
 	tableName := getTableName(b.schema, descriptor.Typ)
-	fieldCount := len(descriptor.Fields) + len(descriptor.Owners) + len(descriptor.Refers)
-	markers := make([]string, 0, fieldCount)
-	for i := 1; i <= fieldCount; i++ {
+	//columns added by migration are after resource version, so columns
+	//are specified explicitly
+	columns := insertColumns(descriptor)
+	markers := make([]string, 0, len(columns))
+	for i := 1; i <= len(columns); i++ {
 		markers = append(markers, "$"+strconv.Itoa(i))
 	}
-	sql := strings.Join([]string{"insert into", tableName, "values(", strings.Join(markers, ","), ")"}, " ")
-	args := make([]interface{}, 0, fieldCount)
+	sql := strings.Join([]string{"insert into", tableName, "(", strings.Join(columns, ","), ")",
+		"values(", strings.Join(markers, ","), ")"}, " ")
+	args := make([]interface{}, 0, len(columns))
 
 	id := r.GetID()
 	if id == "" {
@@ -100,43 +114,93 @@ func (b *BaseTx) insertSqlArgsAndID(r resource.Resource) (string, []interface{},
 		args = append(args, val.FieldByName(stringtool.ToUpperCamel(string(refer))).Interface())
 	}
 
+	args = append(args, r.GetResourceVersion())
 	return sql, args, nil
 }
 
+// columns in the order of insert args
+func insertColumns(descriptor *ResourceDescriptor) []string {
+	columns := make([]string, 0, len(descriptor.Fields)+len(descriptor.Owners)+len(descriptor.Refers)+1)
+	for _, field := range descriptor.Fields {
+		columns = append(columns, field.Name)
+	}
+
+	for _, owner := range descriptor.Owners {
+		columns = append(columns, string(owner))
+	}
+
+	for _, refer := range descriptor.Refers {
+		columns = append(columns, string(refer))
+	}
+	return append(columns, ResourceVersionField)
+}
+
 func (b *BaseTx) selectSqlAndArgs(typ ResourceType, conds map[string]any) (string, []any, error) {
 	descriptor, err := b.meta.GetDescriptor(typ)
 	if err != nil {
 		return "", nil, fmt.Errorf("get descriptor for %v failed %v", typ, err.Error())
 	}
 
-	orderStat := "order by id"
-	if order_, ok := conds["orderby"]; ok == true {
-		if order, ok := order_.(string); ok == false {
-			return "", nil, fmt.Errorf("order argument isn't string:%v", order_)
-		} else {
-			orderStat = fmt.Sprintf("order by %s", stringtool.ToSnake(order))
-			delete(conds, "orderby")
-		}
+	goTyp, err := b.meta.GetGoType(descriptor.Typ)
+	if err != nil {
+		return "", nil, err
 	}
 
+	orderColumns, err := parseOrderColumns(goTyp, conds[CondOrderBy])
+	if err != nil {
+		return "", nil, err
+	}
+	delete(conds, CondOrderBy)
+
 	limitStat := ""
-	if limit_, ok := conds["limit"]; ok == true {
-		if offset_, ok := conds["offset"]; ok == true {
-			limit, _ := limit_.(int)
+	if limit_, ok := conds[CondLimit]; ok == true {
+		limit, _ := limit_.(int)
+		delete(conds, CondLimit)
+		limitStat = fmt.Sprintf("limit %d", limit)
+		if offset_, ok := conds[CondOffset]; ok == true {
 			offset, _ := offset_.(int)
-			delete(conds, "limit")
-			delete(conds, "offset")
-			limitStat = fmt.Sprintf("limit %d offset %d", limit, offset)
+			delete(conds, CondOffset)
+			limitStat = fmt.Sprintf("%s offset %d", limitStat, offset)
 		}
 	}
 
+	token, isKeyset := conds[CondContinue]
+	delete(conds, CondContinue)
+	orderStat, err := orderSql(descriptor, orderColumns, isKeyset)
+	if err != nil {
+		return "", nil, err
+	}
+
+	columnsStat, err := selectColumnsSql(descriptor, goTyp, conds[CondColumns], orderColumns)
+	if err != nil {
+		return "", nil, err
+	}
+	delete(conds, CondColumns)
+
 	whereState, args, err := getSqlWhereState(conds)
 	if err != nil {
 		return "", nil, err
-	} else if whereState == "" {
-		return strings.Join([]string{"select * from ", getTableName(b.schema, descriptor.Typ), orderStat, limitStat}, " "), nil, nil
+	}
+
+	if isKeyset {
+		keysetState, keysetArgs, err := keysetSqlAndArgs(descriptor, token, orderColumns, len(args)+1)
+		if err != nil {
+			return "", nil, err
+		} else if keysetState != "" {
+			if whereState == "" {
+				whereState = keysetState
+			} else {
+				whereState = whereState + " and " + keysetState
+			}
+			args = append(args, keysetArgs...)
+		}
+	}
+
+	selectStat := "select " + columnsStat + " from"
+	if whereState == "" {
+		return strings.Join([]string{selectStat, getTableName(b.schema, descriptor.Typ), orderStat, limitStat}, " "), nil, nil
 	} else {
-		return strings.Join([]string{"select * from", getTableName(b.schema, descriptor.Typ), "where", whereState, orderStat, limitStat}, " "), args, nil
+		return strings.Join([]string{selectStat, getTableName(b.schema, descriptor.Typ), "where", whereState, orderStat, limitStat}, " "), args, nil
 	}
 }
 
@@ -146,30 +210,13 @@ func (b *BaseTx) deleteSqlAndArgs(typ ResourceType, conds map[string]interface{}
 		return "", nil, fmt.Errorf("get descriptor for %v failed %v", typ, err.Error())
 	}
 
-	if len(conds) == 0 {
+	whereState, args, err := getSqlWhereState(cloneConds(conds))
+	if err != nil {
+		return "", nil, err
+	} else if whereState == "" {
 		return "delete from " + getTableName(b.schema, descriptor.Typ), nil, nil
 	}
-
-	whereState := make([]string, 0, len(conds))
-	args := make([]interface{}, 0, len(conds))
-	markerSeq := 1
-	for k, v := range conds {
-		if vf, ok := v.(FillValue); ok {
-			s, arg, err := vf.buildSql(k, markerSeq)
-			if err != nil {
-				return "", nil, err
-			}
-			whereState = append(whereState, s)
-			args = append(args, arg)
-			markerSeq += 1
-		} else {
-			whereState = append(whereState, stringtool.ToSnake(k)+"=$"+strconv.Itoa(markerSeq))
-			args = append(args, v)
-			markerSeq += 1
-		}
-	}
-	whereSeq := strings.Join(whereState, " and ")
-	return strings.Join([]string{"delete from", getTableName(b.schema, descriptor.Typ), "where", whereSeq}, " "), args, nil
+	return strings.Join([]string{"delete from", getTableName(b.schema, descriptor.Typ), "where", whereState}, " "), args, nil
 }
 
 // select count(*) from zc_zone where zdnsuser=$1
@@ -179,32 +226,13 @@ func (b *BaseTx) existsSqlAndArgs(typ ResourceType, conds map[string]any) (strin
 		return "", nil, fmt.Errorf("get descriptor for %v failed %v", typ, err.Error())
 	}
 
-	if len(conds) == 0 {
+	whereState, args, err := getSqlWhereState(cloneConds(conds))
+	if err != nil {
+		return "", nil, err
+	} else if whereState == "" {
 		return "select (exists (select 1 from " + getTableName(b.schema, descriptor.Typ) + " limit 1))", nil, nil
 	}
-
-	whereState := make([]string, 0, len(conds))
-	args := make([]interface{}, 0, len(conds))
-	markerSeq := 1
-
-	for k, v := range conds {
-		if vf, ok := v.(FillValue); ok {
-			s, arg, err := vf.buildSql(k, markerSeq)
-			if err != nil {
-				return "", nil, err
-			}
-			whereState = append(whereState, s)
-			args = append(args, arg)
-			markerSeq += 1
-		} else {
-			whereState = append(whereState, stringtool.ToSnake(k)+"=$"+strconv.Itoa(markerSeq))
-			args = append(args, v)
-			markerSeq += 1
-		}
-	}
-
-	whereSeq := strings.Join(whereState, " and ")
-	return strings.Join([]string{"select (exists (select 1 from ", getTableName(b.schema, descriptor.Typ), "where", whereSeq, "limit 1))"}, " "), args, nil
+	return strings.Join([]string{"select (exists (select 1 from ", getTableName(b.schema, descriptor.Typ), "where", whereState, "limit 1))"}, " "), args, nil
 }
 
 // select count(*) from zc_zone where zdnsuser=$1
@@ -214,7 +242,7 @@ func (b *BaseTx) countSqlAndArgs(typ ResourceType, conds map[string]any) (string
 		return "", nil, fmt.Errorf("get descriptor for %v failed %v", typ, err.Error())
 	}
 
-	whereState, args, err := getSqlWhereState(conds)
+	whereState, args, err := getSqlWhereState(cloneConds(conds, CondOrderBy, CondLimit, CondOffset, CondContinue, CondColumns))
 	if err != nil {
 		return "", nil, err
 	} else if whereState == "" {
@@ -231,36 +259,34 @@ func (b *BaseTx) updateSqlAndArgs(typ ResourceType, newVals map[string]any, cond
 		return "", nil, fmt.Errorf("get descriptor for %v failed %v", typ, err.Error())
 	}
 
-	setState := make([]string, 0, len(newVals))
-	whereState := make([]string, 0, len(conds))
+	setState := make([]string, 0, len(newVals)+1)
 	args := make([]interface{}, 0, len(newVals)+len(conds))
 	markerSeq := 1
 	for k, v := range newVals {
-		setState = append(setState, stringtool.ToSnake(k)+"=$"+strconv.Itoa(markerSeq))
+		column := stringtool.ToSnake(k)
+		if column == ResourceVersionField {
+			continue
+		}
+		setState = append(setState, column+"=$"+strconv.Itoa(markerSeq))
 		args = append(args, v)
 		markerSeq += 1
 
 	}
+	//resource version is bumped on every update, put it into conds
+	//to do compare-and-swap
+	setState = append(setState, ResourceVersionField+"="+ResourceVersionField+"+1")
 
-	for k, v := range conds {
-		if vf, ok := v.(FillValue); ok {
-			s, arg, err := vf.buildSql(k, markerSeq)
-			if err != nil {
-				return "", nil, err
-			}
-			whereState = append(whereState, s)
-			args = append(args, arg)
-			markerSeq += 1
-		} else {
-			whereState = append(whereState, stringtool.ToSnake(k)+"=$"+strconv.Itoa(markerSeq))
-			args = append(args, v)
-			markerSeq += 1
-		}
+	//markers of where state follow the new values
+	whereState, whereArgs, err := getSqlWhereStateFrom(cloneConds(conds), markerSeq)
+	if err != nil {
+		return "", nil, err
 	}
 
 	setSeq := strings.Join(setState, ",")
-	whereSeq := strings.Join(whereState, " and ")
-	return strings.Join([]string{"update", getTableName(b.schema, descriptor.Typ), "set", setSeq, "where", whereSeq}, " "), args, nil
+	if whereState == "" {
+		return strings.Join([]string{"update", getTableName(b.schema, descriptor.Typ), "set", setSeq}, " "), args, nil
+	}
+	return strings.Join([]string{"update", getTableName(b.schema, descriptor.Typ), "set", setSeq, "where", whereState}, " "), append(args, whereArgs...), nil
 }
 
 type joinSqlParams struct {
@@ -294,30 +320,105 @@ func (b *BaseTx) joinSelectSqlAndArgs(ownerTyp ResourceType, ownedTyp ResourceTy
 	return buf.String(), []interface{}{ownerID}, nil
 }
 
+// fields of resource base which aren't stored
+var nonColumnFields = []string{"type", "links", "deletion_timestamp"}
+
+// columns could be json names, field names or column names, id and order
+// columns are always selected, nil columns means all the columns, so
+// fields in request could be used as columns directly
+func selectColumnsSql(descriptor *ResourceDescriptor, goTyp reflect.Type, columns_ any, orderColumns []orderColumn) (string, error) {
+	if columns_ == nil {
+		return "*", nil
+	}
+
+	columns, ok := columns_.([]string)
+	if ok == false {
+		return "", fmt.Errorf("columns argument isn't string slice:%v", columns_)
+	} else if len(columns) == 0 {
+		return "*", nil
+	}
+
+	selected := []string{IDField}
+	columns = slices.Clone(columns)
+	for _, column := range orderColumns {
+		columns = append(columns, column.Name)
+	}
+
+	for _, column := range columns {
+		column = toColumnName(jsonNameToFieldName(goTyp, column))
+		if slices.Contains(nonColumnFields, column) {
+			continue
+		}
+
+		if _, ok := getColumnField(descriptor, column); ok == false {
+			return "", fmt.Errorf("unknown column %s of %s", column, descriptor.Typ)
+		}
+
+		if slices.Contains(selected, column) == false {
+			selected = append(selected, column)
+		}
+	}
+	return strings.Join(selected, ", "), nil
+}
+
+// json name is converted to the name of the go field, whose snake case
+// is the column name, fields of embed struct are also searched, name
+// is returned as it is if no field has the json name
+func jsonNameToFieldName(goTyp reflect.Type, name string) string {
+	for i := 0; i < goTyp.NumField(); i++ {
+		field := goTyp.Field(i)
+		if field.Name == EmbedResource {
+			continue
+		}
+
+		if tagContains(field.Tag.Get(DBTag), TagEmbed) {
+			embedType := field.Type
+			if embedType.Kind() == reflect.Ptr {
+				embedType = embedType.Elem()
+			}
+			if embedType.Kind() == reflect.Struct {
+				if fieldName := jsonNameToFieldName(embedType, name); fieldName != name {
+					return fieldName
+				}
+			}
+			continue
+		}
+
+		if jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ","); jsonName == name {
+			return field.Name
+		}
+	}
+	return name
+}
+
 func getSqlWhereState(conds map[string]any) (string, []any, error) {
+	return getSqlWhereStateFrom(conds, 1)
+}
+
+// markers of args start from markerSeq
+func getSqlWhereStateFrom(conds map[string]any, markerSeq int) (string, []any, error) {
 	if len(conds) == 0 {
 		return "", nil, nil
 	}
 
 	var searchKeys []string
-	if keys_, ok := conds["search"]; ok {
+	if keys_, ok := conds[CondSearch]; ok {
 		if keys, ok := keys_.(string); ok {
 			searchKeys = strings.Split(keys, ",")
 		}
-		delete(conds, "search")
+		delete(conds, CondSearch)
 	}
 
 	var matchListKeys []string
-	if keys_, ok := conds["match_list"]; ok {
+	if keys_, ok := conds[CondMatchList]; ok {
 		if keys, ok := keys_.(string); ok {
 			matchListKeys = strings.Split(keys, ",")
 		}
-		delete(conds, "match_list")
+		delete(conds, CondMatchList)
 	}
 
 	whereState := make([]string, 0, len(conds))
 	args := make([]interface{}, 0, len(conds))
-	markerSeq := 1
 	for k, v := range conds {
 		isSearchKey := false
 		for _, sk := range searchKeys {
@@ -358,13 +459,21 @@ func getSqlWhereState(conds map[string]any) (string, []any, error) {
 			}
 		} else {
 			if vf, ok := v.(FillValue); ok {
-				s, arg, err := vf.buildSql(k, markerSeq)
-				if err != nil {
-					return "", nil, err
+				v = []FillValue{vf}
+			}
+
+			if vfs, ok := v.([]FillValue); ok {
+				for _, vf := range vfs {
+					s, arg, err := vf.buildSql(k, markerSeq)
+					if err != nil {
+						return "", nil, err
+					}
+					whereState = append(whereState, s)
+					if vf.hasArg() {
+						args = append(args, arg)
+						markerSeq += 1
+					}
 				}
-				whereState = append(whereState, s)
-				args = append(args, arg)
-				markerSeq += 1
 			} else {
 				whereState = append(whereState, stringtool.ToSnake(k)+"=$"+strconv.Itoa(markerSeq))
 				args = append(args, v)
@@ -375,3 +484,17 @@ func getSqlWhereState(conds map[string]any) (string, []any, error) {
 
 	return strings.Join(whereState, " and "), args, nil
 }
+
+// conds will be modified during generate sql, clone it
+// if it will be used again, excludes keys are omitted
+func cloneConds(conds map[string]any, excludes ...string) map[string]any {
+	cloned := make(map[string]any, len(conds))
+	for k, v := range conds {
+		cloned[k] = v
+	}
+
+	for _, k := range excludes {
+		delete(cloned, k)
+	}
+	return cloned
+}
@@ -0,0 +1,297 @@
+package db
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	ut "github.com/linkingthing/cement/unittest"
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+)
+
+func TestCountSqlIgnorePagination(t *testing.T) {
+	meta, err := NewResourceMeta([]resource.Resource{&Mother{}})
+	ut.Assert(t, err == nil, "")
+	tx := NewBaseTx(meta, "")
+
+	conds := map[string]interface{}{"name": "m1", CondOrderBy: "age", CondLimit: 10, CondOffset: 20}
+	sql, args, err := tx.countSqlAndArgs("mother", conds)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "select count(*) from lx.gr_mother where name=$1")
+	ut.Equal(t, args, []interface{}{"m1"})
+	ut.Equal(t, len(conds), 4)
+
+	sql, args, err = tx.selectSqlAndArgs("mother", conds)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "select * from lx.gr_mother where name=$1 order by age limit 10 offset 20")
+	ut.Equal(t, args, []interface{}{"m1"})
+}
+
+func TestKeysetSql(t *testing.T) {
+	meta, err := NewResourceMeta([]resource.Resource{&Mother{}})
+	ut.Assert(t, err == nil, "")
+	tx := NewBaseTx(meta, "")
+
+	conds := map[string]interface{}{"name": "m1", CondOrderBy: "age", CondLimit: 11, CondContinue: ""}
+	sql, args, err := tx.selectSqlAndArgs("mother", conds)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "select * from lx.gr_mother where name=$1 order by age, id limit 11")
+	ut.Equal(t, args, []interface{}{"m1"})
+
+	last := &Mother{Age: 30, Name: "m1"}
+	last.SetID("m1_30")
+	token, err := genContinueToken(last, []orderColumn{{Name: "age"}})
+	ut.Assert(t, err == nil, "")
+	conds = map[string]interface{}{"name": "m1", CondOrderBy: "age", CondLimit: 11, CondContinue: token}
+	sql, args, err = tx.selectSqlAndArgs("mother", conds)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "select * from lx.gr_mother where name=$1 and ((age > $2::bigint or age is null) or (age = $2::bigint and id > $3)) order by age, id limit 11")
+	ut.Equal(t, args, []interface{}{"m1", "30", "m1_30"})
+
+	sorts := []resource.Sort{{Field: "age", Order: resource.SortDesc}, {Field: "name", Order: resource.SortDesc}}
+	columns, err := parseOrderColumns(reflect.TypeOf(Mother{}), sorts)
+	ut.Assert(t, err == nil, "")
+	token, err = genContinueToken(last, columns)
+	ut.Assert(t, err == nil, "")
+	sql, args, err = tx.selectSqlAndArgs("mother", map[string]interface{}{CondOrderBy: sorts, CondLimit: 11, CondContinue: token})
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "select * from lx.gr_mother where (age < $1::bigint or (age = $1::bigint and name < $2::text) or (age = $1::bigint and name = $2::text and id < $3)) order by age desc, name desc, id desc limit 11")
+	ut.Equal(t, args, []interface{}{"30", "m1", "m1_30"})
+
+	token, err = genContinueToken(last, nil)
+	ut.Assert(t, err == nil, "")
+	sql, args, err = tx.selectSqlAndArgs("mother", map[string]interface{}{CondLimit: 11, CondContinue: token})
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "select * from lx.gr_mother where id > $1 order by id limit 11")
+	ut.Equal(t, args, []interface{}{"m1_30"})
+
+	_, _, err = tx.selectSqlAndArgs("mother", map[string]interface{}{CondOrderBy: "age", CondContinue: token})
+	ut.Assert(t, err != nil, "token ordered by id shouldn't be used for order by age")
+	_, _, err = tx.selectSqlAndArgs("mother", map[string]interface{}{CondContinue: "invalid token"})
+	ut.Assert(t, errors.Is(err, ErrInvalidContinueToken), "invalid token should be rejected")
+	ut.Equal(t, meta.ErrorToAPIError("mother", err).ErrorCode, goresterr.InvalidFormat)
+	_, _, err = tx.selectSqlAndArgs("mother", map[string]interface{}{CondOrderBy: "-age,name", CondContinue: ""})
+	ut.Assert(t, err != nil, "keyset pagination with mixed directions should be rejected")
+}
+
+type Ticket struct {
+	resource.ResourceBase
+	Priority int
+	Title    string `db:"not null"`
+}
+
+func TestKeysetSqlWithNull(t *testing.T) {
+	meta, err := NewResourceMeta([]resource.Resource{&Ticket{}})
+	ut.Assert(t, err == nil, "")
+	tx := NewBaseTx(meta, "")
+
+	last := &Ticket{Priority: 5, Title: "t1"}
+	last.SetID("t1")
+	token, err := genContinueToken(last, []orderColumn{{Name: "priority"}})
+	ut.Assert(t, err == nil, "")
+	sql, args, err := tx.selectSqlAndArgs("ticket", map[string]interface{}{CondOrderBy: "priority", CondLimit: 11, CondContinue: token})
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "select * from lx.gr_ticket where ((priority > $1::bigint or priority is null) or (priority = $1::bigint and id > $2)) order by priority, id limit 11")
+	ut.Equal(t, args, []interface{}{"5", "t1"})
+
+	//not null columns are still compared as a row
+	token, err = genContinueToken(last, []orderColumn{{Name: "title"}})
+	ut.Assert(t, err == nil, "")
+	sql, args, err = tx.selectSqlAndArgs("ticket", map[string]interface{}{CondOrderBy: "title", CondLimit: 11, CondContinue: token})
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "select * from lx.gr_ticket where (title, id) > ($1::text, $2) order by title, id limit 11")
+	ut.Equal(t, args, []interface{}{"t1", "t1"})
+
+	//rows with null are after the ones with value in ascending order
+	token, err = encodeContinueToken(&continueToken{Columns: []orderColumn{{Name: "priority"}}, Values: []*string{nil}, ID: "t1"})
+	ut.Assert(t, err == nil, "")
+	sql, args, err = tx.selectSqlAndArgs("ticket", map[string]interface{}{CondOrderBy: "priority", CondLimit: 11, CondContinue: token})
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "select * from lx.gr_ticket where (priority is null and id > $1) order by priority, id limit 11")
+	ut.Equal(t, args, []interface{}{"t1"})
+
+	//and before them in descending order
+	title := "t1"
+	token, err = encodeContinueToken(&continueToken{Columns: []orderColumn{{Name: "priority", Desc: true}, {Name: "title", Desc: true}}, Values: []*string{nil, &title}, ID: "t1"})
+	ut.Assert(t, err == nil, "")
+	sql, args, err = tx.selectSqlAndArgs("ticket", map[string]interface{}{CondOrderBy: "-priority,-title", CondLimit: 11, CondContinue: token})
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "select * from lx.gr_ticket where (priority is not null or (priority is null and title < $1::text) or (priority is null and title = $1::text and id < $2)) order by priority desc, title desc, id desc limit 11")
+	ut.Equal(t, args, []interface{}{"t1", "t1"})
+
+	token, err = encodeContinueToken(&continueToken{Columns: []orderColumn{{Name: "title"}}, Values: []*string{nil}, ID: "t1"})
+	ut.Assert(t, err == nil, "")
+	_, _, err = tx.selectSqlAndArgs("ticket", map[string]interface{}{CondOrderBy: "title", CondContinue: token})
+	ut.Assert(t, errors.Is(err, ErrInvalidContinueToken), "not null column can't be null in token")
+}
+
+func TestOrderSql(t *testing.T) {
+	meta, err := NewResourceMeta([]resource.Resource{&Mother{}})
+	ut.Assert(t, err == nil, "")
+	tx := NewBaseTx(meta, "")
+
+	cases := []struct {
+		order interface{}
+		sql   string
+	}{
+		{"age", "select * from lx.gr_mother order by age "},
+		{"-age, name", "select * from lx.gr_mother order by age desc, name "},
+		{"create_time desc,name asc", "select * from lx.gr_mother order by create_time desc, name "},
+		{[]resource.Sort{{Field: "createTime", Order: resource.SortDesc}, {Field: "name", Order: resource.SortAsc}},
+			"select * from lx.gr_mother order by create_time desc, name "},
+		{[]resource.Sort{{Field: "creationTimestamp", Order: resource.SortAsc}},
+			"select * from lx.gr_mother order by create_time "},
+	}
+
+	for _, tc := range cases {
+		sql, _, err := tx.selectSqlAndArgs("mother", map[string]interface{}{CondOrderBy: tc.order})
+		ut.Assert(t, err == nil, "order %v failed %v", tc.order, err)
+		ut.Equal(t, sql, tc.sql)
+	}
+
+	for _, order := range []interface{}{"unknown", "age,", "age up", 1, []resource.Sort{{Field: "weight"}}} {
+		_, _, err := tx.selectSqlAndArgs("mother", map[string]interface{}{CondOrderBy: order})
+		ut.Assert(t, err != nil, "order %v should be invalid", order)
+	}
+}
+
+func TestCheckSorts(t *testing.T) {
+	meta, err := NewResourceMeta([]resource.Resource{&Student{}})
+	ut.Assert(t, err == nil, "")
+
+	for _, field := range []string{"name", "age", "id", "createTime"} {
+		ut.Assert(t, meta.CheckSorts("student", []resource.Sort{{Field: field}}) == nil, "%s should be sortable", field)
+	}
+
+	apiErr := meta.CheckSorts("student", []resource.Sort{{Field: "name"}, {Field: "classroom", Order: resource.SortDesc}})
+	ut.Assert(t, apiErr != nil, "field isn't stored can't be sorted")
+	ut.Equal(t, apiErr.ErrorCode, goresterr.InvalidFormat)
+}
+
+func TestSelectColumns(t *testing.T) {
+	meta, err := NewResourceMeta([]resource.Resource{&Mother{}})
+	ut.Assert(t, err == nil, "")
+	tx := NewBaseTx(meta, "")
+
+	columns := []string{"name", "links", "creationTimestamp"}
+	sql, _, err := tx.selectSqlAndArgs("mother", map[string]interface{}{CondColumns: columns, CondOrderBy: "-age"})
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "select id, name, create_time, age from lx.gr_mother order by age desc ")
+	ut.Equal(t, columns, []string{"name", "links", "creationTimestamp"})
+
+	conds := map[string]interface{}{"name": "m1", CondColumns: columns}
+	sql, _, err = tx.countSqlAndArgs("mother", conds)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "select count(*) from lx.gr_mother where name=$1")
+
+	_, _, err = tx.selectSqlAndArgs("mother", map[string]interface{}{CondColumns: []string{"weight"}})
+	ut.Assert(t, err != nil, "unknown column should be rejected")
+}
+
+type Endpoint struct {
+	Address string `json:"ip"`
+}
+
+type Service struct {
+	resource.ResourceBase `json:",inline"`
+	ServiceName           string   `json:"name"`
+	Endpoint              Endpoint `db:"embed"`
+}
+
+func TestSelectColumnsByJsonName(t *testing.T) {
+	meta, err := NewResourceMeta([]resource.Resource{&Service{}})
+	ut.Assert(t, err == nil, "")
+	tx := NewBaseTx(meta, "")
+
+	sql, _, err := tx.selectSqlAndArgs("service", map[string]interface{}{CondColumns: []string{"name", "ip", "resourceVersion"}})
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "select id, service_name, address, resource_version from lx.gr_service order by id ")
+
+	//column names are still accepted
+	sql, _, err = tx.selectSqlAndArgs("service", map[string]interface{}{CondColumns: []string{"service_name"}})
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "select id, service_name from lx.gr_service order by id ")
+}
+
+func TestOrderBySortFields(t *testing.T) {
+	meta, err := NewResourceMeta([]resource.Resource{&Service{}})
+	ut.Assert(t, err == nil, "")
+	tx := NewBaseTx(meta, "")
+
+	//sort fields are resolved like the request validation
+	sorts := []resource.Sort{{Field: "name", Order: resource.SortDesc}, {Field: "createTime"}, {Field: "ServiceName"}}
+	sql, _, err := tx.selectSqlAndArgs("service", map[string]interface{}{CondOrderBy: sorts})
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "select * from lx.gr_service order by service_name desc, create_time, service_name ")
+}
+
+func TestUpdateSqlBumpResourceVersion(t *testing.T) {
+	meta, err := NewResourceMeta([]resource.Resource{&Mother{}})
+	ut.Assert(t, err == nil, "")
+	tx := NewBaseTx(meta, "")
+
+	sql, args, err := tx.updateSqlAndArgs("mother", map[string]interface{}{"age": 31, ResourceVersionField: 5},
+		map[string]interface{}{IDField: "m1"})
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "update lx.gr_mother set age=$1,resource_version=resource_version+1 where id=$2")
+	ut.Equal(t, args, []interface{}{31, "m1"})
+
+	sql, args, err = tx.updateSqlAndArgs("mother", map[string]interface{}{"age": 31},
+		map[string]interface{}{ResourceVersionField: int64(3)})
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "update lx.gr_mother set age=$1,resource_version=resource_version+1 where resource_version=$2")
+	ut.Equal(t, args, []interface{}{31, int64(3)})
+
+	m := &Mother{Age: 30, Name: "m1"}
+	m.SetResourceVersion(1)
+	sql, args, err = tx.insertSqlArgsAndID(m)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, args[len(args)-1], int64(1))
+	ut.Equal(t, strings.Count(sql, "$"), len(args))
+}
+
+func TestWhereStateOperators(t *testing.T) {
+	meta, err := NewResourceMeta([]resource.Resource{&Mother{}})
+	ut.Assert(t, err == nil, "")
+	tx := NewBaseTx(meta, "")
+
+	nullConds := map[string]interface{}{"name": FillValue{Operator: OperatorNull}}
+	sql, args, err := tx.deleteSqlAndArgs("mother", nullConds)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "delete from lx.gr_mother where name is null")
+	ut.Equal(t, len(args), 0)
+
+	sql, args, err = tx.existsSqlAndArgs("mother", map[string]interface{}{"name": FillValue{Operator: OperatorNotNull}})
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "select (exists (select 1 from  lx.gr_mother where name is not null limit 1))")
+	ut.Equal(t, len(args), 0)
+
+	sql, args, err = tx.updateSqlAndArgs("mother", map[string]interface{}{"age": 31}, nullConds)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "update lx.gr_mother set age=$1,resource_version=resource_version+1 where name is null")
+	ut.Equal(t, args, []interface{}{31})
+
+	//filters with multiple operators on one field
+	rangeConds := map[string]interface{}{"age": []FillValue{
+		{Operator: OperatorNotNull},
+		{Operator: OperatorGte, Value: 20},
+		{Operator: OperatorLt, Value: 40},
+	}}
+	sql, args, err = tx.deleteSqlAndArgs("mother", rangeConds)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "delete from lx.gr_mother where age is not null and age >= $1 and age < $2")
+	ut.Equal(t, args, []interface{}{20, 40})
+
+	sql, args, err = tx.existsSqlAndArgs("mother", rangeConds)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "select (exists (select 1 from  lx.gr_mother where age is not null and age >= $1 and age < $2 limit 1))")
+	ut.Equal(t, args, []interface{}{20, 40})
+
+	sql, args, err = tx.updateSqlAndArgs("mother", map[string]interface{}{"name": "m2"}, rangeConds)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sql, "update lx.gr_mother set name=$1,resource_version=resource_version+1 where age is not null and age >= $2 and age < $3")
+	ut.Equal(t, args, []interface{}{"m2", 20, 40})
+	ut.Equal(t, len(rangeConds["age"].([]FillValue)), 3)
+}
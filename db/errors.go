@@ -0,0 +1,230 @@
+package db
+
+import (
+	"errors"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/Kseleven/pgx/v5/pgconn"
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+)
+
+// sqlstate of postgresql, openGauss uses the same codes
+const (
+	pgNotNullViolation     = "23502"
+	pgForeignKeyViolation  = "23503"
+	pgUniqueViolation      = "23505"
+	pgCheckViolation       = "23514"
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+	pgConnectionClass      = "08"
+	pgAdminShutdown        = "57P01"
+	pgCrashShutdown        = "57P02"
+	pgCannotConnectNow     = "57P03"
+)
+
+const detailKeyPrefix = "Key ("
+
+// kinds of StoreError, use errors.Is to check the kind of an error
+// returned by Transaction
+var (
+	ErrUniqueViolation      = errors.New("unique violation")
+	ErrForeignKeyViolation  = errors.New("foreign key violation")
+	ErrNotNullViolation     = errors.New("not null violation")
+	ErrCheckViolation       = errors.New("check violation")
+	ErrSerializationFailure = errors.New("serialization failure")
+	ErrDeadlock             = errors.New("deadlock detected")
+	ErrConnectionLost       = errors.New("connection lost")
+)
+
+// detail of foreign key violation, like
+// Key (id)=(d1) is still referenced from table "gr_record".
+// Key (domain)=(d1) is not present in table "gr_domain".
+var foreignKeyDetailRegexp = regexp.MustCompile(`(still referenced from|not present in) table "([^"]+)"`)
+
+// StoreError is the classified database error, Table is the resource
+// type of the table which violates the constraint, Columns come from the
+// error detail, for foreign key violation, Referenced means the row is
+// still referenced by ReferenceTable, otherwise the row refers to a
+// missing row of ReferenceTable
+type StoreError struct {
+	Kind           error
+	Code           string
+	Table          ResourceType
+	Constraint     string
+	Columns        []string
+	Referenced     bool
+	ReferenceTable ResourceType
+	Err            error
+}
+
+func (e *StoreError) Error() string {
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+func (e *StoreError) Unwrap() error {
+	return e.Err
+}
+
+func (e *StoreError) Is(target error) bool {
+	return e.Kind == target
+}
+
+// classifyError wrap the pgx error into StoreError, unknown error is
+// returned as it is
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var storeErr *StoreError
+	if errors.As(err, &storeErr) {
+		return err
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return classifyPgError(pgErr, err)
+	}
+
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	if errors.As(err, &connectErr) || errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return &StoreError{Kind: ErrConnectionLost, Err: err}
+	}
+	return err
+}
+
+func classifyPgError(pgErr *pgconn.PgError, err error) error {
+	e := &StoreError{
+		Code:       pgErr.Code,
+		Table:      tableResourceType(pgErr.TableName),
+		Constraint: pgErr.ConstraintName,
+		Err:        err,
+	}
+
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		e.Kind = ErrUniqueViolation
+		e.Columns = detailColumns(pgErr.Detail)
+	case pgForeignKeyViolation:
+		e.Kind = ErrForeignKeyViolation
+		e.Columns = detailColumns(pgErr.Detail)
+		if matches := foreignKeyDetailRegexp.FindStringSubmatch(pgErr.Detail); len(matches) == 3 {
+			e.Referenced = strings.HasPrefix(matches[1], "still")
+			e.ReferenceTable = tableResourceType(matches[2])
+		}
+	case pgNotNullViolation:
+		e.Kind = ErrNotNullViolation
+		if pgErr.ColumnName != "" {
+			e.Columns = []string{pgErr.ColumnName}
+		}
+	case pgCheckViolation:
+		e.Kind = ErrCheckViolation
+	case pgSerializationFailure:
+		e.Kind = ErrSerializationFailure
+	case pgDeadlockDetected:
+		e.Kind = ErrDeadlock
+	case pgAdminShutdown, pgCrashShutdown, pgCannotConnectNow:
+		e.Kind = ErrConnectionLost
+	default:
+		if strings.HasPrefix(pgErr.Code, pgConnectionClass) {
+			e.Kind = ErrConnectionLost
+		} else {
+			return err
+		}
+	}
+	return e
+}
+
+// columns in detail like Key (name, domain)=(www, d1) already exists.
+func detailColumns(detail string) []string {
+	if strings.HasPrefix(detail, detailKeyPrefix) == false {
+		return nil
+	}
+
+	columns, _, ok := strings.Cut(strings.TrimPrefix(detail, detailKeyPrefix), ")=(")
+	if ok == false {
+		return nil
+	}
+
+	var names []string
+	for _, column := range strings.Split(columns, ",") {
+		names = append(names, strings.Trim(strings.TrimSpace(column), `"`))
+	}
+	return names
+}
+
+// table name may have schema, tables in default schema have prefix
+func tableResourceType(table string) ResourceType {
+	if i := strings.LastIndex(table, "."); i != -1 {
+		table = table[i+1:]
+	}
+	return ResourceType(strings.TrimPrefix(table, DefaultTablePrefix))
+}
+
+// ErrorToAPIError convert the error returned by Transaction to APIError
+// with the resource type of typ, *goresterr.APIError is returned as it is
+func (meta *ResourceMeta) ErrorToAPIError(typ ResourceType, err error) *goresterr.APIError {
+	var apiErr *goresterr.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	resourceType := string(typ)
+	if descriptor, e := meta.GetDescriptor(typ); e == nil {
+		resourceType = string(descriptor.Typ)
+	}
+
+	var storeErr *StoreError
+	if errors.As(classifyError(err), &storeErr) == false {
+		if errors.Is(err, ErrResourceVersionConflict) {
+			return storeAPIError(goresterr.Conflict, goresterr.MessageConcurrentUpdate, goresterr.Params{"type": resourceType})
+		} else if errors.Is(err, ErrInvalidContinueToken) {
+			return storeAPIError(goresterr.InvalidFormat, goresterr.MessageInvalidQuery, goresterr.Params{"name": resource.FilterNameContinue})
+		}
+		return goresterr.NewAPIError(goresterr.ServerError,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageStoreFailed, goresterr.Params{"reason": err.Error()}))
+	}
+
+	field := strings.Join(storeErr.Columns, ",")
+	if field == "" {
+		field = storeErr.Constraint
+	}
+
+	switch storeErr.Kind {
+	case ErrUniqueViolation:
+		return storeAPIError(goresterr.DuplicateResource, goresterr.MessageDuplicateResource,
+			goresterr.Params{"type": resourceType, "field": field})
+	case ErrForeignKeyViolation:
+		//the table which violates the constraint is the referencing table
+		if storeErr.Referenced || (storeErr.Table != "" && string(storeErr.Table) != resourceType) {
+			referrer := string(storeErr.ReferenceTable)
+			if referrer == "" {
+				referrer = string(storeErr.Table)
+			}
+			return storeAPIError(goresterr.DeleteParent, goresterr.MessageResourceReferenced,
+				goresterr.Params{"type": resourceType, "referrer": referrer})
+		}
+		return storeAPIError(goresterr.NotFound, goresterr.MessageReferenceNotFound,
+			goresterr.Params{"type": resourceType, "field": field})
+	case ErrNotNullViolation:
+		return storeAPIError(goresterr.NotNullable, goresterr.MessageNotNullable,
+			goresterr.Params{"type": resourceType, "field": field})
+	case ErrCheckViolation:
+		return storeAPIError(goresterr.InvalidFormat, goresterr.MessageCheckViolation,
+			goresterr.Params{"type": resourceType, "constraint": storeErr.Constraint})
+	case ErrSerializationFailure, ErrDeadlock:
+		return storeAPIError(goresterr.Conflict, goresterr.MessageConcurrentUpdate, goresterr.Params{"type": resourceType})
+	default:
+		return storeAPIError(goresterr.ClusterUnavailable, goresterr.MessageDatabaseUnavailable, nil)
+	}
+}
+
+func storeAPIError(code goresterr.ErrorCode, key string, params goresterr.Params) *goresterr.APIError {
+	return goresterr.NewAPIError(code, *goresterr.NewLocalizedErrorMessage("", key, params))
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/Kseleven/pgx/v5/pgconn"
+	ut "github.com/linkingthing/cement/unittest"
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+)
+
+func TestClassifyError(t *testing.T) {
+	err := classifyError(fmt.Errorf("insert failed: %w", &pgconn.PgError{
+		Code:           pgUniqueViolation,
+		TableName:      "gr_record",
+		ConstraintName: "gr_record_name_domain_key",
+		Detail:         `Key (name, "domain")=(www, d1) already exists.`,
+	}))
+	ut.Assert(t, errors.Is(err, ErrUniqueViolation), "should be unique violation")
+	var storeErr *StoreError
+	ut.Assert(t, errors.As(err, &storeErr), "should be store error")
+	ut.Equal(t, storeErr.Table, ResourceType("record"))
+	ut.Equal(t, storeErr.Constraint, "gr_record_name_domain_key")
+	ut.Equal(t, storeErr.Columns, []string{"name", "domain"})
+	var pgErr *pgconn.PgError
+	ut.Assert(t, errors.As(err, &pgErr), "pg error should be kept")
+
+	err = classifyError(&pgconn.PgError{
+		Code:      pgForeignKeyViolation,
+		TableName: "record",
+		Detail:    `Key (domain)=(d1) is not present in table "domain".`,
+	})
+	ut.Assert(t, errors.As(err, &storeErr), "should be store error")
+	ut.Equal(t, storeErr.Kind, ErrForeignKeyViolation)
+	ut.Equal(t, storeErr.Referenced, false)
+	ut.Equal(t, storeErr.ReferenceTable, ResourceType("domain"))
+	ut.Equal(t, storeErr.Columns, []string{"domain"})
+
+	for code, kind := range map[string]error{
+		pgNotNullViolation:     ErrNotNullViolation,
+		pgCheckViolation:       ErrCheckViolation,
+		pgSerializationFailure: ErrSerializationFailure,
+		pgDeadlockDetected:     ErrDeadlock,
+		"08006":                ErrConnectionLost,
+		pgAdminShutdown:        ErrConnectionLost,
+	} {
+		ut.Assert(t, errors.Is(classifyError(&pgconn.PgError{Code: code}), kind), "code %s should be %v", code, kind)
+	}
+
+	ut.Assert(t, errors.Is(classifyError(io.ErrUnexpectedEOF), ErrConnectionLost), "eof should be connection lost")
+
+	unknown := &pgconn.PgError{Code: "42P01"}
+	ut.Equal(t, classifyError(unknown), error(unknown))
+	ut.Assert(t, classifyError(nil) == nil, "nil error should be kept")
+}
+
+func TestErrorToAPIError(t *testing.T) {
+	meta, err := NewResourceMeta([]resource.Resource{&Domain{}, &Record{}})
+	ut.Assert(t, err == nil, "new meta failed:%v", err)
+
+	cases := []struct {
+		typ     ResourceType
+		err     error
+		code    goresterr.ErrorCode
+		message string
+	}{
+		{
+			"record",
+			&pgconn.PgError{Code: pgUniqueViolation, Detail: "Key (name)=(www) already exists."},
+			goresterr.DuplicateResource,
+			"record resource with same name already exists",
+		},
+		{
+			"domain",
+			&pgconn.PgError{Code: pgForeignKeyViolation, TableName: "gr_record"},
+			goresterr.DeleteParent,
+			"domain resource is referenced by record resources",
+		},
+		{
+			"record",
+			&pgconn.PgError{Code: pgForeignKeyViolation, TableName: "gr_record", Detail: `Key (domain)=(d1) is not present in table "gr_domain".`},
+			goresterr.NotFound,
+			"resource referenced by domain of record doesn't exist",
+		},
+		{
+			"record",
+			&pgconn.PgError{Code: pgNotNullViolation, ColumnName: "name"},
+			goresterr.NotNullable,
+			"name of record can't be null",
+		},
+		{
+			"record",
+			&pgconn.PgError{Code: pgCheckViolation, ConstraintName: "positive_ttl"},
+			goresterr.InvalidFormat,
+			"record resource violates check constraint positive_ttl",
+		},
+		{
+			"record",
+			&pgconn.PgError{Code: pgDeadlockDetected},
+			goresterr.Conflict,
+			"record resource is modified concurrently, please retry",
+		},
+		{
+			"record",
+			io.EOF,
+			goresterr.ClusterUnavailable,
+			"database is unavailable, please retry later",
+		},
+		{
+			"record",
+			errors.New("unknown column"),
+			goresterr.ServerError,
+			"database operation failed: unknown column",
+		},
+	}
+
+	for _, c := range cases {
+		apiErr := meta.ErrorToAPIError(c.typ, c.err)
+		ut.Equal(t, apiErr.ErrorCode, c.code)
+		ut.Equal(t, apiErr.Message, c.message)
+	}
+
+	apiErr := meta.ErrorToAPIError("record", &pgconn.PgError{Code: pgUniqueViolation, Detail: "Key (name)=(www) already exists."})
+	ut.Equal(t, apiErr.MessageCN, "name相同的record资源已存在")
+}
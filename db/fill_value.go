@@ -25,6 +25,9 @@ const (
 	OperatorLike              = "%like%"
 	OperatorLikeSuffix        = "like%"
 	OperatorLikePrefix        = "%like"
+	OperatorNotLike           = "!%like%"
+	OperatorNull              = "null"
+	OperatorNotNull           = "notnull"
 	OperatorAny               = "any"
 	OperatorOverlap           = "&&"
 	OperatorSubnetContain     = ">>"
@@ -47,6 +50,12 @@ func (f FillValue) buildSql(key string, markerSeq int) (string, any, error) {
 		return stringtool.ToSnake(key) + " >= $" + strconv.Itoa(markerSeq), f.Value, nil
 	case OperatorLike:
 		return stringtool.ToSnake(key) + " ~ $" + strconv.Itoa(markerSeq), f.Value, nil
+	case OperatorNotLike:
+		return stringtool.ToSnake(key) + " !~ $" + strconv.Itoa(markerSeq), f.Value, nil
+	case OperatorNull:
+		return stringtool.ToSnake(key) + " is null", nil, nil
+	case OperatorNotNull:
+		return stringtool.ToSnake(key) + " is not null", nil, nil
 	case OperatorLikeSuffix:
 		if sv, ok := f.Value.(string); ok == true {
 			return stringtool.ToSnake(key) + " ~ $" + strconv.Itoa(markerSeq), "^" + sv, nil
@@ -83,6 +92,9 @@ func (f FillValue) buildSql(key string, markerSeq int) (string, any, error) {
 		case reflect.Bool:
 			return stringtool.ToSnake(key) + " = ANY($" + strconv.Itoa(markerSeq) + "::boolean[])", f.Value, nil
 		case reflect.Struct:
+			if typStr == "time.Time" {
+				return stringtool.ToSnake(key) + " = ANY($" + strconv.Itoa(markerSeq) + "::timestamp with time zone[])", f.Value, nil
+			}
 			if typStr == "net.IPNet" || typStr == "netip.Addr" || typStr == "netip.Prefix" {
 				return stringtool.ToSnake(key) + " = ANY($" + strconv.Itoa(markerSeq) + "::inet[])", f.Value, nil
 			}
@@ -107,3 +119,8 @@ func (f FillValue) buildSql(key string, markerSeq int) (string, any, error) {
 		return stringtool.ToSnake(key) + " = $" + strconv.Itoa(markerSeq), f.Value, nil
 	}
 }
+
+// null and not null operators have no argument
+func (f FillValue) hasArg() bool {
+	return f.Operator != OperatorNull && f.Operator != OperatorNotNull
+}
@@ -0,0 +1,245 @@
+package db
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/linkingthing/cement/stringtool"
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+)
+
+var modifierOperators = map[resource.Modifier]Operator{
+	resource.Eq:      OperatorEq,
+	resource.Ne:      OperatorNe,
+	resource.Lt:      OperatorLt,
+	resource.Gt:      OperatorGt,
+	resource.Lte:     OperatorLte,
+	resource.Gte:     OperatorGte,
+	resource.Prefix:  OperatorLikeSuffix,
+	resource.Suffix:  OperatorLikePrefix,
+	resource.Like:    OperatorLike,
+	resource.NotLike: OperatorNotLike,
+	resource.Null:    OperatorNull,
+	resource.NotNull: OperatorNotNull,
+}
+
+// FiltersToConds convert filters in url to conds of resource typ, filter name
+// could be the json name or column name of a field, values are converted
+// to the type of the column, filters on the same column are all applied
+func (meta *ResourceMeta) FiltersToConds(typ ResourceType, filters []resource.Filter) (map[string]interface{}, *goresterr.APIError) {
+	descriptor, err := meta.GetDescriptor(typ)
+	if err != nil {
+		return nil, goresterr.NewAPIError(goresterr.ServerError,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageStoreFailed, goresterr.Params{"reason": err.Error()}))
+	}
+
+	conds := make(map[string]interface{})
+	for _, filter := range filters {
+		column := stringtool.ToSnake(filter.Name)
+		field, ok := getColumnField(descriptor, column)
+		if ok == false {
+			return nil, invalidFilterError(filter, fmt.Sprintf("unknown filter %s", filter.Name))
+		}
+
+		value, err := filterToFillValue(field, filter)
+		if err != nil {
+			return nil, invalidFilterError(filter, err.Error())
+		}
+
+		if vfs, ok := conds[column].([]FillValue); ok {
+			conds[column] = append(vfs, value)
+		} else {
+			conds[column] = []FillValue{value}
+		}
+	}
+
+	return conds, nil
+}
+
+func invalidFilterError(filter resource.Filter, msg string) *goresterr.APIError {
+	return goresterr.NewAPIError(goresterr.InvalidFormat,
+		*goresterr.NewLocalizedErrorMessage(msg, goresterr.MessageInvalidQuery, goresterr.Params{"name": filter.Name}))
+}
+
+// owner and refer columns are stored as text
+func getColumnField(descriptor *ResourceDescriptor, column string) (ResourceField, bool) {
+	for _, field := range descriptor.Fields {
+		if field.Name == column {
+			return field, true
+		}
+	}
+
+	if column == ResourceVersionField {
+		return ResourceField{Name: column, Type: BigInt}, true
+	}
+
+	for _, typ := range append(descriptor.Owners, descriptor.Refers...) {
+		if string(typ) == column {
+			return ResourceField{Name: column, Type: String}, true
+		}
+	}
+
+	return ResourceField{}, false
+}
+
+func filterToFillValue(field ResourceField, filter resource.Filter) (FillValue, error) {
+	operator, ok := modifierOperators[filter.Modifier]
+	if ok == false {
+		return FillValue{}, fmt.Errorf("unknown modifier %s of filter %s", filter.Modifier, filter.Name)
+	}
+
+	switch operator {
+	case OperatorNull, OperatorNotNull:
+		return nullFillValue(operator, filter)
+	case OperatorLike, OperatorNotLike, OperatorLikePrefix, OperatorLikeSuffix:
+		if field.Type != String {
+			return FillValue{}, fmt.Errorf("modifier %s isn't supported by filter %s", filter.Modifier, filter.Name)
+		} else if len(filter.Values) != 1 {
+			return FillValue{}, fmt.Errorf("filter %s should have one value", filter.Name)
+		}
+		return FillValue{Operator: operator, Value: regexp.QuoteMeta(filter.Values[0])}, nil
+	}
+
+	if elemType, ok := arrayElemTypes[field.Type]; ok {
+		if operator != OperatorEq {
+			return FillValue{}, fmt.Errorf("modifier %s isn't supported by filter %s", filter.Modifier, filter.Name)
+		}
+
+		values, err := filterValuesToSlice(elemType, filter.Values)
+		if err != nil {
+			return FillValue{}, fmt.Errorf("invalid value of filter %s:%s", filter.Name, err.Error())
+		}
+		return FillValue{Operator: OperatorOverlap, Value: values}, nil
+	}
+
+	if len(filter.Values) > 1 {
+		if operator != OperatorEq {
+			return FillValue{}, fmt.Errorf("filter %s should have one value", filter.Name)
+		}
+
+		values, err := filterValuesToSlice(field.Type, filter.Values)
+		if err != nil {
+			return FillValue{}, fmt.Errorf("invalid value of filter %s:%s", filter.Name, err.Error())
+		}
+		return FillValue{Operator: OperatorAny, Value: values}, nil
+	} else if len(filter.Values) == 0 {
+		return FillValue{}, fmt.Errorf("filter %s has no value", filter.Name)
+	}
+
+	value, err := filterValueToColumnValue(field.Type, filter.Values[0])
+	if err != nil {
+		return FillValue{}, fmt.Errorf("invalid value of filter %s:%s", filter.Name, err.Error())
+	}
+	return FillValue{Operator: operator, Value: value}, nil
+}
+
+// x_null=false is same with x_notnull, empty value means true
+func nullFillValue(operator Operator, filter resource.Filter) (FillValue, error) {
+	isNull := true
+	if len(filter.Values) > 0 && filter.Values[0] != "" {
+		b, err := strconv.ParseBool(filter.Values[0])
+		if err != nil {
+			return FillValue{}, fmt.Errorf("value of filter %s should be bool", filter.Name)
+		}
+		isNull = b
+	}
+
+	if isNull == false {
+		if operator == OperatorNull {
+			operator = OperatorNotNull
+		} else {
+			operator = OperatorNull
+		}
+	}
+	return FillValue{Operator: operator}, nil
+}
+
+var arrayElemTypes = map[Datatype]Datatype{
+	SmallIntArray: SmallInt,
+	BigIntArray:   BigInt,
+	SuperIntArray: SuperInt,
+	Float32Array:  Float32,
+	StringArray:   String,
+	IPSlice:       IP,
+	IPNetSlice:    IPNet,
+}
+
+func filterValuesToSlice(typ Datatype, values []string) (interface{}, error) {
+	switch typ {
+	case SmallInt, BigInt:
+		return convertFilterValues(values, strconv.Atoi)
+	case SuperInt:
+		return convertFilterValues(values, func(s string) (uint64, error) { return strconv.ParseUint(s, 10, 64) })
+	case Float32:
+		return convertFilterValues(values, parseFloat32)
+	case Bool:
+		return convertFilterValues(values, strconv.ParseBool)
+	case Time:
+		return convertFilterValues(values, parseTime)
+	case IP:
+		return convertFilterValues(values, netip.ParseAddr)
+	case IPNet:
+		return convertFilterValues(values, parsePrefix)
+	default:
+		return values, nil
+	}
+}
+
+func filterValueToColumnValue(typ Datatype, value string) (interface{}, error) {
+	switch typ {
+	case SmallInt, BigInt:
+		return strconv.Atoi(value)
+	case SuperInt:
+		return strconv.ParseUint(value, 10, 64)
+	case Float32:
+		return parseFloat32(value)
+	case Bool:
+		return strconv.ParseBool(value)
+	case Time:
+		return parseTime(value)
+	case IP:
+		return netip.ParseAddr(value)
+	case IPNet:
+		return parsePrefix(value)
+	default:
+		return value, nil
+	}
+}
+
+func convertFilterValues[T any](values []string, convert func(string) (T, error)) ([]T, error) {
+	converted := make([]T, 0, len(values))
+	for _, value := range values {
+		v, err := convert(value)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, v)
+	}
+	return converted, nil
+}
+
+func parseFloat32(value string) (float32, error) {
+	f, err := strconv.ParseFloat(value, 32)
+	return float32(f), err
+}
+
+func parseTime(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339, value)
+}
+
+// a single ip is treated as a host prefix
+func parsePrefix(value string) (netip.Prefix, error) {
+	if prefix, err := netip.ParsePrefix(value); err == nil {
+		return prefix, nil
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
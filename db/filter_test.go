@@ -0,0 +1,74 @@
+package db
+
+import (
+	"net/netip"
+	"testing"
+
+	ut "github.com/linkingthing/cement/unittest"
+	"github.com/linkingthing/gorest/resource"
+)
+
+func TestFiltersToConds(t *testing.T) {
+	meta, err := NewResourceMeta([]resource.Resource{&FillValueResource{}})
+	ut.Assert(t, err == nil, "")
+	tx := NewBaseTx(meta, "")
+
+	cases := []struct {
+		filters []resource.Filter
+		sql     string
+		args    []interface{}
+	}{
+		{
+			[]resource.Filter{{Name: "age", Modifier: resource.Gte, Values: []string{"1"}},
+				{Name: "age", Modifier: resource.Lt, Values: []string{"10"}}},
+			"select * from lx.gr_fill_value_resource where age >= $1 and age < $2 order by id ",
+			[]interface{}{1, 10},
+		},
+		{
+			[]resource.Filter{{Name: "name", Modifier: resource.Prefix, Values: []string{"a.b"}}},
+			"select * from lx.gr_fill_value_resource where name ~ $1 order by id ",
+			[]interface{}{`^a\.b`},
+		},
+		{
+			[]resource.Filter{{Name: "street", Modifier: resource.Null, Values: []string{""}},
+				{Name: "street", Modifier: resource.Null, Values: []string{"false"}}},
+			"select * from lx.gr_fill_value_resource where street is null and street is not null order by id ",
+			[]interface{}{},
+		},
+		{
+			[]resource.Filter{{Name: "ipAddress", Modifier: resource.Eq, Values: []string{"10.0.0.1", "10.0.0.2"}}},
+			"select * from lx.gr_fill_value_resource where ip_address = ANY($1::inet[]) order by id ",
+			[]interface{}{[]netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("10.0.0.2")}},
+		},
+		{
+			[]resource.Filter{{Name: "friends", Modifier: resource.Eq, Values: []string{"j"}}},
+			"select * from lx.gr_fill_value_resource where friends && $1 order by id ",
+			[]interface{}{[]string{"j"}},
+		},
+	}
+
+	for _, tc := range cases {
+		conds, err := meta.FiltersToConds("fill_value_resource", tc.filters)
+		ut.Assert(t, err == nil, "convert filters %v failed %v", tc.filters, err)
+		sql, args, err_ := tx.selectSqlAndArgs("fill_value_resource", conds)
+		ut.Assert(t, err_ == nil, "")
+		ut.Equal(t, sql, tc.sql)
+		ut.Equal(t, args, tc.args)
+	}
+
+	invalidFilters := [][]resource.Filter{
+		{{Name: "unknown", Modifier: resource.Eq, Values: []string{"1"}}},
+		{{Name: "age", Modifier: resource.Eq, Values: []string{"a"}}},
+		{{Name: "age", Modifier: resource.Prefix, Values: []string{"1"}}},
+		{{Name: "age", Modifier: resource.Gt, Values: []string{"1", "2"}}},
+		{{Name: "ipAddress", Modifier: resource.Eq, Values: []string{"10.0.0.256"}}},
+		{{Name: "friends", Modifier: resource.Ne, Values: []string{"j"}}},
+		{{Name: "street", Modifier: resource.NotNull, Values: []string{"no"}}},
+	}
+	for _, filters := range invalidFilters {
+		_, err := meta.FiltersToConds("fill_value_resource", filters)
+		ut.Assert(t, err != nil, "filters %v should be invalid", filters)
+		ut.Equal(t, err.ErrorCode.Code, "InvalidFormat")
+		ut.Assert(t, err.MessageCN != "", "")
+	}
+}
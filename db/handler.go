@@ -0,0 +1,336 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/linkingthing/cement/reflector"
+	"github.com/linkingthing/cement/stringtool"
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+)
+
+// Hook is called in the transaction of the request, returned error rolls
+// back the transaction, *goresterr.APIError is returned to client as it is
+type Hook func(ctx *resource.Context, tx Transaction, r resource.Resource) error
+
+type HandlerOption func(*StoreHandler)
+
+func WithBeforeCreate(hook Hook) HandlerOption {
+	return func(h *StoreHandler) {
+		h.beforeCreate = hook
+	}
+}
+
+func WithAfterCreate(hook Hook) HandlerOption {
+	return func(h *StoreHandler) {
+		h.afterCreate = hook
+	}
+}
+
+// update hooks are also called by patch
+func WithBeforeUpdate(hook Hook) HandlerOption {
+	return func(h *StoreHandler) {
+		h.beforeUpdate = hook
+	}
+}
+
+func WithAfterUpdate(hook Hook) HandlerOption {
+	return func(h *StoreHandler) {
+		h.afterUpdate = hook
+	}
+}
+
+// resource of delete hooks only has id and parent
+func WithBeforeDelete(hook Hook) HandlerOption {
+	return func(h *StoreHandler) {
+		h.beforeDelete = hook
+	}
+}
+
+func WithAfterDelete(hook Hook) HandlerOption {
+	return func(h *StoreHandler) {
+		h.afterDelete = hook
+	}
+}
+
+// StoreHandler implement create, get, list, update, patch and delete of a
+// resource kind with ResourceStore, queries are scoped by the owner
+// column which has same type with the parent in url
+type StoreHandler struct {
+	store  ResourceStore
+	meta   *ResourceMeta
+	typ    ResourceType
+	goType reflect.Type
+
+	beforeCreate Hook
+	afterCreate  Hook
+	beforeUpdate Hook
+	afterUpdate  Hook
+	beforeDelete Hook
+	afterDelete  Hook
+}
+
+func NewStoreHandler(kind resource.ResourceKind, store ResourceStore, meta *ResourceMeta, opts ...HandlerOption) (*StoreHandler, error) {
+	goType := reflect.TypeOf(kind)
+	if goType.Kind() == reflect.Ptr {
+		goType = goType.Elem()
+	}
+
+	r, ok := reflect.New(goType).Interface().(resource.Resource)
+	if ok == false {
+		return nil, fmt.Errorf("%s doesn't implement resource interface", goType.Name())
+	}
+
+	typ := ResourceDBType(r)
+	if meta.Has(typ) == false {
+		return nil, fmt.Errorf("model %v is unknown", typ)
+	}
+
+	h := &StoreHandler{
+		store:  store,
+		meta:   meta,
+		typ:    typ,
+		goType: goType,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h, nil
+}
+
+func (h *StoreHandler) Create(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	r := ctx.Resource
+	if err := h.setOwner(r); err != nil {
+		return nil, err
+	}
+
+	err := WithTx(h.store, func(tx Transaction) error {
+		if err := callHook(h.beforeCreate, ctx, tx, r); err != nil {
+			return err
+		}
+
+		if _, err := tx.Insert(r); err != nil {
+			return err
+		}
+		return callHook(h.afterCreate, ctx, tx, r)
+	})
+	if err != nil {
+		return nil, h.meta.ErrorToAPIError(h.typ, err)
+	}
+	return r, nil
+}
+
+func (h *StoreHandler) Get(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	var r resource.Resource
+	err := WithTx(h.store, func(tx Transaction) error {
+		var err error
+		r, err = h.get(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, h.meta.ErrorToAPIError(h.typ, err)
+	}
+	return r, nil
+}
+
+// return nil if the resource doesn't exist
+func (h *StoreHandler) get(ctx *resource.Context, tx Transaction) (resource.Resource, error) {
+	out := reflector.NewSlicePointer(reflect.PointerTo(h.goType))
+	conds := h.ownerConds(ctx)
+	conds[IDField] = ctx.Resource.GetID()
+	if err := tx.Fill(conds, out); err != nil {
+		return nil, err
+	}
+
+	rs := reflect.ValueOf(out).Elem()
+	if rs.Len() == 0 {
+		return nil, nil
+	}
+	return rs.Index(0).Interface().(resource.Resource), nil
+}
+
+// filters, sorts and pagination of the request are applied by store
+func (h *StoreHandler) List(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+	conds, apiErr := h.meta.FiltersToConds(h.typ, ctx.GetFilters())
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	for k, v := range h.ownerConds(ctx) {
+		conds[k] = v
+	}
+	if sorts := ctx.GetSorts(); len(sorts) > 0 {
+		if apiErr := h.meta.CheckSorts(h.typ, sorts); apiErr != nil {
+			return nil, apiErr
+		}
+		conds[CondOrderBy] = sorts
+		ctx.SetSortsApplied()
+	}
+
+	out := reflector.NewSlicePointer(reflect.PointerTo(h.goType))
+	err := WithTx(h.store, func(tx Transaction) error {
+		return tx.FillWithPagination(conds, out, ctx.GetPagination())
+	})
+	if err != nil {
+		return nil, h.meta.ErrorToAPIError(h.typ, err)
+	}
+	return reflect.ValueOf(out).Elem().Interface(), nil
+}
+
+func (h *StoreHandler) Update(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	return h.update(ctx)
+}
+
+// resource in context is the patched one
+func (h *StoreHandler) Patch(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	return h.update(ctx)
+}
+
+// resource version is compared if it's specified
+func (h *StoreHandler) update(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	r := ctx.Resource
+	if err := h.setOwner(r); err != nil {
+		return nil, err
+	}
+
+	var updated resource.Resource
+	err := WithTx(h.store, func(tx Transaction) error {
+		if err := callHook(h.beforeUpdate, ctx, tx, r); err != nil {
+			return err
+		}
+
+		nv, err := ResourceToMap(r)
+		if err != nil {
+			return err
+		}
+
+		conds := h.ownerConds(ctx)
+		conds[IDField] = r.GetID()
+		if version := r.GetResourceVersion(); version != 0 {
+			conds[ResourceVersionField] = version
+		}
+
+		if rows, err := tx.Update(h.typ, nv, conds); errors.Is(err, ErrResourceVersionConflict) {
+			return h.versionConflictError(ctx, tx)
+		} else if err != nil {
+			return err
+		} else if rows == 0 {
+			return notFoundError(ctx)
+		}
+
+		if updated, err = h.get(ctx, tx); err != nil {
+			return err
+		} else if updated == nil {
+			return notFoundError(ctx)
+		}
+		return callHook(h.afterUpdate, ctx, tx, updated)
+	})
+	if err != nil {
+		return nil, h.meta.ErrorToAPIError(h.typ, err)
+	}
+	return updated, nil
+}
+
+func (h *StoreHandler) Delete(ctx *resource.Context) *goresterr.APIError {
+	err := WithTx(h.store, func(tx Transaction) error {
+		if err := callHook(h.beforeDelete, ctx, tx, ctx.Resource); err != nil {
+			return err
+		}
+
+		conds := h.ownerConds(ctx)
+		conds[IDField] = ctx.Resource.GetID()
+		if rows, err := tx.Delete(h.typ, conds); err != nil {
+			return err
+		} else if rows == 0 {
+			return notFoundError(ctx)
+		}
+		return callHook(h.afterDelete, ctx, tx, ctx.Resource)
+	})
+	if err != nil {
+		return h.meta.ErrorToAPIError(h.typ, err)
+	}
+	return nil
+}
+
+// owner column which has the type of parent, parent isn't in conds if
+// it isn't an owner
+func (h *StoreHandler) ownerColumn(ctx *resource.Context) (string, string) {
+	parent := ctx.Resource.GetParent()
+	if parent == nil {
+		return "", ""
+	}
+
+	descriptor, err := h.meta.GetDescriptor(h.typ)
+	if err != nil {
+		return "", ""
+	}
+
+	parentType := ResourceDBType(parent)
+	for _, owner := range descriptor.Owners {
+		if owner == parentType {
+			return string(owner), parent.GetID()
+		}
+	}
+	return "", ""
+}
+
+func (h *StoreHandler) ownerConds(ctx *resource.Context) map[string]interface{} {
+	conds := make(map[string]interface{})
+	if column, id := h.ownerColumn(ctx); column != "" {
+		conds[column] = id
+	}
+	return conds
+}
+
+// owner field of the resource is always the parent in url
+func (h *StoreHandler) setOwner(r resource.Resource) *goresterr.APIError {
+	ctx := &resource.Context{Resource: r}
+	column, id := h.ownerColumn(ctx)
+	if column == "" {
+		return nil
+	}
+
+	v, ok := reflector.GetStructFromPointer(r)
+	if ok == false {
+		return goresterr.NewAPIError(goresterr.ServerError,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageInvalidOwnerField, goresterr.Params{"type": h.typ, "field": column}))
+	}
+
+	field := v.FieldByName(stringtool.ToUpperCamel(column))
+	if field.IsValid() == false || field.Kind() != reflect.String {
+		return goresterr.NewAPIError(goresterr.ServerError,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageInvalidOwnerField, goresterr.Params{"type": h.typ, "field": column}))
+	}
+	field.SetString(id)
+	return nil
+}
+
+func callHook(hook Hook, ctx *resource.Context, tx Transaction, r resource.Resource) error {
+	if hook == nil {
+		return nil
+	}
+	return hook(ctx, tx, r)
+}
+
+// conflict error carries the current resource version
+func (h *StoreHandler) versionConflictError(ctx *resource.Context, tx Transaction) error {
+	current, err := h.get(ctx, tx)
+	if err != nil {
+		return err
+	} else if current == nil {
+		return notFoundError(ctx)
+	}
+
+	return goresterr.NewAPIError(goresterr.Conflict,
+		*goresterr.NewLocalizedErrorMessage("", goresterr.MessageResourceVersionConflict,
+			goresterr.Params{"type": ctx.Resource.GetType(), "id": ctx.Resource.GetID(),
+				"version": current.GetResourceVersion()}))
+}
+
+func notFoundError(ctx *resource.Context) *goresterr.APIError {
+	return goresterr.NewAPIError(goresterr.NotFound,
+		*goresterr.NewLocalizedErrorMessage("", goresterr.MessageResourceNotFound,
+			goresterr.Params{"type": ctx.Resource.GetType(), "id": ctx.Resource.GetID()}))
+}
@@ -0,0 +1,243 @@
+package db
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Kseleven/pgx/v5/pgconn"
+	ut "github.com/linkingthing/cement/unittest"
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+)
+
+type Domain struct {
+	resource.ResourceBase
+	Name string
+}
+
+type Record struct {
+	resource.ResourceBase
+	Domain string `db:"ownby"`
+	Name   string `db:"uk"`
+}
+
+type fakeStore struct {
+	tx *fakeTx
+}
+
+func (s *fakeStore) Clean()                      {}
+func (s *fakeStore) Close()                      {}
+func (s *fakeStore) SetSchema(string)            {}
+func (s *fakeStore) GetSchema() string           { return "" }
+func (s *fakeStore) DropSchemas(...string) error { return nil }
+func (s *fakeStore) Begin() (Transaction, error) { return s.tx, nil }
+
+// fakeTx keep rrs in memory, conds of the last query are recorded
+type fakeTx struct {
+	Transaction
+	rrs        []*Record
+	conds      map[string]interface{}
+	err        error
+	committed  bool
+	rolledBack bool
+}
+
+func (tx *fakeTx) Insert(r resource.Resource) (resource.Resource, error) {
+	if tx.err != nil {
+		return nil, tx.err
+	}
+	tx.rrs = append(tx.rrs, r.(*Record))
+	return r, nil
+}
+
+func (tx *fakeTx) Fill(conds map[string]interface{}, out interface{}) error {
+	tx.conds = conds
+	rrs := out.(*[]*Record)
+	for _, rr := range tx.rrs {
+		if tx.match(rr, conds) {
+			*rrs = append(*rrs, rr)
+		}
+	}
+	return nil
+}
+
+func (tx *fakeTx) FillWithPagination(conds map[string]interface{}, out interface{}, pagination *resource.Pagination) error {
+	return tx.Fill(conds, out)
+}
+
+func (tx *fakeTx) Update(typ ResourceType, nv map[string]interface{}, conds map[string]interface{}) (int64, error) {
+	tx.conds = conds
+	if tx.err != nil {
+		return 0, tx.err
+	}
+
+	for _, rr := range tx.rrs {
+		if tx.match(rr, conds) == false {
+			continue
+		}
+		if version, ok := conds[ResourceVersionField]; ok && version != rr.GetResourceVersion() {
+			return 0, ErrResourceVersionConflict
+		}
+		rr.Name = nv["name"].(string)
+		rr.SetResourceVersion(rr.GetResourceVersion() + 1)
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (tx *fakeTx) Delete(typ ResourceType, conds map[string]interface{}) (int64, error) {
+	tx.conds = conds
+	if tx.err != nil {
+		return 0, tx.err
+	}
+
+	for i, rr := range tx.rrs {
+		if tx.match(rr, conds) {
+			tx.rrs = append(tx.rrs[:i], tx.rrs[i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.rolledBack = true
+	return nil
+}
+
+func (tx *fakeTx) match(rr *Record, conds map[string]interface{}) bool {
+	if id, ok := conds[IDField]; ok && id != rr.GetID() {
+		return false
+	}
+	if domain, ok := conds["domain"]; ok && domain != rr.Domain {
+		return false
+	}
+	return true
+}
+
+func newRecordContext(method, domainID, id string) *resource.Context {
+	domain := &Domain{}
+	domain.SetID(domainID)
+	rr := &Record{Name: "www"}
+	rr.SetID(id)
+	rr.SetType("record")
+	rr.SetParent(domain)
+	return &resource.Context{Resource: rr, Method: method}
+}
+
+func newRecordHandler(t *testing.T, opts ...HandlerOption) (*StoreHandler, *fakeTx) {
+	meta, err := NewResourceMeta([]resource.Resource{&Domain{}, &Record{}})
+	ut.Assert(t, err == nil, "new meta failed:%v", err)
+
+	tx := &fakeTx{}
+	h, err := NewStoreHandler(Record{}, &fakeStore{tx: tx}, meta, opts...)
+	ut.Assert(t, err == nil, "new handler failed:%v", err)
+	return h, tx
+}
+
+func TestStoreHandlerCRUD(t *testing.T) {
+	h, tx := newRecordHandler(t)
+
+	r, err := h.Create(newRecordContext(http.MethodPost, "z1", "rr1"))
+	ut.Assert(t, err == nil, "create failed:%v", err)
+	ut.Equal(t, r.(*Record).Domain, "z1")
+	ut.Assert(t, tx.committed, "transaction should be committed")
+
+	_, err = h.Create(newRecordContext(http.MethodPost, "z2", "rr2"))
+	ut.Assert(t, err == nil, "create failed:%v", err)
+
+	r, err = h.Get(newRecordContext(http.MethodGet, "z1", "rr1"))
+	ut.Assert(t, err == nil, "get failed:%v", err)
+	ut.Equal(t, r.GetID(), "rr1")
+	ut.Equal(t, tx.conds, map[string]interface{}{IDField: "rr1", "domain": "z1"})
+
+	//rr2 isn't owned by z1
+	r, err = h.Get(newRecordContext(http.MethodGet, "z1", "rr2"))
+	ut.Assert(t, err == nil && r == nil, "rr2 shouldn't be found in z1")
+
+	rs, err := h.List(newRecordContext(http.MethodGet, "z2", ""))
+	ut.Assert(t, err == nil, "list failed:%v", err)
+	ut.Equal(t, len(rs.([]*Record)), 1)
+	ut.Equal(t, rs.([]*Record)[0].GetID(), "rr2")
+
+	ctx := newRecordContext(http.MethodPut, "z1", "rr1")
+	ctx.Resource.(*Record).Name = "ftp"
+	r, err = h.Update(ctx)
+	ut.Assert(t, err == nil, "update failed:%v", err)
+	ut.Equal(t, r.(*Record).Name, "ftp")
+	ut.Equal(t, r.GetResourceVersion(), int64(1))
+
+	ctx = newRecordContext(http.MethodPut, "z1", "rr1")
+	ctx.Resource.SetResourceVersion(5)
+	_, err = h.Update(ctx)
+	ut.Assert(t, err != nil && err.ErrorCode == goresterr.Conflict, "stale version should conflict")
+	ut.Equal(t, err.MessageParams["version"], int64(1))
+	ut.Assert(t, tx.rolledBack, "transaction should be rolled back")
+
+	_, err = h.Update(newRecordContext(http.MethodPut, "z2", "rr1"))
+	ut.Assert(t, err != nil && err.ErrorCode == goresterr.NotFound, "rr1 isn't in z2")
+
+	err = h.Delete(newRecordContext(http.MethodDelete, "z1", "rr1"))
+	ut.Assert(t, err == nil, "delete failed:%v", err)
+	ut.Equal(t, len(tx.rrs), 1)
+
+	err = h.Delete(newRecordContext(http.MethodDelete, "z1", "rr1"))
+	ut.Assert(t, err != nil && err.ErrorCode == goresterr.NotFound, "rr1 has been deleted")
+}
+
+func TestStoreHandlerError(t *testing.T) {
+	h, tx := newRecordHandler(t)
+
+	tx.err = &pgconn.PgError{Code: pgUniqueViolation}
+	_, err := h.Create(newRecordContext(http.MethodPost, "z1", "rr1"))
+	ut.Assert(t, err != nil && err.ErrorCode == goresterr.DuplicateResource, "unique violation should be duplicate")
+
+	tx.err = &pgconn.PgError{Code: pgForeignKeyViolation, Detail: `Key (id)=(rr1) is still referenced from table "gr_rdata".`}
+	err = h.Delete(newRecordContext(http.MethodDelete, "z1", "rr1"))
+	ut.Assert(t, err != nil && err.ErrorCode == goresterr.DeleteParent, "foreign key violation should be delete parent")
+
+	tx.err = &pgconn.PgError{Code: pgForeignKeyViolation, TableName: "gr_record"}
+	_, err = h.Create(newRecordContext(http.MethodPost, "z1", "rr1"))
+	ut.Assert(t, err != nil && err.ErrorCode == goresterr.NotFound, "referenced resource doesn't exist")
+
+	tx.err = &pgconn.PgError{Code: "42P01"}
+	_, err = h.Create(newRecordContext(http.MethodPost, "z1", "rr1"))
+	ut.Assert(t, err != nil && err.ErrorCode == goresterr.ServerError, "other error should be server error")
+}
+
+func TestStoreHandlerHooks(t *testing.T) {
+	var called []string
+	hook := func(name string) Hook {
+		return func(ctx *resource.Context, tx Transaction, r resource.Resource) error {
+			called = append(called, name)
+			return nil
+		}
+	}
+
+	h, tx := newRecordHandler(t,
+		WithBeforeCreate(hook("beforeCreate")),
+		WithAfterCreate(hook("afterCreate")),
+		WithBeforeUpdate(hook("beforeUpdate")),
+		WithAfterUpdate(hook("afterUpdate")),
+		WithBeforeDelete(func(ctx *resource.Context, tx Transaction, r resource.Resource) error {
+			return goresterr.NewAPIError(goresterr.PermissionDenied, goresterr.ErrorMessage{MessageEN: "locked"})
+		}),
+		WithAfterDelete(hook("afterDelete")))
+
+	_, err := h.Create(newRecordContext(http.MethodPost, "z1", "rr1"))
+	ut.Assert(t, err == nil, "create failed:%v", err)
+	_, err = h.Update(newRecordContext(http.MethodPut, "z1", "rr1"))
+	ut.Assert(t, err == nil, "update failed:%v", err)
+	ut.Equal(t, called, []string{"beforeCreate", "afterCreate", "beforeUpdate", "afterUpdate"})
+
+	err = h.Delete(newRecordContext(http.MethodDelete, "z1", "rr1"))
+	ut.Assert(t, err != nil && err.ErrorCode == goresterr.PermissionDenied, "hook error should be returned")
+	ut.Equal(t, len(tx.rrs), 1)
+	//after delete isn't called
+	ut.Equal(t, called, []string{"beforeCreate", "afterCreate", "beforeUpdate", "afterUpdate"})
+}
@@ -0,0 +1,270 @@
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/linkingthing/gorest/resource"
+)
+
+// reserved key in conds, its value is the continue token of keyset pagination
+const CondContinue = "continue"
+
+// malformed continue token or the one doesn't match the order of request,
+// it's returned to client as InvalidFormat
+var ErrInvalidContinueToken = errors.New("invalid continue token")
+
+// continue token records the order columns and their values of the last
+// resource in previous page, values are in text format so postgresql
+// could cast them to the column types, nil value means NULL
+type continueToken struct {
+	Columns []orderColumn `json:"columns,omitempty"`
+	Values  []*string     `json:"values,omitempty"`
+	ID      string        `json:"id"`
+}
+
+func encodeContinueToken(token *continueToken) (string, error) {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeContinueToken(s string) (*continueToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s", ErrInvalidContinueToken, s)
+	}
+
+	var token continueToken
+	if err := json.Unmarshal(data, &token); err != nil || token.ID == "" || len(token.Columns) != len(token.Values) {
+		return nil, fmt.Errorf("%w %s", ErrInvalidContinueToken, s)
+	}
+	return &token, nil
+}
+
+// keyset pagination compares (columns..., id) as a row, so all the
+// columns should be in the same direction, columns after id are
+// useless since id is unique, return the columns before id and
+// whether the order is descending
+func keysetColumns(columns []orderColumn) ([]orderColumn, bool, error) {
+	if len(columns) == 0 {
+		return nil, false, nil
+	}
+
+	desc := columns[0].Desc
+	var keyColumns []orderColumn
+	for _, column := range columns {
+		if column.Desc != desc {
+			return nil, false, fmt.Errorf("keyset pagination requires all order columns in same direction")
+		}
+
+		if column.Name == IDField {
+			break
+		}
+		keyColumns = append(keyColumns, column)
+	}
+	return keyColumns, desc, nil
+}
+
+// empty token means the first page, if all the columns are not null they
+// are compared as a row which could use the index, otherwise the row
+// comparison is expanded so the rows with NULL aren't skipped, NULL is
+// larger than any value like the default order of postgresql
+func keysetSqlAndArgs(descriptor *ResourceDescriptor, token_ any, columns []orderColumn, markerSeq int) (string, []any, error) {
+	keyColumns, desc, err := keysetColumns(columns)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tokenStr, ok := token_.(string)
+	if ok == false {
+		return "", nil, fmt.Errorf("continue argument isn't string:%v", token_)
+	} else if tokenStr == "" {
+		return "", nil, nil
+	}
+
+	token, err := decodeContinueToken(tokenStr)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(token.Columns) != len(keyColumns) {
+		return "", nil, fmt.Errorf("%w: it doesn't match the order of request", ErrInvalidContinueToken)
+	}
+	for i, column := range keyColumns {
+		if token.Columns[i] != column {
+			return "", nil, fmt.Errorf("%w: it doesn't match the order of request", ErrInvalidContinueToken)
+		}
+	}
+
+	operator := ">"
+	if desc {
+		operator = "<"
+	}
+
+	if len(keyColumns) == 0 {
+		return fmt.Sprintf("id %s $%d", operator, markerSeq), []any{token.ID}, nil
+	}
+
+	nullable := false
+	markers := make([]string, 0, len(keyColumns))
+	args := make([]any, 0, len(keyColumns)+1)
+	for i, column := range keyColumns {
+		columnType, err := getColumnType(descriptor, column.Name)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if isNullableColumn(descriptor, column.Name) {
+			nullable = true
+		} else if token.Values[i] == nil {
+			return "", nil, fmt.Errorf("%w: value of %s is null", ErrInvalidContinueToken, column.Name)
+		}
+
+		if token.Values[i] == nil {
+			markers = append(markers, "")
+			continue
+		}
+		markers = append(markers, fmt.Sprintf("$%d::%s", markerSeq, columnType))
+		args = append(args, *token.Values[i])
+		markerSeq += 1
+	}
+	idMarker := fmt.Sprintf("$%d", markerSeq)
+	args = append(args, token.ID)
+
+	if nullable == false {
+		names := make([]string, 0, len(keyColumns)+1)
+		for _, column := range keyColumns {
+			names = append(names, column.Name)
+		}
+		names = append(names, IDField)
+		markers = append(markers, idMarker)
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(names, ", "), operator, strings.Join(markers, ", ")), args, nil
+	}
+
+	var terms []string
+	var equals []string
+	for i, column := range keyColumns {
+		var after, equal string
+		switch {
+		case markers[i] == "" && desc:
+			after, equal = column.Name+" is not null", column.Name+" is null"
+		case markers[i] == "":
+			equal = column.Name + " is null"
+		case desc || isNullableColumn(descriptor, column.Name) == false:
+			after, equal = fmt.Sprintf("%s %s %s", column.Name, operator, markers[i]), fmt.Sprintf("%s = %s", column.Name, markers[i])
+		default:
+			after = fmt.Sprintf("(%s %s %s or %s is null)", column.Name, operator, markers[i], column.Name)
+			equal = fmt.Sprintf("%s = %s", column.Name, markers[i])
+		}
+
+		if after != "" && len(equals) == 0 {
+			terms = append(terms, after)
+		} else if after != "" {
+			terms = append(terms, "("+strings.Join(append(append([]string{}, equals...), after), " and ")+")")
+		}
+		equals = append(equals, equal)
+	}
+	terms = append(terms, "("+strings.Join(append(equals, fmt.Sprintf("id %s %s", operator, idMarker)), " and ")+")")
+	if len(terms) == 1 {
+		return terms[0], args, nil
+	}
+	return "(" + strings.Join(terms, " or ") + ")", args, nil
+}
+
+// columns which aren't declared not null could be NULL, except the ones
+// always set by store
+func isNullableColumn(descriptor *ResourceDescriptor, column string) bool {
+	if column == CreateTimeField || column == ResourceVersionField {
+		return false
+	}
+
+	field, ok := getColumnField(descriptor, column)
+	return ok && field.NotNull == false
+}
+
+func getColumnType(descriptor *ResourceDescriptor, column string) (string, error) {
+	if field, ok := getColumnField(descriptor, column); ok {
+		return postgresqlTypeMap[field.Type], nil
+	}
+	return "", fmt.Errorf("unknown column %s of %s", column, descriptor.Typ)
+}
+
+// generate the continue token from the last resource of current page
+func genContinueToken(r resource.Resource, columns []orderColumn) (string, error) {
+	keyColumns, _, err := keysetColumns(columns)
+	if err != nil {
+		return "", err
+	}
+
+	token := &continueToken{Columns: keyColumns, ID: r.GetID()}
+	var values map[string]interface{}
+	for _, column := range keyColumns {
+		if column.Name == CreateTimeField {
+			text := r.GetCreationTimestamp().Format(time.RFC3339Nano)
+			token.Values = append(token.Values, &text)
+			continue
+		} else if column.Name == ResourceVersionField {
+			text := strconv.FormatInt(r.GetResourceVersion(), 10)
+			token.Values = append(token.Values, &text)
+			continue
+		}
+
+		if values == nil {
+			if values, err = ResourceToMap(r); err != nil {
+				return "", err
+			}
+		}
+
+		value, ok := values[column.Name]
+		if ok == false {
+			return "", fmt.Errorf("unknown column %s of %s", column.Name, ResourceDBType(r))
+		}
+		if isNilValue(value) {
+			token.Values = append(token.Values, nil)
+			continue
+		}
+		text := columnValueToText(value)
+		token.Values = append(token.Values, &text)
+	}
+
+	return encodeContinueToken(token)
+}
+
+func isNilValue(value any) bool {
+	if value == nil {
+		return true
+	}
+
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+func columnValueToText(value any) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case net.IPNet:
+		return v.String()
+	case fmt.Stringer:
+		if reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil() {
+			return ""
+		}
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
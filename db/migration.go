@@ -0,0 +1,451 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Kseleven/pgx/v5"
+)
+
+const (
+	MigrationTable         = "gr_schema_migrations"
+	autoMigrationPrefix    = "auto_"
+	autoMigrationTimestamp = "20060102150405.000000"
+	//key of the advisory lock which serializes migrations
+	migrationLockKey int64 = 0x67725f6d6967
+)
+
+// Migration is the data change which can't be generated from resource
+// descriptors, like backfill of a new column, migrations are run in
+// version order after the tables are migrated, and each version is run
+// only once, Up shouldn't commit or rollback the transaction
+type Migration struct {
+	Version     string
+	Description string
+	Up          func(tx Transaction) error
+}
+
+// WithMigrations register the go migrations
+func WithMigrations(migrations ...Migration) Option {
+	return func(r ResourceStore) {
+		if store, ok := r.(*PGStore); ok {
+			store.migrations = append(store.migrations, migrations...)
+		}
+	}
+}
+
+// WithMigrationDryRun write the migration plan to w instead of applying it
+func WithMigrationDryRun(w io.Writer) Option {
+	return func(r ResourceStore) {
+		if store, ok := r.(*PGStore); ok {
+			store.dryRun = w
+		}
+	}
+}
+
+// MigrationPlan has the sql generated by comparing resource descriptors
+// with the tables in database, and the go migrations which aren't applied,
+// new not null columns are added as nullable in Statements, and set to
+// not null in NotNullStatements after the go migrations backfill them
+type MigrationPlan struct {
+	Statements        []string
+	Migrations        []Migration
+	NotNullStatements []string
+}
+
+func (plan *MigrationPlan) IsEmpty() bool {
+	return len(plan.Statements) == 0 && len(plan.Migrations) == 0 && len(plan.NotNullStatements) == 0
+}
+
+func (plan *MigrationPlan) String() string {
+	var buf strings.Builder
+	for _, statement := range plan.Statements {
+		buf.WriteString(statement)
+		buf.WriteString(";\n")
+	}
+
+	for _, m := range plan.Migrations {
+		buf.WriteString("-- migration ")
+		buf.WriteString(m.Version)
+		if m.Description != "" {
+			buf.WriteString(": ")
+			buf.WriteString(m.Description)
+		}
+		buf.WriteString("\n")
+	}
+
+	for _, statement := range plan.NotNullStatements {
+		buf.WriteString(statement)
+		buf.WriteString(";\n")
+	}
+	return buf.String()
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// tableSchema is the current columns, indexes and unique constraints of
+// a table, unique constraint is the column names joined with comma
+type tableSchema struct {
+	columns map[string]struct{}
+	indexes map[string]struct{}
+	uniques map[string]struct{}
+}
+
+func (store *PGStore) migrate() error {
+	if store.dryRun != nil {
+		plan, err := store.PlanMigration()
+		if err != nil {
+			return err
+		}
+
+		_, err = io.WriteString(store.dryRun, plan.String())
+		return err
+	}
+
+	tx, err := store.pool.Begin(context.TODO())
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(context.TODO())
+
+	//replicas started at the same time migrate one by one, the lock is
+	//released when the transaction ends
+	if _, err := tx.Exec(context.TODO(), "select pg_advisory_xact_lock($1)", migrationLockKey); err != nil {
+		return fmt.Errorf("lock migration failed: %v", err)
+	}
+
+	//plan in the transaction, so the tables aren't changed by others
+	plan, err := store.planMigration(tx)
+	if err != nil {
+		return err
+	}
+
+	if plan.IsEmpty() {
+		return nil
+	}
+
+	if err := store.applyMigration(tx, plan); err != nil {
+		return err
+	}
+	return tx.Commit(context.TODO())
+}
+
+// PlanMigration return the changes to migrate the database to resource
+// descriptors and registered migrations, nothing is changed
+func (store *PGStore) PlanMigration() (*MigrationPlan, error) {
+	return store.planMigration(store.pool)
+}
+
+func (store *PGStore) planMigration(q querier) (*MigrationPlan, error) {
+	plan := &MigrationPlan{}
+	var notNullTables []ResourceType
+	for _, descriptor := range store.meta.GetDescriptors() {
+		current, err := store.loadTableSchema(q, getTableNameWithoutSchema(store.schema, descriptor.Typ))
+		if err != nil {
+			return nil, fmt.Errorf("load schema of table %s failed: %v", descriptor.Typ, err)
+		}
+
+		statements, notNullStatements := store.tableChanges(descriptor, current)
+		plan.Statements = append(plan.Statements, statements...)
+		plan.NotNullStatements = append(plan.NotNullStatements, notNullStatements...)
+		if len(notNullStatements) > 0 {
+			notNullTables = append(notNullTables, descriptor.Typ)
+		}
+	}
+
+	migrationTable, err := store.loadTableSchema(q, MigrationTable)
+	if err != nil {
+		return nil, fmt.Errorf("load schema of table %s failed: %v", MigrationTable, err)
+	}
+
+	applied := make(map[string]struct{})
+	if len(migrationTable.columns) == 0 {
+		plan.Statements = append(plan.Statements, store.createMigrationTableSql())
+	} else if applied, err = store.loadAppliedVersions(q); err != nil {
+		return nil, fmt.Errorf("load applied migrations failed: %v", err)
+	}
+
+	if plan.Migrations, err = pendingMigrations(store.migrations, applied); err != nil {
+		return nil, err
+	}
+
+	//without go migration, new not null columns of the table which has
+	//rows can't be backfilled
+	if len(plan.Migrations) == 0 {
+		for _, typ := range notNullTables {
+			if hasRows, err := store.tableHasRows(q, typ); err != nil {
+				return nil, fmt.Errorf("check rows of table %s failed: %v", typ, err)
+			} else if hasRows {
+				return nil, fmt.Errorf("table %s has rows, new not null columns should be backfilled by a migration", typ)
+			}
+		}
+	}
+	return plan, nil
+}
+
+func (store *PGStore) tableHasRows(q querier, typ ResourceType) (bool, error) {
+	rows, err := q.Query(context.TODO(), "select exists (select 1 from "+getTableName(store.schema, typ)+")")
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	var exists bool
+	for rows.Next() {
+		if err := rows.Scan(&exists); err != nil {
+			return false, err
+		}
+	}
+	return exists, rows.Err()
+}
+
+// the table is created if it doesn't exist, otherwise the missing
+// columns, unique constraints and indexes are added, columns aren't
+// dropped or altered, new not null columns are added as nullable, the
+// statements to set them not null are returned separately
+func (store *PGStore) tableChanges(descriptor *ResourceDescriptor, current tableSchema) ([]string, []string) {
+	if len(current.columns) == 0 {
+		table, indexes := store.createTableSql(descriptor)
+		return append([]string{table}, indexes...), nil
+	}
+
+	var statements, notNullStatements []string
+	tableName := getTableName(store.schema, descriptor.Typ)
+	addColumn := func(name, columnSql string, notNull bool) {
+		if _, ok := current.columns[name]; ok == false {
+			statements = append(statements, "alter table "+tableName+" add column "+columnSql)
+			if notNull {
+				notNullStatements = append(notNullStatements,
+					"alter table "+tableName+" alter column "+name+" set not null")
+			}
+		}
+	}
+
+	var uniques [][]string
+	for _, field := range descriptor.Fields {
+		if field.Unique {
+			uniques = append(uniques, []string{field.Name})
+		}
+		//unique constraint is added with the constraints of existing columns
+		notNull := field.NotNull
+		field.Unique = false
+		field.NotNull = false
+		addColumn(field.Name, fieldColumnSql(field), notNull)
+	}
+
+	for _, owner := range descriptor.Owners {
+		addColumn(string(owner), store.referenceColumnSql(owner, "cascade", false), true)
+	}
+
+	for _, refer := range descriptor.Refers {
+		addColumn(string(refer), store.referenceColumnSql(refer, "restrict", false), true)
+	}
+
+	//existing rows get the default value
+	addColumn(ResourceVersionField, resourceVersionColumnSql, false)
+
+	if len(descriptor.Uks) > 0 {
+		var columns []string
+		for _, uk := range descriptor.Uks {
+			columns = append(columns, string(uk))
+		}
+		uniques = append(uniques, columns)
+	}
+
+	tableNameWithoutSchema := getTableNameWithoutSchema(store.schema, descriptor.Typ)
+	for _, columns := range uniques {
+		if _, ok := current.uniques[strings.Join(columns, ",")]; ok == false {
+			statements = append(statements, "alter table "+tableName+" add constraint "+
+				tableNameWithoutSchema+"_"+strings.Join(columns, "_")+"_key unique ("+strings.Join(columns, ",")+")")
+		}
+	}
+
+	for _, index := range store.tableIndexes(descriptor) {
+		if _, ok := current.indexes[index.name]; ok == false {
+			statements = append(statements, index.sql)
+		}
+	}
+
+	return statements, notNullStatements
+}
+
+// indexes aren't in information_schema, so pg_indexes is used
+func (store *PGStore) loadTableSchema(q querier, table string) (tableSchema, error) {
+	current := tableSchema{
+		columns: make(map[string]struct{}),
+		indexes: make(map[string]struct{}),
+		uniques: make(map[string]struct{}),
+	}
+
+	columns, err := queryStrings(q,
+		"select column_name from information_schema.columns where table_schema=$1 and table_name=$2",
+		store.schema, table)
+	if err != nil {
+		return current, err
+	}
+	for _, column := range columns {
+		current.columns[column] = struct{}{}
+	}
+
+	if len(columns) == 0 {
+		return current, nil
+	}
+
+	indexes, err := queryStrings(q,
+		"select indexname from pg_indexes where schemaname=$1 and tablename=$2",
+		store.schema, table)
+	if err != nil {
+		return current, err
+	}
+	for _, index := range indexes {
+		current.indexes[index] = struct{}{}
+	}
+
+	rows, err := q.Query(context.TODO(),
+		"select tc.constraint_name, kcu.column_name from information_schema.table_constraints tc "+
+			"join information_schema.key_column_usage kcu on tc.constraint_schema=kcu.constraint_schema "+
+			"and tc.constraint_name=kcu.constraint_name and tc.table_name=kcu.table_name "+
+			"where tc.constraint_type='UNIQUE' and tc.table_schema=$1 and tc.table_name=$2 "+
+			"order by tc.constraint_name, kcu.ordinal_position",
+		store.schema, table)
+	if err != nil {
+		return current, err
+	}
+	defer rows.Close()
+
+	var names []string
+	constraints := make(map[string][]string)
+	for rows.Next() {
+		var name, column string
+		if err := rows.Scan(&name, &column); err != nil {
+			return current, err
+		}
+		if _, ok := constraints[name]; ok == false {
+			names = append(names, name)
+		}
+		constraints[name] = append(constraints[name], column)
+	}
+	if err := rows.Err(); err != nil {
+		return current, err
+	}
+
+	for _, name := range names {
+		current.uniques[strings.Join(constraints[name], ",")] = struct{}{}
+	}
+	return current, nil
+}
+
+func (store *PGStore) loadAppliedVersions(q querier) (map[string]struct{}, error) {
+	versions, err := queryStrings(q, "select version from "+store.migrationTableName())
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]struct{})
+	for _, version := range versions {
+		applied[version] = struct{}{}
+	}
+	return applied, nil
+}
+
+func queryStrings(q querier, sql string, args ...any) ([]string, error) {
+	rows, err := q.Query(context.TODO(), sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+// migrations which aren't applied, sorted by version
+func pendingMigrations(migrations []Migration, applied map[string]struct{}) ([]Migration, error) {
+	versions := make(map[string]struct{})
+	var pending []Migration
+	for _, m := range migrations {
+		if m.Version == "" {
+			return nil, fmt.Errorf("migration version is empty")
+		} else if m.Up == nil {
+			return nil, fmt.Errorf("migration %s has no up function", m.Version)
+		} else if strings.HasPrefix(m.Version, autoMigrationPrefix) {
+			return nil, fmt.Errorf("migration version %s has reserved prefix %s", m.Version, autoMigrationPrefix)
+		}
+
+		if _, ok := versions[m.Version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %s", m.Version)
+		}
+		versions[m.Version] = struct{}{}
+
+		if _, ok := applied[m.Version]; ok == false {
+			pending = append(pending, m)
+		}
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].Version < pending[j].Version
+	})
+	return pending, nil
+}
+
+// table changes are recorded as one auto version with the sql as
+// description
+func (store *PGStore) applyMigration(tx pgx.Tx, plan *MigrationPlan) error {
+	for _, statement := range plan.Statements {
+		logSql(statement)
+		if _, err := tx.Exec(context.TODO(), statement); err != nil {
+			return fmt.Errorf("exec %s failed: %v", statement, err)
+		}
+	}
+
+	storeTx := PGStoreTx{tx, NewBaseTx(store.meta, store.schema)}
+	insertSql := "insert into " + store.migrationTableName() + " (version, description) values ($1, $2)"
+	for _, m := range plan.Migrations {
+		if err := m.Up(storeTx); err != nil {
+			return fmt.Errorf("run migration %s failed: %v", m.Version, err)
+		}
+
+		if _, err := tx.Exec(context.TODO(), insertSql, m.Version, m.Description); err != nil {
+			return fmt.Errorf("record migration %s failed: %v", m.Version, err)
+		}
+	}
+
+	for _, statement := range plan.NotNullStatements {
+		logSql(statement)
+		if _, err := tx.Exec(context.TODO(), statement); err != nil {
+			return fmt.Errorf("exec %s failed, the column isn't backfilled by migrations: %v", statement, err)
+		}
+	}
+
+	statements := append(append([]string{}, plan.Statements...), plan.NotNullStatements...)
+	if len(statements) > 0 {
+		version := autoMigrationPrefix + time.Now().UTC().Format(autoMigrationTimestamp)
+		if _, err := tx.Exec(context.TODO(), insertSql, version, strings.Join(statements, ";\n")); err != nil {
+			return fmt.Errorf("record migration %s failed: %v", version, err)
+		}
+	}
+	return nil
+}
+
+func (store *PGStore) migrationTableName() string {
+	return store.schema + "." + MigrationTable
+}
+
+func (store *PGStore) createMigrationTableSql() string {
+	return "create table if not exists " + store.migrationTableName() +
+		" (version text primary key, description text not null default '', " +
+		"applied_at timestamp with time zone not null default now())"
+}
@@ -0,0 +1,161 @@
+package db
+
+import (
+	"strings"
+	"testing"
+
+	ut "github.com/linkingthing/cement/unittest"
+	"github.com/linkingthing/gorest/resource"
+)
+
+type Host struct {
+	resource.ResourceBase
+	Name   string   `db:"uk"`
+	Serial string   `db:"suk"`
+	Ip     string   `db:"snk"`
+	Tags   []string `db:"snk"`
+	Domain string   `db:"ownby"`
+}
+
+func newMigrationStore(t *testing.T) (*PGStore, *ResourceDescriptor) {
+	meta, err := NewResourceMeta([]resource.Resource{&Domain{}, &Host{}})
+	ut.Assert(t, err == nil, "new meta failed:%v", err)
+
+	descriptor, err := meta.GetDescriptor("host")
+	ut.Assert(t, err == nil, "get descriptor failed:%v", err)
+	return &PGStore{schema: DefaultSchemaName, driver: DriverPostgresql, meta: meta}, descriptor
+}
+
+func newTableSchema(columns, indexes, uniques []string) tableSchema {
+	current := tableSchema{
+		columns: make(map[string]struct{}),
+		indexes: make(map[string]struct{}),
+		uniques: make(map[string]struct{}),
+	}
+	for _, column := range columns {
+		current.columns[column] = struct{}{}
+	}
+	for _, index := range indexes {
+		current.indexes[index] = struct{}{}
+	}
+	for _, unique := range uniques {
+		current.uniques[unique] = struct{}{}
+	}
+	return current
+}
+
+func TestTableChanges(t *testing.T) {
+	store, descriptor := newMigrationStore(t)
+
+	//missing table is created
+	statements, notNullStatements := store.tableChanges(descriptor, newTableSchema(nil, nil, nil))
+	table, indexes := store.createTableSql(descriptor)
+	ut.Equal(t, statements, append([]string{table}, indexes...))
+	ut.Equal(t, len(notNullStatements), 0)
+
+	//table is up to date
+	current := newTableSchema(
+		[]string{"id", "create_time", "name", "serial", "ip", "tags", "domain", "resource_version"},
+		[]string{"idx_gr_host_ip", "idx_gr_host_tags"},
+		[]string{"name", "serial"})
+	statements, notNullStatements = store.tableChanges(descriptor, current)
+	ut.Equal(t, len(statements)+len(notNullStatements), 0)
+
+	//table created by old version
+	current = newTableSchema(
+		[]string{"id", "create_time", "name", "ip"},
+		[]string{"idx_gr_host_ip"},
+		nil)
+	statements, notNullStatements = store.tableChanges(descriptor, current)
+	ut.Equal(t, statements, []string{
+		"alter table lx.gr_host add column serial text",
+		"alter table lx.gr_host add column tags text[]",
+		"alter table lx.gr_host add column domain text references lx.gr_domain (id) on delete cascade",
+		"alter table lx.gr_host add column resource_version bigint not null default 1",
+		"alter table lx.gr_host add constraint gr_host_serial_key unique (serial)",
+		"alter table lx.gr_host add constraint gr_host_name_key unique (name)",
+		"create index  if not exists idx_gr_host_tags on lx.gr_host using gin (tags)",
+	})
+	//not null is set after the go migrations backfill the column
+	ut.Equal(t, notNullStatements, []string{"alter table lx.gr_host alter column domain set not null"})
+
+	//openGauss doesn't support gin index
+	store.driver = DriverOpenGauss
+	current.columns["tags"] = struct{}{}
+	statements, _ = store.tableChanges(descriptor, current)
+	ut.Equal(t, statements[len(statements)-1], "create index  if not exists idx_gr_host_tags on lx.gr_host (tags)")
+}
+
+func TestPendingMigrations(t *testing.T) {
+	up := func(tx Transaction) error { return nil }
+	migrations := []Migration{
+		{Version: "20260301_backfill_serial", Up: up},
+		{Version: "20260101_init_domain", Description: "init domain", Up: up},
+		{Version: "20260201_rename_host", Up: up},
+	}
+
+	pending, err := pendingMigrations(migrations, map[string]struct{}{"20260201_rename_host": {}})
+	ut.Assert(t, err == nil, "pending migrations failed:%v", err)
+	ut.Equal(t, len(pending), 2)
+	ut.Equal(t, pending[0].Version, "20260101_init_domain")
+	ut.Equal(t, pending[1].Version, "20260301_backfill_serial")
+
+	plan := &MigrationPlan{
+		Statements:        []string{"alter table lx.gr_host add column serial text"},
+		Migrations:        pending,
+		NotNullStatements: []string{"alter table lx.gr_host alter column serial set not null"},
+	}
+	ut.Equal(t, plan.String(), "alter table lx.gr_host add column serial text;\n"+
+		"-- migration 20260101_init_domain: init domain\n"+
+		"-- migration 20260301_backfill_serial\n"+
+		"alter table lx.gr_host alter column serial set not null;\n")
+	ut.Assert(t, (&MigrationPlan{}).IsEmpty(), "plan should be empty")
+
+	for _, invalid := range [][]Migration{
+		{{Version: "", Up: up}},
+		{{Version: "20260101", Up: nil}},
+		{{Version: "auto_20260101", Up: up}},
+		{{Version: "20260101", Up: up}, {Version: "20260101", Up: up}},
+	} {
+		_, err := pendingMigrations(invalid, nil)
+		ut.Assert(t, err != nil, "migrations %v should be invalid", invalid)
+	}
+}
+
+func TestInsertAfterMigration(t *testing.T) {
+	store, descriptor := newMigrationStore(t)
+
+	//columns added by migration are appended to the table
+	tableColumns := []string{"id", "create_time", "name", "ip"}
+	current := newTableSchema(tableColumns, nil, nil)
+	statements, _ := store.tableChanges(descriptor, current)
+	for _, statement := range statements {
+		if _, column, ok := strings.Cut(statement, " add column "); ok {
+			tableColumns = append(tableColumns, strings.Fields(column)[0])
+		}
+	}
+	ut.Equal(t, tableColumns, []string{"id", "create_time", "name", "ip", "serial", "tags", "domain", "resource_version"})
+
+	host := &Host{Name: "h1", Serial: "s1", Ip: "10.0.0.1", Tags: []string{"web"}, Domain: "d1"}
+	host.SetResourceVersion(1)
+	sql, args, err := NewBaseTx(store.meta, "").insertSqlArgsAndID(host)
+	ut.Assert(t, err == nil, "insert sql failed:%v", err)
+
+	_, columnList, _ := strings.Cut(sql, "(")
+	columnList, _, _ = strings.Cut(columnList, ")")
+	columns := strings.Split(strings.ReplaceAll(columnList, " ", ""), ",")
+	ut.Equal(t, len(columns), len(args))
+
+	values := make(map[string]interface{})
+	for i, column := range columns {
+		values[column] = args[i]
+	}
+	for _, column := range tableColumns {
+		_, ok := values[column]
+		ut.Assert(t, ok, "column %s isn't inserted", column)
+	}
+	ut.Equal(t, values["serial"], "s1")
+	ut.Equal(t, values["tags"], []string{"web"})
+	ut.Equal(t, values["domain"], "d1")
+	ut.Equal(t, values["resource_version"], int64(1))
+}
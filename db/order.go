@@ -0,0 +1,151 @@
+package db
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/linkingthing/cement/stringtool"
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+)
+
+const creationTimestampColumn = "creation_timestamp"
+
+type orderColumn struct {
+	Name string `json:"name"`
+	Desc bool   `json:"desc,omitempty"`
+}
+
+// order in conds could be []resource.Sort, or a string like
+// "-create_time,name" or "create_time desc,name", field names
+// are converted to column names
+func parseOrderColumns(goTyp reflect.Type, order_ any) ([]orderColumn, error) {
+	var columns []orderColumn
+	switch order := order_.(type) {
+	case nil:
+	case []resource.Sort:
+		for _, s := range order {
+			columns = append(columns, orderColumn{Name: sortColumnName(goTyp, s.Field), Desc: s.Order == resource.SortDesc})
+		}
+	case string:
+		for _, seg := range strings.Split(order, ",") {
+			fields := strings.Fields(seg)
+			if len(fields) == 0 || len(fields) > 2 {
+				return nil, fmt.Errorf("invalid order argument:%s", order)
+			}
+
+			column := orderColumn{Name: fields[0]}
+			if strings.HasPrefix(column.Name, "-") {
+				column.Name = column.Name[1:]
+				column.Desc = true
+			}
+
+			if len(fields) == 2 {
+				switch strings.ToLower(fields[1]) {
+				case string(resource.SortAsc):
+				case string(resource.SortDesc):
+					column.Desc = true
+				default:
+					return nil, fmt.Errorf("invalid order argument:%s", order)
+				}
+			}
+
+			column.Name = toColumnName(column.Name)
+			columns = append(columns, column)
+		}
+	default:
+		return nil, fmt.Errorf("order argument isn't string or sorts:%v", order_)
+	}
+
+	for _, column := range columns {
+		if column.Name == "" {
+			return nil, fmt.Errorf("invalid order argument:%v", order_)
+		}
+	}
+	return columns, nil
+}
+
+// sort fields which are valid in go struct may not be stored, like the
+// ones with db:"-", they can't be sorted by store
+func (meta *ResourceMeta) CheckSorts(typ ResourceType, sorts []resource.Sort) *goresterr.APIError {
+	descriptor, err := meta.GetDescriptor(typ)
+	if err != nil {
+		return goresterr.NewAPIError(goresterr.ServerError,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageStoreFailed, goresterr.Params{"reason": err.Error()}))
+	}
+
+	goTyp, err := meta.GetGoType(typ)
+	if err != nil {
+		return goresterr.NewAPIError(goresterr.ServerError,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageStoreFailed, goresterr.Params{"reason": err.Error()}))
+	}
+
+	for _, s := range sorts {
+		if _, ok := getColumnField(descriptor, sortColumnName(goTyp, s.Field)); ok == false {
+			return goresterr.NewAPIError(goresterr.InvalidFormat,
+				*goresterr.NewLocalizedErrorMessage(fmt.Sprintf("field %s can't be sorted", s.Field),
+					goresterr.MessageInvalidQuery, goresterr.Params{"name": resource.FilterNameSort}))
+		}
+	}
+	return nil
+}
+
+// sort field is resolved through the go struct like the request
+// validation does, fields of ResourceBase are stored as the columns
+// named by their json names
+func sortColumnName(goTyp reflect.Type, field string) string {
+	index, ok := resource.FindSortField(goTyp, field)
+	if ok == false {
+		return toColumnName(field)
+	}
+
+	structField := goTyp.FieldByIndex(index)
+	if goTyp.Field(index[0]).Name == EmbedResource {
+		jsonName, _, _ := strings.Cut(structField.Tag.Get("json"), ",")
+		return toColumnName(jsonName)
+	}
+	return toColumnName(structField.Name)
+}
+
+func toColumnName(field string) string {
+	column := stringtool.ToSnake(field)
+	if column == creationTimestampColumn {
+		return CreateTimeField
+	}
+	return column
+}
+
+// id is appended to make the order stable if it's required
+func orderSql(descriptor *ResourceDescriptor, columns []orderColumn, withID bool) (string, error) {
+	if len(columns) == 0 {
+		return "order by id", nil
+	}
+
+	segs := make([]string, 0, len(columns)+1)
+	hasID := false
+	for _, column := range columns {
+		if _, ok := getColumnField(descriptor, column.Name); ok == false {
+			return "", fmt.Errorf("unknown order column %s of %s", column.Name, descriptor.Typ)
+		}
+
+		if column.Name == IDField {
+			hasID = true
+		}
+
+		if column.Desc {
+			segs = append(segs, column.Name+" desc")
+		} else {
+			segs = append(segs, column.Name)
+		}
+	}
+
+	if withID && hasID == false {
+		if columns[0].Desc {
+			segs = append(segs, "id desc")
+		} else {
+			segs = append(segs, "id")
+		}
+	}
+	return "order by " + strings.Join(segs, ", "), nil
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"strings"
 	"time"
@@ -17,10 +18,12 @@ import (
 )
 
 type PGStore struct {
-	schema string
-	pool   *pgxpool.Pool
-	meta   *ResourceMeta
-	driver Driver
+	schema     string
+	pool       *pgxpool.Pool
+	meta       *ResourceMeta
+	driver     Driver
+	migrations []Migration
+	dryRun     io.Writer
 }
 
 func NewPGStore(connStr string, driver Driver, meta *ResourceMeta, opts ...Option) (ResourceStore, error) {
@@ -41,24 +44,17 @@ func NewPGStore(connStr string, driver Driver, meta *ResourceMeta, opts ...Optio
 		opt(r)
 	}
 
-	if err := r.InitSchema(); err != nil {
-		pool.Close()
-		return nil, fmt.Errorf("init schema failed: %v", err)
-	}
-
-	for _, descriptor := range meta.GetDescriptors() {
-		cTable, cIndexes := r.createTableSql(descriptor)
-		if _, err := pool.Exec(context.TODO(), cTable); err != nil {
+	//dry run doesn't change the database
+	if r.dryRun == nil {
+		if err := r.InitSchema(); err != nil {
 			pool.Close()
-			return nil, fmt.Errorf("create table %s error: %v", cTable, err)
+			return nil, fmt.Errorf("init schema failed: %v", err)
 		}
+	}
 
-		for _, index := range cIndexes {
-			if _, err := pool.Exec(context.TODO(), index); err != nil {
-				pool.Close()
-				return nil, fmt.Errorf("create index failed:%s", err.Error())
-			}
-		}
+	if err := r.migrate(); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("migrate schema failed: %v", err)
 	}
 
 	return r, nil
@@ -69,59 +65,27 @@ func (store *PGStore) createTableSql(descriptor *ResourceDescriptor) (string, []
 	buf.WriteString("create table if not exists ")
 	buf.WriteString(getTableName(store.schema, descriptor.Typ))
 	buf.WriteString(" (")
-	tableName := getTableNameWithoutSchema(store.schema, descriptor.Typ)
 
-	var indexes []string
-	var ginIndexes []string
 	for _, field := range descriptor.Fields {
-		buf.WriteString(field.Name)
-		buf.WriteString(" ")
-		buf.WriteString(postgresqlTypeMap[field.Type])
-
-		if field.NotNull {
-			buf.WriteString(" ")
-			buf.WriteString("not null")
-		}
-
-		if field.Unique {
-			buf.WriteString(" ")
-			buf.WriteString("unique")
-		}
-
-		if field.Index {
-			if field.Type == StringArray || field.Type == IPSlice || field.Type == IPNetSlice ||
-				field.Type == SmallIntArray || field.Type == BigIntArray || field.Type == SuperIntArray ||
-				field.Type == Float32Array {
-				ginIndexes = append(ginIndexes, field.Name)
-			} else {
-				indexes = append(indexes, field.Name)
-			}
-		}
-
-		if field.Check == Positive {
-			buf.WriteString(" check(")
-			buf.WriteString(field.Name)
-			buf.WriteString(" > 0)")
-		}
+		buf.WriteString(fieldColumnSql(field))
 		buf.WriteString(",")
 	}
 
 	for _, owner := range descriptor.Owners {
-		buf.WriteString(string(owner))
-		buf.WriteString(" text not null references ")
-		buf.WriteString(getTableName(store.schema, owner))
-		buf.WriteString(" (id) on delete cascade")
+		buf.WriteString(store.referenceColumnSql(owner, "cascade", true))
 		buf.WriteString(",")
 	}
 
 	for _, refer := range descriptor.Refers {
-		buf.WriteString(string(refer))
-		buf.WriteString(" text not null references ")
-		buf.WriteString(getTableName(store.schema, refer))
-		buf.WriteString(" (id) on delete restrict")
+		buf.WriteString(store.referenceColumnSql(refer, "restrict", true))
 		buf.WriteString(",")
 	}
 
+	//resource version is the last column, so copy from without it
+	//uses the default value
+	buf.WriteString(resourceVersionColumnSql)
+	buf.WriteString(",")
+
 	if len(descriptor.Pks) > 0 {
 		buf.WriteString("primary key (")
 		for i, pk := range descriptor.Pks {
@@ -144,62 +108,94 @@ func (store *PGStore) createTableSql(descriptor *ResourceDescriptor) (string, []
 		buf.WriteString("),")
 	}
 
-	var idxBuf bytes.Buffer
 	var createIndexes []string
-	if len(descriptor.Idxes) > 0 {
-		idxBuf.WriteString("create index ")
-		idxBuf.WriteString(" if not exists ")
-		idxBuf.WriteString(IndexPrefix + tableName + "_" + strings.Join(descriptor.Idxes, "_"))
-		idxBuf.WriteString(" on ")
-		idxBuf.WriteString(getTableName(store.schema, descriptor.Typ))
-		idxBuf.WriteString(" (")
-		for i, idx := range descriptor.Idxes {
-			idxBuf.WriteString(idx)
-			if i < len(descriptor.Idxes)-1 {
-				idxBuf.WriteString(",")
-			}
-		}
-		idxBuf.WriteString(")")
-		createIndexes = append(createIndexes, idxBuf.String())
-		idxBuf.Reset()
-	}
-
-	if len(indexes) > 0 {
-		for _, index := range indexes {
-			idxBuf.WriteString("create index ")
-			idxBuf.WriteString(" if not exists ")
-			idxBuf.WriteString(IndexPrefix + tableName + "_" + index)
-			idxBuf.WriteString(" on ")
-			idxBuf.WriteString(getTableName(store.schema, descriptor.Typ))
-			idxBuf.WriteString(" (")
-			idxBuf.WriteString(index)
-			idxBuf.WriteString(")")
-
-			createIndexes = append(createIndexes, idxBuf.String())
-			idxBuf.Reset()
+	for _, index := range store.tableIndexes(descriptor) {
+		createIndexes = append(createIndexes, index.sql)
+	}
+
+	return strings.TrimRight(buf.String(), ",") + ")", createIndexes
+}
+
+const resourceVersionColumnSql = ResourceVersionField + " bigint not null default 1"
+
+func fieldColumnSql(field ResourceField) string {
+	var buf bytes.Buffer
+	buf.WriteString(field.Name)
+	buf.WriteString(" ")
+	buf.WriteString(postgresqlTypeMap[field.Type])
+
+	if field.NotNull {
+		buf.WriteString(" ")
+		buf.WriteString("not null")
+	}
+
+	if field.Unique {
+		buf.WriteString(" ")
+		buf.WriteString("unique")
+	}
+
+	if field.Check == Positive {
+		buf.WriteString(" check(")
+		buf.WriteString(field.Name)
+		buf.WriteString(" > 0)")
+	}
+	return buf.String()
+}
+
+// owner and refer columns reference the id of other tables
+func (store *PGStore) referenceColumnSql(typ ResourceType, onDelete string, notNull bool) string {
+	columnSql := string(typ) + " text"
+	if notNull {
+		columnSql += " not null"
+	}
+	return columnSql + " references " + getTableName(store.schema, typ) + " (id) on delete " + onDelete
+}
+
+type tableIndex struct {
+	name string
+	sql  string
+}
+
+func (store *PGStore) tableIndexes(descriptor *ResourceDescriptor) []tableIndex {
+	tableName := getTableNameWithoutSchema(store.schema, descriptor.Typ)
+	var indexes []tableIndex
+	newIndex := func(columns []string, gin bool) tableIndex {
+		name := IndexPrefix + tableName + "_" + strings.Join(columns, "_")
+		var buf bytes.Buffer
+		buf.WriteString("create index ")
+		buf.WriteString(" if not exists ")
+		buf.WriteString(name)
+		buf.WriteString(" on ")
+		buf.WriteString(getTableName(store.schema, descriptor.Typ))
+		if gin && store.driver != DriverOpenGauss { //GaussDB not support gin index
+			buf.WriteString(" using gin")
 		}
+		buf.WriteString(" (")
+		buf.WriteString(strings.Join(columns, ","))
+		buf.WriteString(")")
+		return tableIndex{name: name, sql: buf.String()}
 	}
 
-	if len(ginIndexes) > 0 {
-		for _, index := range ginIndexes {
-			idxBuf.WriteString("create index ")
-			idxBuf.WriteString(" if not exists ")
-			idxBuf.WriteString(IndexPrefix + tableName + "_" + index)
-			idxBuf.WriteString(" on ")
-			idxBuf.WriteString(getTableName(store.schema, descriptor.Typ))
-			if store.driver != DriverOpenGauss { //GaussDB not support gin index
-				idxBuf.WriteString(" using gin")
-			}
-			idxBuf.WriteString(" (")
-			idxBuf.WriteString(index)
-			idxBuf.WriteString(")")
+	if len(descriptor.Idxes) > 0 {
+		indexes = append(indexes, newIndex(descriptor.Idxes, false))
+	}
+
+	var ginIndexes []tableIndex
+	for _, field := range descriptor.Fields {
+		if field.Index == false {
+			continue
+		}
 
-			createIndexes = append(createIndexes, idxBuf.String())
-			idxBuf.Reset()
+		if field.Type == StringArray || field.Type == IPSlice || field.Type == IPNetSlice ||
+			field.Type == SmallIntArray || field.Type == BigIntArray || field.Type == SuperIntArray ||
+			field.Type == Float32Array {
+			ginIndexes = append(ginIndexes, newIndex([]string{field.Name}, true))
+		} else {
+			indexes = append(indexes, newIndex([]string{field.Name}, false))
 		}
 	}
 
-	return strings.TrimRight(buf.String(), ",") + ")", createIndexes
+	return append(indexes, ginIndexes...)
 }
 
 func (store *PGStore) Close() {
@@ -217,7 +213,7 @@ func (store *PGStore) Clean() {
 func (store *PGStore) Begin() (Transaction, error) {
 	tx, err := store.pool.Begin(context.TODO())
 	if err != nil {
-		return nil, err
+		return nil, classifyError(err)
 	} else {
 		return PGStoreTx{tx, NewBaseTx(store.meta, store.schema)}, nil
 	}
@@ -264,8 +260,10 @@ type PGStoreTx struct {
 	*BaseTx
 }
 
+// serialization failure of serializable transaction may be reported
+// when commit
 func (tx PGStoreTx) Commit() error {
-	return tx.Tx.Commit(context.TODO())
+	return classifyError(tx.Tx.Commit(context.TODO()))
 }
 
 func (tx PGStoreTx) Rollback() error {
@@ -274,6 +272,7 @@ func (tx PGStoreTx) Rollback() error {
 
 func (tx PGStoreTx) Insert(r resource.Resource) (resource.Resource, error) {
 	r.SetCreationTimestamp(time.Now())
+	r.SetResourceVersion(1)
 	sql, args, err := tx.insertSqlArgsAndID(r)
 	if err != nil {
 		return nil, err
@@ -282,7 +281,7 @@ func (tx PGStoreTx) Insert(r resource.Resource) (resource.Resource, error) {
 	logSql(sql, args)
 	_, err = tx.Tx.Exec(context.TODO(), sql, args...)
 	if err != nil {
-		return nil, err
+		return nil, classifyError(err)
 	} else {
 		return r, err
 	}
@@ -349,6 +348,87 @@ func (tx PGStoreTx) Fill(conds map[string]interface{}, out interface{}) error {
 	return tx.getWithSql(sql, args, out)
 }
 
+func (tx PGStoreTx) GetWithPagination(typ ResourceType, cond map[string]interface{}, pagination *resource.Pagination) (interface{}, error) {
+	goTyp, err := tx.meta.GetGoType(typ)
+	if err != nil {
+		return nil, err
+	}
+	sp := reflector.NewSlicePointer(reflect.PointerTo(goTyp))
+	err = tx.FillWithPagination(cond, sp, pagination)
+	if err != nil {
+		return nil, err
+	} else {
+		return reflect.ValueOf(sp).Elem().Interface(), nil
+	}
+}
+
+func (tx PGStoreTx) FillWithPagination(conds map[string]interface{}, out interface{}, pagination *resource.Pagination) error {
+	if pagination.IsKeyset() {
+		return tx.fillWithKeyset(conds, out, pagination)
+	} else if pagination.IsEnabled() == false {
+		return tx.Fill(conds, out)
+	}
+
+	r, err := reflector.GetStructPointerInSlice(out)
+	if err != nil {
+		return err
+	}
+
+	count, err := tx.Count(ResourceDBType(r.(resource.Resource)), conds)
+	if err != nil {
+		return err
+	}
+
+	pagination.SetTotal(int(count))
+	if count == 0 {
+		return nil
+	}
+
+	pageConds := cloneConds(conds)
+	pageConds[CondLimit] = pagination.PageSize
+	pageConds[CondOffset] = pagination.GetOffset()
+	return tx.Fill(pageConds, out)
+}
+
+// one more resource is fetched to check whether there is next page
+func (tx PGStoreTx) fillWithKeyset(conds map[string]interface{}, out interface{}, pagination *resource.Pagination) error {
+	pageConds := cloneConds(conds, CondOffset)
+	pageConds[CondContinue] = pagination.Continue
+	pageConds[CondLimit] = pagination.PageSize + 1
+	r, err := reflector.GetStructPointerInSlice(out)
+	if err != nil {
+		return err
+	}
+
+	orderColumns, err := parseOrderColumns(reflect.TypeOf(r).Elem(), conds[CondOrderBy])
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Fill(pageConds, out); err != nil {
+		return err
+	}
+
+	rs := reflect.ValueOf(out).Elem()
+	pagination.Next = ""
+	if rs.Len() <= pagination.PageSize {
+		return nil
+	}
+
+	rs.Set(rs.Slice(0, pagination.PageSize))
+	last, ok := rs.Index(pagination.PageSize - 1).Interface().(resource.Resource)
+	if ok == false {
+		return fmt.Errorf("%v isn't a resource", rs.Index(pagination.PageSize-1).Type())
+	}
+
+	next, err := genContinueToken(last, orderColumns)
+	if err != nil {
+		return err
+	}
+	pagination.Next = next
+	return nil
+}
+
 func (tx PGStoreTx) Exists(typ ResourceType, conds map[string]interface{}) (bool, error) {
 	sql, params, err := tx.existsSqlAndArgs(typ, conds)
 	if err != nil {
@@ -361,7 +441,7 @@ func (tx PGStoreTx) Exists(typ ResourceType, conds map[string]interface{}) (bool
 func (tx PGStoreTx) existsWithSql(sql string, params ...interface{}) (bool, error) {
 	rows, err := tx.Tx.Query(context.TODO(), sql, params...)
 	if err != nil {
-		return false, err
+		return false, classifyError(err)
 	}
 
 	var exist bool
@@ -371,7 +451,7 @@ func (tx PGStoreTx) existsWithSql(sql string, params ...interface{}) (bool, erro
 			return false, err
 		}
 	}
-	return exist, nil
+	return exist, classifyError(rows.Err())
 }
 
 func (tx PGStoreTx) Count(typ ResourceType, conds map[string]interface{}) (int64, error) {
@@ -393,7 +473,7 @@ func (tx PGStoreTx) CountEx(typ ResourceType, sql string, params ...interface{})
 func (tx PGStoreTx) countWithSql(sql string, params ...interface{}) (int64, error) {
 	rows, err := tx.Tx.Query(context.TODO(), sql, params...)
 	if err != nil {
-		return 0, err
+		return 0, classifyError(err)
 	}
 
 	var count int64
@@ -404,16 +484,32 @@ func (tx PGStoreTx) countWithSql(sql string, params ...interface{}) (int64, erro
 		}
 	}
 
-	return count, nil
+	return count, classifyError(rows.Err())
 }
 
+// if resource_version is in conds and no row is updated because the
+// version is stale, ErrResourceVersionConflict is returned
 func (tx PGStoreTx) Update(typ ResourceType, nv map[string]interface{}, conds map[string]interface{}) (int64, error) {
 	sql, args, err := tx.updateSqlAndArgs(typ, nv, conds)
 	if err != nil {
 		return 0, err
 	}
 
-	return tx.Exec(sql, args...)
+	rows, err := tx.Exec(sql, args...)
+	if err != nil || rows != 0 {
+		return rows, err
+	}
+
+	if _, ok := conds[ResourceVersionField]; ok == false {
+		return 0, nil
+	}
+
+	if exists, err := tx.Exists(typ, cloneConds(conds, ResourceVersionField)); err != nil {
+		return 0, err
+	} else if exists {
+		return 0, ErrResourceVersionConflict
+	}
+	return 0, nil
 }
 
 func (tx PGStoreTx) Delete(typ ResourceType, cond map[string]interface{}) (int64, error) {
@@ -447,7 +543,7 @@ func (tx PGStoreTx) Exec(sql string, params ...interface{}) (int64, error) {
 	logSql(sql, params...)
 	result, err := tx.Tx.Exec(context.TODO(), sql, params...)
 	if err != nil {
-		return 0, err
+		return 0, classifyError(err)
 	} else {
 		return result.RowsAffected(), nil
 	}
@@ -466,7 +562,7 @@ func (tx PGStoreTx) CopyFromEx(typ ResourceType, columns []string, values [][]in
 		pgx.Identifier{tx.schema, getTableNameWithoutSchema(tx.schema, descriptor.Typ)},
 		columns,
 		pgx.CopyFromRows(values))
-	return c, err
+	return c, classifyError(err)
 }
 
 func (tx PGStoreTx) CopyFrom(typ ResourceType, values [][]interface{}) (int64, error) {
@@ -487,17 +583,20 @@ func (tx PGStoreTx) CopyFrom(typ ResourceType, values [][]interface{}) (int64, e
 		pgx.Identifier{tx.schema, getTableNameWithoutSchema(tx.schema, descriptor.Typ)},
 		columns,
 		pgx.CopyFromRows(values))
-	return c, err
+	return c, classifyError(err)
 }
 
 func (tx PGStoreTx) getWithSql(sql string, args []interface{}, out interface{}) error {
 	logSql(sql, args)
 	rows, err := tx.Tx.Query(context.TODO(), sql, args...)
 	if err != nil {
-		return err
+		return classifyError(err)
 	}
 
-	return tx.rowsToResources(rows, out)
+	if err := tx.rowsToResources(rows, out); err != nil {
+		return err
+	}
+	return classifyError(rows.Err())
 }
 
 func (tx PGStoreTx) rowsToResources(rows pgx.Rows, out interface{}) error {
@@ -365,6 +365,40 @@ func TestPGGetWithLimitAndOffset(t *testing.T) {
 	store.Close()
 }
 
+func TestPGFillWithPagination(t *testing.T) {
+	meta, err := NewResourceMeta([]resource.Resource{&Mother{}})
+	ut.Assert(t, err == nil, "")
+	store, err := setup(meta)
+	ut.Assert(t, err == nil, "")
+
+	tx, _ := store.Begin()
+	for i := 0; i < 55; i++ {
+		_, err := tx.Insert(&Mother{Age: i, Name: "m" + strconv.Itoa(i)})
+		ut.Assert(t, err == nil, "")
+	}
+	tx.Commit()
+
+	tx, _ = store.Begin()
+	var mothers []*Mother
+	pagination := &resource.Pagination{PageSize: 10, PageNum: 6}
+	err = tx.FillWithPagination(map[string]interface{}{"orderby": "age"}, &mothers, pagination)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, len(mothers), 5)
+	ut.Equal(t, mothers[0].Age, 50)
+	ut.Equal(t, *pagination, resource.Pagination{PageTotal: 6, PageNum: 6, PageSize: 10, Total: 55})
+
+	pagination = &resource.Pagination{PageSize: 10, PageNum: 2}
+	ms, err := tx.GetWithPagination("mother", map[string]interface{}{"age": FillValue{Operator: OperatorGte, Value: 30}}, pagination)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, len(ms.([]*Mother)), 10)
+	ut.Equal(t, pagination.Total, 25)
+	ut.Equal(t, pagination.PageTotal, 3)
+	tx.Commit()
+
+	store.Clean()
+	store.Close()
+}
+
 type Student struct {
 	resource.ResourceBase
 	Name      string `db:"uk"`
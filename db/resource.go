@@ -1,6 +1,7 @@
 package db
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/linkingthing/cement/reflector"
@@ -9,10 +10,13 @@ import (
 )
 
 const (
-	IDField         = "id"
-	CreateTimeField = "create_time"
+	IDField              = "id"
+	CreateTimeField      = "create_time"
+	ResourceVersionField = "resource_version"
 )
 
+var ErrResourceVersionConflict = errors.New("resource version conflict")
+
 type ResourceType string
 
 func ResourceDBType(r resource.Resource) ResourceType {
@@ -43,7 +47,7 @@ func ResourceToMap(r resource.Resource) (map[string]interface{}, error) {
 		}
 
 		n = stringtool.ToSnake(n)
-		if n == IDField || n == CreateTimeField {
+		if n == IDField || n == CreateTimeField || n == ResourceVersionField {
 			continue
 		}
 		m[n] = v.Field(i).Interface()
@@ -255,8 +255,8 @@ func genDescriptor(r resource.Resource) (*ResourceDescriptor, error) {
 		}
 
 		fieldName := stringtool.ToSnake(field.Name)
-		if fieldName == IDField || fieldName == CreateTimeField {
-			return nil, fmt.Errorf("id or createTime field has exists in resource base")
+		if fieldName == IDField || fieldName == CreateTimeField || fieldName == ResourceVersionField {
+			return nil, fmt.Errorf("id, createTime or resourceVersion field has exists in resource base")
 		}
 
 		fieldTag := field.Tag.Get(DBTag)
@@ -279,8 +279,8 @@ func genDescriptor(r resource.Resource) (*ResourceDescriptor, error) {
 				embedField := embedType.Field(j)
 				embedFieldTag := embedField.Tag.Get(DBTag)
 				embedFieldName := stringtool.ToSnake(embedField.Name)
-				if embedFieldName == IDField || embedFieldName == CreateTimeField {
-					return nil, fmt.Errorf("id or createTime field has exists in resource base")
+				if embedFieldName == IDField || embedFieldName == CreateTimeField || embedFieldName == ResourceVersionField {
+					return nil, fmt.Errorf("id, createTime or resourceVersion field has exists in resource base")
 				}
 
 				if tagContains(embedFieldTag, "-") {
@@ -28,6 +28,11 @@ type Transaction interface {
 	Count(typ ResourceType, cond map[string]interface{}) (int64, error)
 	// Fill out should be an slice of Resource which is a pointer to struct
 	Fill(cond map[string]interface{}, out interface{}) error
+	// GetWithPagination same with Get, but only return the resources in the page
+	//specified by pagination, and pagination is filled with the total count
+	GetWithPagination(typ ResourceType, cond map[string]interface{}, pagination *resource.Pagination) (interface{}, error)
+	// FillWithPagination similar with GetWithPagination
+	FillWithPagination(cond map[string]interface{}, out interface{}, pagination *resource.Pagination) error
 	Delete(typ ResourceType, cond map[string]interface{}) (int64, error)
 	Update(typ ResourceType, nv map[string]interface{}, cond map[string]interface{}) (int64, error)
 	// FillOwned Similar with GetOwned
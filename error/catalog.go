@@ -0,0 +1,127 @@
+package error
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	LangEN = "en"
+	LangZH = "zh"
+	LangJA = "ja"
+	LangRU = "ru"
+)
+
+// Params are values of the placeholders in message template, like {field}
+type Params map[string]interface{}
+
+// Catalog stores message templates of each language, language is a tag
+// like zh or zh-CN, key is the error code or error code with a suffix
+type Catalog interface {
+	Lookup(lang, key string) (string, bool)
+	Languages() []string
+}
+
+type MessageCatalog struct {
+	lock     sync.RWMutex
+	messages map[string]map[string]string
+}
+
+var _ Catalog = &MessageCatalog{}
+
+func NewMessageCatalog() *MessageCatalog {
+	return &MessageCatalog{messages: make(map[string]map[string]string)}
+}
+
+// Add merge the templates to the language, template with same key is
+// overwritten
+func (c *MessageCatalog) Add(lang string, templates map[string]string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	lang = strings.ToLower(lang)
+	messages, ok := c.messages[lang]
+	if ok == false {
+		messages = make(map[string]string, len(templates))
+		c.messages[lang] = messages
+	}
+	for key, template := range templates {
+		messages[key] = template
+	}
+}
+
+// lookup the language tag first, then its base language, zh-CN falls
+// back to zh
+func (c *MessageCatalog) Lookup(lang, key string) (string, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	lang = strings.ToLower(lang)
+	if template, ok := c.messages[lang][key]; ok {
+		return template, true
+	}
+	template, ok := c.messages[BaseLanguage(lang)][key]
+	return template, ok
+}
+
+func (c *MessageCatalog) Languages() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	langs := make([]string, 0, len(c.messages))
+	for lang := range c.messages {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+var (
+	defaultCatalog         = newBuiltinCatalog()
+	catalog        Catalog = defaultCatalog
+)
+
+// DefaultCatalog return the builtin catalog, add templates to it to
+// support more languages or overwrite the builtin messages
+func DefaultCatalog() *MessageCatalog {
+	return defaultCatalog
+}
+
+// SetCatalog replace the catalog used by all the errors, it should be
+// called before server starts
+func SetCatalog(c Catalog) {
+	catalog = c
+}
+
+func GetCatalog() Catalog {
+	return catalog
+}
+
+func newBuiltinCatalog() *MessageCatalog {
+	c := NewMessageCatalog()
+	for lang, templates := range builtinMessages {
+		c.Add(lang, templates)
+	}
+	return c
+}
+
+// BaseLanguage return the primary language subtag, zh-CN returns zh
+func BaseLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i != -1 {
+		return lang[:i]
+	}
+	return lang
+}
+
+// Render replace the placeholders in template with the params
+func Render(template string, params Params) string {
+	if len(params) == 0 {
+		return template
+	}
+
+	oldnew := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		oldnew = append(oldnew, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(oldnew...).Replace(template)
+}
@@ -0,0 +1,95 @@
+package error
+
+import (
+	"testing"
+
+	ut "github.com/linkingthing/cement/unittest"
+)
+
+func TestRender(t *testing.T) {
+	ut.Equal(t, Render("{type} resource with id {id} doesn't exist", Params{"type": "cluster", "id": "c1"}),
+		"cluster resource with id c1 doesn't exist")
+	ut.Equal(t, Render("field {field} should < {limit}", Params{"field": "size", "limit": 100}),
+		"field size should < 100")
+	ut.Equal(t, Render("watch isn't supported", nil), "watch isn't supported")
+}
+
+func TestCatalogLookup(t *testing.T) {
+	c := NewMessageCatalog()
+	c.Add("zh", map[string]string{"hello": "你好"})
+	c.Add("zh-TW", map[string]string{"hello": "妳好"})
+	c.Add("ZH", map[string]string{"bye": "再见"})
+
+	template, ok := c.Lookup("zh-TW", "hello")
+	ut.Assert(t, ok, "")
+	ut.Equal(t, template, "妳好")
+	template, ok = c.Lookup("zh-CN", "hello")
+	ut.Assert(t, ok, "")
+	ut.Equal(t, template, "你好")
+	template, ok = c.Lookup("zh-TW", "bye")
+	ut.Assert(t, ok, "")
+	ut.Equal(t, template, "再见")
+	_, ok = c.Lookup("ja", "hello")
+	ut.Assert(t, ok == false, "")
+	ut.Equal(t, c.Languages(), []string{"zh", "zh-tw"})
+}
+
+func TestLocalization(t *testing.T) {
+	newErr := func() *APIError {
+		return NewAPIError(NotFound, *NewLocalizedErrorMessage("", MessageResourceNotFound,
+			Params{"type": "cluster", "id": "c1"}))
+	}
+
+	err := newErr()
+	ut.Equal(t, err.Message, "cluster resource with id c1 doesn't exist")
+	ut.Equal(t, err.MessageCN, "id为c1的cluster资源不存在")
+	ut.Equal(t, err.Localization("ja").Message, "IDがc1のclusterリソースは存在しません")
+	ut.Equal(t, err.Localization("ru-RU").Message, "ресурс cluster с id c1 не существует")
+	ut.Equal(t, err.Localization("zh").Message, "id为c1的cluster资源不存在")
+	ut.Equal(t, err.Localization("fr").Message, "cluster resource with id c1 doesn't exist")
+	ut.Equal(t, err.Localization("").Message, "cluster resource with id c1 doesn't exist")
+
+	legacy := NewAPIError(Conflict, *NewErrorMessage("conflict", "冲突"))
+	ut.Equal(t, legacy.Localization("zh-CN").Message, "冲突")
+	ut.Equal(t, legacy.Localization("ja").Message, "conflict")
+
+	DefaultCatalog().Add("de", map[string]string{MessageResourceNotFound: "{type} Ressource mit ID {id} existiert nicht"})
+	ut.Equal(t, newErr().Localization("de").Message, "cluster Ressource mit ID c1 existiert nicht")
+
+	custom := NewMessageCatalog()
+	custom.Add(LangEN, map[string]string{MessageResourceNotFound: "no {type} {id}"})
+	custom.Add(LangZH, map[string]string{MessageResourceNotFound: "没有{type} {id}"})
+	SetCatalog(custom)
+	defer SetCatalog(DefaultCatalog())
+	err = newErr()
+	ut.Equal(t, err.Message, "no cluster c1")
+	ut.Equal(t, err.MessageCN, "没有cluster c1")
+	ut.Equal(t, err.Localization("ja").Message, "no cluster c1")
+}
+
+func TestDetailsLocalization(t *testing.T) {
+	detail := func(field string, limit int) ErrorDetail {
+		return ErrorDetail{
+			Code:  MinLengthExceeded.Code,
+			Field: field,
+			ErrorMessage: *NewLocalizedErrorMessage("", MessageMinLengthExceeded,
+				Params{"field": field, "limit": limit}),
+		}
+	}
+
+	err := NewDetailsAPIError(MinLengthExceeded, detail("name", 2), detail("comment", 5))
+	ut.Equal(t, err.Message, "length of field name should >= 2; length of field comment should >= 5")
+	ut.Equal(t, err.Localization("zh").Message, "字段[name]的长度不能小于2; 字段[comment]的长度不能小于5")
+	ut.Equal(t, err.Localization("ja").Details[1].Message, "フィールド[comment]の長さは5以上でなければなりません")
+	ut.Equal(t, err.Localization("en").Message, "length of field name should >= 2; length of field comment should >= 5")
+}
+
+func TestBuiltinMessagesComplete(t *testing.T) {
+	for lang, messages := range builtinMessages {
+		ut.Equal(t, len(messages), len(builtinMessages[LangEN]))
+		for key := range builtinMessages[LangEN] {
+			_, ok := messages[key]
+			ut.Assert(t, ok, "message %s of %s is missing", key, lang)
+		}
+	}
+}
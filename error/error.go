@@ -1,5 +1,7 @@
 package error
 
+import "strings"
+
 var (
 	Unauthorized     = ErrorCode{"Unauthorized", 401}
 	PermissionDenied = ErrorCode{"PermissionDenied", 403}
@@ -7,6 +9,9 @@ var (
 	MethodNotAllowed = ErrorCode{"MethodNotAllow", 405}
 	Conflict         = ErrorCode{"Conflict", 409}
 
+	RequestEntityTooLarge = ErrorCode{"RequestEntityTooLarge", 413}
+	UnsupportedMediaType  = ErrorCode{"UnsupportedMediaType", 415}
+
 	DuplicateResource  = ErrorCode{"DuplicateResource", 422}
 	DeleteParent       = ErrorCode{"DeleteParent", 422}
 	InvalidFormat      = ErrorCode{"InvalidFormat", 422}
@@ -31,6 +36,10 @@ var (
 const (
 	ErrorCHNameInvalidQuery  = "查询参数[%s]不合法"
 	ErrorCHNameInvalidFormat = "请求格式不合法 "
+
+	ErrorCHNameResourceVersionConflict = "资源[%s]已被修改, 请刷新后重试"
+
+	DetailsSeparator = "; "
 )
 
 type ErrorCode struct {
@@ -38,34 +47,95 @@ type ErrorCode struct {
 	Status int    `json:"status,omitempty"`
 }
 
+// Key and Params are used to translate the message to the languages
+// other than english and chinese
 type ErrorMessage struct {
 	MessageEN string `json:"messageEN"`
 	MessageCN string `json:"messageCN"`
+	Key       string `json:"-"`
+	Params    Params `json:"-"`
 }
 
 type APIError struct {
-	ErrorCode `json:",inline"`
-	Type      string `json:"type,omitempty"`
-	Message   string `json:"message,omitempty"`
-	MessageCN string `json:"-"`
+	ErrorCode     `json:",inline"`
+	Type          string        `json:"type,omitempty"`
+	Message       string        `json:"message,omitempty"`
+	MessageCN     string        `json:"-"`
+	MessageKey    string        `json:"-"`
+	MessageParams Params        `json:"-"`
+	RequestID     string        `json:"requestId,omitempty"`
+	Details       []ErrorDetail `json:"details,omitempty"`
+
+	messageEN     string
+	joinedDetails bool
+}
+
+// ErrorDetail is the failure of one field in the request, field is the
+// json path like infos[1].size, rule and limit come from the rest tag
+type ErrorDetail struct {
+	Code         string      `json:"code"`
+	Field        string      `json:"field"`
+	Rule         string      `json:"rule,omitempty"`
+	Limit        interface{} `json:"limit,omitempty"`
+	Message      string      `json:"message,omitempty"`
+	ErrorMessage `json:",inline"`
 }
 
 func NewAPIError(code ErrorCode, message ErrorMessage) *APIError {
 	return &APIError{
-		ErrorCode: code,
-		Type:      "error",
-		Message:   message.MessageEN,
-		MessageCN: message.MessageCN,
+		ErrorCode:     code,
+		Type:          "error",
+		Message:       message.MessageEN,
+		MessageCN:     message.MessageCN,
+		MessageKey:    message.Key,
+		MessageParams: message.Params,
+		messageEN:     message.MessageEN,
+	}
+}
+
+// NewDetailsAPIError create error whose message is the messages of the
+// details joined by "; ", the message is localized with the details
+func NewDetailsAPIError(code ErrorCode, details ...ErrorDetail) *APIError {
+	var msgs, msgsCN []string
+	for _, detail := range details {
+		msgs = append(msgs, detail.MessageEN)
+		msgsCN = append(msgsCN, detail.MessageCN)
+	}
+	e := NewAPIError(code, *NewErrorMessage(strings.Join(msgs, DetailsSeparator),
+		strings.Join(msgsCN, DetailsSeparator))).AddDetails(details...)
+	e.joinedDetails = true
+	return e
+}
+
+func (e *APIError) AddDetails(details ...ErrorDetail) *APIError {
+	for _, detail := range details {
+		if detail.Message == "" {
+			detail.Message = detail.MessageEN
+		}
+		e.Details = append(e.Details, detail)
 	}
+	return e
 }
 
 func (e *APIError) Error() string {
 	return e.Message
 }
 
-func (e *APIError) Localization(localize bool) *APIError {
-	if localize {
-		e.Message = e.MessageCN
+// Localization translate message of the error and its details to the
+// language, lang is a tag like zh-CN, empty tag means english
+func (e *APIError) Localization(lang string) *APIError {
+	if e.messageEN == "" {
+		e.messageEN = e.Message
+	}
+	message := ErrorMessage{MessageEN: e.messageEN, MessageCN: e.MessageCN, Key: e.MessageKey, Params: e.MessageParams}
+	e.Message = message.Localize(lang)
+	msgs := make([]string, 0, len(e.Details))
+	for i := range e.Details {
+		e.Details[i].Message = e.Details[i].Localize(lang)
+		msgs = append(msgs, e.Details[i].Message)
+	}
+	if e.joinedDetails {
+		e.Message = strings.Join(msgs, DetailsSeparator)
 	}
 	return e
 }
@@ -74,6 +144,48 @@ func NewErrorMessage(msgEn, msgCn string) *ErrorMessage {
 	return &ErrorMessage{MessageEN: msgEn, MessageCN: msgCn}
 }
 
+// NewLocalizedErrorMessage create message with the template in catalog,
+// english message is rendered from the template if msgEn is empty
+func NewLocalizedErrorMessage(msgEn, key string, params Params) *ErrorMessage {
+	if msgEn == "" {
+		msgEn = renderMessage(LangEN, key, params)
+	}
+	return &ErrorMessage{
+		MessageEN: msgEn,
+		MessageCN: renderMessage(LangZH, key, params),
+		Key:       key,
+		Params:    params,
+	}
+}
+
+func renderMessage(lang, key string, params Params) string {
+	if template, ok := catalog.Lookup(lang, key); ok {
+		return Render(template, params)
+	}
+	return ""
+}
+
+// Localize return the message in the language, english message is kept
+// for english, then template in catalog is used, chinese message is the
+// fallback of zh, and english message is the fallback of all
+func (m *ErrorMessage) Localize(lang string) string {
+	base := BaseLanguage(lang)
+	if base == "" || base == LangEN {
+		return m.MessageEN
+	}
+
+	if m.Key != "" {
+		if template, ok := catalog.Lookup(lang, m.Key); ok {
+			return Render(template, m.Params)
+		}
+	}
+
+	if base == LangZH && m.MessageCN != "" {
+		return m.MessageCN
+	}
+	return m.MessageEN
+}
+
 func (m *ErrorMessage) Error() string {
 	return m.MessageEN
 }
@@ -0,0 +1,327 @@
+package error
+
+// keys of the builtin messages, placeholders of each message are listed
+// in the comments
+const (
+	MessageNoHandler               = "NoHandler"               //method
+	MessageResourceNotFound        = "ResourceNotFound"        //type, id
+	MessageGenerateLinksFailed     = "GenerateLinksFailed"     //reason
+	MessageMarshalFailed           = "MarshalFailed"           //reason
+	MessageInternalServerError     = "InternalServerError"     //requestId
+	MessageResourceVersionConflict = "ResourceVersionConflict" //type, id, version
+	MessageWatchNotSupported       = "WatchNotSupported"
+	MessageUnknownAction           = "UnknownAction"        //action
+	MessageInvalidResourceID       = "InvalidResourceID"    //type
+	MessageNotChild                = "NotChild"             //child, parent
+	MessageInvalidQuery            = "InvalidQuery"         //name
+	MessageReadBodyFailed          = "ReadBodyFailed"       //reason
+	MessageBodyTooLarge            = "BodyTooLarge"         //limit
+	MessageUnsupportedMediaType    = "UnsupportedMediaType" //contentType, supported
+	MessageInvalidJSON             = "InvalidJSON"          //reason
+	MessageUnknownField            = "UnknownField"         //field
+	MessageInvalidBody             = "InvalidBody"          //reason
+	MessageInvalidBodyField        = "InvalidBodyField"     //field, reason
+	MessageMissingRequired         = "MissingRequired"      //field
+	MessageInvalidOption           = "InvalidOption"        //field, limit
+	MessageMinLimitExceeded        = "MinLimitExceeded"     //field, limit
+	MessageMaxLimitExceeded        = "MaxLimitExceeded"     //field, limit
+	MessageMinLengthExceeded       = "MinLengthExceeded"    //field, limit
+	MessageMaxLengthExceeded       = "MaxLengthExceeded"    //field, limit
+	MessageInvalidDomain           = "InvalidDomain"        //field
+	MessagePatternMismatch         = "PatternMismatch"      //field, limit
+	MessageInvalidIPv4             = "InvalidIPv4"          //field
+	MessageInvalidIPv6             = "InvalidIPv6"          //field
+	MessageInvalidCIDR             = "InvalidCIDR"          //field
+	MessageInvalidMAC              = "InvalidMAC"           //field
+	MessageInvalidEmail            = "InvalidEmail"         //field
+	MessageInvalidURL              = "InvalidURL"           //field
+	MessageInvalidUUID             = "InvalidUUID"          //field
+	MessageValidateFailed          = "ValidateFailed"       //field, limit
+	MessageTimeNotAfter            = "TimeNotAfter"         //field, limit
+	MessageTimeNotBefore           = "TimeNotBefore"        //field, limit
+	MessageInvalidField            = "InvalidField"         //field
+	MessageReadOnlyField           = "ReadOnlyField"        //field
+	MessageImmutableField          = "ImmutableField"       //field
+	MessageDuplicateResource       = "DuplicateResource"    //type, field
+	MessageResourceReferenced      = "ResourceReferenced"   //type, referrer
+	MessageReferenceNotFound       = "ReferenceNotFound"    //type, field
+	MessageNotNullable             = "NotNullable"          //type, field
+	MessageCheckViolation          = "CheckViolation"       //type, constraint
+	MessageConcurrentUpdate        = "ConcurrentUpdate"     //type
+	MessageDatabaseUnavailable     = "DatabaseUnavailable"
+	MessageNoPatch                 = "NoPatch"
+	MessagePatchCollection         = "PatchCollection"        //type
+	MessageInvalidPatch            = "InvalidPatch"           //reason
+	MessageInvalidPatchedResource  = "InvalidPatchedResource" //reason
+	MessageInvalidActionInput      = "InvalidActionInput"     //action, reason
+	MessageNoResourceInURL         = "NoResourceInURL"        //url
+	MessageUnknownResourceKind     = "UnknownResourceKind"    //kind
+	MessageUnknownAPIVersion       = "UnknownAPIVersion"      //url
+	MessageGenerateDocumentFailed  = "GenerateDocumentFailed" //kind, reason
+	MessageConvertFailed           = "ConvertFailed"          //type, reason
+	MessageInvalidListResult       = "InvalidListResult"      //reason
+	MessageStreamingNotSupported   = "StreamingNotSupported"
+	MessageUnsupportedMethod       = "UnsupportedMethod"  //method
+	MessageInvalidOwnerField       = "InvalidOwnerField"  //type, field
+	MessageStoreFailed             = "StoreFailed"        //reason
+	MessageCompareFieldFailed      = "CompareFieldFailed" //field, reason
+)
+
+var builtinMessages = map[string]map[string]string{
+	LangEN: {
+		MessageNoHandler:               "no handler for {method}",
+		MessageResourceNotFound:        "{type} resource with id {id} doesn't exist",
+		MessageGenerateLinksFailed:     "generate links failed:{reason}",
+		MessageMarshalFailed:           "marshal failed:{reason}",
+		MessageInternalServerError:     "internal server error, request id is {requestId}",
+		MessageResourceVersionConflict: "{type} resource with id {id} has been modified, current resource version is {version}",
+		MessageWatchNotSupported:       "watch isn't supported",
+		MessageUnknownAction:           "unknown action {action}",
+		MessageInvalidResourceID:       "invalid resource {type} id",
+		MessageNotChild:                "{child} is not a child of {parent}",
+		MessageInvalidQuery:            "query parameter {name} is invalid",
+		MessageReadBodyFailed:          "failed to read request body: {reason}",
+		MessageBodyTooLarge:            "request body exceeds the limit of {limit} bytes",
+		MessageUnsupportedMediaType:    "unsupported content type {contentType}, supported content types are {supported}",
+		MessageInvalidJSON:             "request body isn't valid json:{reason}",
+		MessageUnknownField:            "unknown field {field} in request body",
+		MessageInvalidBody:             "request body is invalid: {reason}",
+		MessageInvalidBodyField:        "field {field} in request body is invalid: {reason}",
+		MessageMissingRequired:         "field {field} is missing",
+		MessageInvalidOption:           "field {field} should be one of {limit}",
+		MessageMinLimitExceeded:        "field {field} should >= {limit}",
+		MessageMaxLimitExceeded:        "field {field} should < {limit}",
+		MessageMinLengthExceeded:       "length of field {field} should >= {limit}",
+		MessageMaxLengthExceeded:       "length of field {field} should < {limit}",
+		MessageInvalidDomain:           "field {field} isn't a valid domain name",
+		MessagePatternMismatch:         "field {field} doesn't match pattern {limit}",
+		MessageInvalidIPv4:             "field {field} isn't a valid ipv4 address",
+		MessageInvalidIPv6:             "field {field} isn't a valid ipv6 address",
+		MessageInvalidCIDR:             "field {field} isn't a valid cidr",
+		MessageInvalidMAC:              "field {field} isn't a valid mac address",
+		MessageInvalidEmail:            "field {field} isn't a valid email address",
+		MessageInvalidURL:              "field {field} isn't a valid url",
+		MessageInvalidUUID:             "field {field} isn't a valid uuid",
+		MessageValidateFailed:          "field {field} failed validator {limit}",
+		MessageTimeNotAfter:            "field {field} should be after {limit}",
+		MessageTimeNotBefore:           "field {field} should be before {limit}",
+		MessageInvalidField:            "field {field} is invalid",
+		MessageReadOnlyField:           "field {field} is read only",
+		MessageImmutableField:          "field {field} can't be modified after creation",
+		MessageDuplicateResource:       "{type} resource with same {field} already exists",
+		MessageResourceReferenced:      "{type} resource is referenced by {referrer} resources",
+		MessageReferenceNotFound:       "resource referenced by {field} of {type} doesn't exist",
+		MessageNotNullable:             "{field} of {type} can't be null",
+		MessageCheckViolation:          "{type} resource violates check constraint {constraint}",
+		MessageConcurrentUpdate:        "{type} resource is modified concurrently, please retry",
+		MessageDatabaseUnavailable:     "database is unavailable, please retry later",
+		MessageNoPatch:                 "no patch document in request",
+		MessagePatchCollection:         "patch {type} collection isn't supported",
+		MessageInvalidPatch:            "patch document is invalid: {reason}",
+		MessageInvalidPatchedResource:  "patched resource is invalid: {reason}",
+		MessageInvalidActionInput:      "input of action {action} is invalid: {reason}",
+		MessageNoResourceInURL:         "no resource in url {url}",
+		MessageUnknownResourceKind:     "no resource with kind {kind}",
+		MessageUnknownAPIVersion:       "{url} has unknown api version",
+		MessageGenerateDocumentFailed:  "generate document of {kind} failed: {reason}",
+		MessageConvertFailed:           "convert {type} failed: {reason}",
+		MessageInvalidListResult:       "list handler returns invalid result: {reason}",
+		MessageStreamingNotSupported:   "response doesn't support streaming",
+		MessageUnsupportedMethod:       "method {method} isn't supported",
+		MessageInvalidOwnerField:       "owner field {field} of {type} isn't a string field",
+		MessageStoreFailed:             "database operation failed: {reason}",
+		MessageCompareFieldFailed:      "compare field {field} failed: {reason}",
+	},
+	LangZH: {
+		MessageNoHandler:               "不支持{method}操作",
+		MessageResourceNotFound:        "id为{id}的{type}资源不存在",
+		MessageGenerateLinksFailed:     "生成链接失败:{reason}",
+		MessageMarshalFailed:           "序列化失败:{reason}",
+		MessageInternalServerError:     "服务器内部错误, 请求ID为{requestId}",
+		MessageResourceVersionConflict: "资源[{id}]已被修改, 请刷新后重试",
+		MessageWatchNotSupported:       "不支持watch",
+		MessageUnknownAction:           "未知的操作[{action}]",
+		MessageInvalidResourceID:       "{type}资源的id不合法",
+		MessageNotChild:                "{child}不是{parent}的子资源",
+		MessageInvalidQuery:            "查询参数[{name}]不合法",
+		MessageReadBodyFailed:          "读取请求体失败: {reason}",
+		MessageBodyTooLarge:            "请求体大小超过{limit}字节的限制",
+		MessageUnsupportedMediaType:    "不支持的请求体类型[{contentType}], 支持的类型为{supported}",
+		MessageInvalidJSON:             "请求体不是合法的json:{reason}",
+		MessageUnknownField:            "请求体包含未知字段[{field}]",
+		MessageInvalidBody:             "请求体不合法: {reason}",
+		MessageInvalidBodyField:        "请求体字段[{field}]类型不合法: {reason}",
+		MessageMissingRequired:         "缺少必填字段[{field}]",
+		MessageInvalidOption:           "字段[{field}]的值必须是{limit}中的一个",
+		MessageMinLimitExceeded:        "字段[{field}]的值不能小于{limit}",
+		MessageMaxLimitExceeded:        "字段[{field}]的值必须小于{limit}",
+		MessageMinLengthExceeded:       "字段[{field}]的长度不能小于{limit}",
+		MessageMaxLengthExceeded:       "字段[{field}]的长度必须小于{limit}",
+		MessageInvalidDomain:           "字段[{field}]不是合法的域名",
+		MessagePatternMismatch:         "字段[{field}]的值不匹配正则表达式{limit}",
+		MessageInvalidIPv4:             "字段[{field}]不是合法的IPv4地址",
+		MessageInvalidIPv6:             "字段[{field}]不是合法的IPv6地址",
+		MessageInvalidCIDR:             "字段[{field}]不是合法的CIDR",
+		MessageInvalidMAC:              "字段[{field}]不是合法的MAC地址",
+		MessageInvalidEmail:            "字段[{field}]不是合法的邮箱地址",
+		MessageInvalidURL:              "字段[{field}]不是合法的URL",
+		MessageInvalidUUID:             "字段[{field}]不是合法的UUID",
+		MessageValidateFailed:          "字段[{field}]未通过{limit}检查",
+		MessageTimeNotAfter:            "字段[{field}]的时间必须晚于{limit}",
+		MessageTimeNotBefore:           "字段[{field}]的时间必须早于{limit}",
+		MessageInvalidField:            "字段[{field}]的值不合法",
+		MessageReadOnlyField:           "字段[{field}]是只读字段",
+		MessageImmutableField:          "字段[{field}]创建后不能修改",
+		MessageDuplicateResource:       "{field}相同的{type}资源已存在",
+		MessageResourceReferenced:      "{type}资源被{referrer}资源引用",
+		MessageReferenceNotFound:       "{type}资源的{field}引用的资源不存在",
+		MessageNotNullable:             "{type}资源的{field}不能为空",
+		MessageCheckViolation:          "{type}资源违反检查约束[{constraint}]",
+		MessageConcurrentUpdate:        "{type}资源正在被并发修改, 请重试",
+		MessageDatabaseUnavailable:     "数据库不可用, 请稍后重试",
+		MessageNoPatch:                 "请求中没有patch文档",
+		MessagePatchCollection:         "不支持patch {type}资源集合",
+		MessageInvalidPatch:            "patch文档不合法: {reason}",
+		MessageInvalidPatchedResource:  "patch后的资源不合法: {reason}",
+		MessageInvalidActionInput:      "操作[{action}]的参数不合法: {reason}",
+		MessageNoResourceInURL:         "url {url} 中没有资源",
+		MessageUnknownResourceKind:     "不存在类型为{kind}的资源",
+		MessageUnknownAPIVersion:       "{url}的api版本未知",
+		MessageGenerateDocumentFailed:  "生成{kind}的文档失败: {reason}",
+		MessageConvertFailed:           "转换{type}资源失败: {reason}",
+		MessageInvalidListResult:       "list返回的结果不合法: {reason}",
+		MessageStreamingNotSupported:   "响应不支持流式传输",
+		MessageUnsupportedMethod:       "不支持{method}方法",
+		MessageInvalidOwnerField:       "{type}资源的父资源字段[{field}]不是字符串",
+		MessageStoreFailed:             "数据库操作失败: {reason}",
+		MessageCompareFieldFailed:      "比较字段[{field}]失败: {reason}",
+	},
+	LangJA: {
+		MessageNoHandler:               "{method}はサポートされていません",
+		MessageResourceNotFound:        "IDが{id}の{type}リソースは存在しません",
+		MessageGenerateLinksFailed:     "リンクの生成に失敗しました:{reason}",
+		MessageMarshalFailed:           "シリアライズに失敗しました:{reason}",
+		MessageInternalServerError:     "サーバー内部エラー, リクエストIDは{requestId}です",
+		MessageResourceVersionConflict: "リソース[{id}]は変更されました, 更新してから再試行してください",
+		MessageWatchNotSupported:       "watchはサポートされていません",
+		MessageUnknownAction:           "不明なアクション[{action}]",
+		MessageInvalidResourceID:       "{type}リソースのIDが不正です",
+		MessageNotChild:                "{child}は{parent}の子リソースではありません",
+		MessageInvalidQuery:            "クエリパラメータ[{name}]が不正です",
+		MessageReadBodyFailed:          "リクエストボディの読み込みに失敗しました: {reason}",
+		MessageBodyTooLarge:            "リクエストボディが{limit}バイトの上限を超えています",
+		MessageUnsupportedMediaType:    "サポートされていないコンテンツタイプ[{contentType}], サポートされているタイプは{supported}です",
+		MessageInvalidJSON:             "リクエストボディが有効なjsonではありません:{reason}",
+		MessageUnknownField:            "リクエストボディに不明なフィールド[{field}]があります",
+		MessageInvalidBody:             "リクエストボディが不正です: {reason}",
+		MessageInvalidBodyField:        "リクエストボディのフィールド[{field}]の型が不正です: {reason}",
+		MessageMissingRequired:         "必須フィールド[{field}]がありません",
+		MessageInvalidOption:           "フィールド[{field}]の値は{limit}のいずれかでなければなりません",
+		MessageMinLimitExceeded:        "フィールド[{field}]の値は{limit}以上でなければなりません",
+		MessageMaxLimitExceeded:        "フィールド[{field}]の値は{limit}未満でなければなりません",
+		MessageMinLengthExceeded:       "フィールド[{field}]の長さは{limit}以上でなければなりません",
+		MessageMaxLengthExceeded:       "フィールド[{field}]の長さは{limit}未満でなければなりません",
+		MessageInvalidDomain:           "フィールド[{field}]は有効なドメイン名ではありません",
+		MessagePatternMismatch:         "フィールド[{field}]の値がパターン{limit}に一致しません",
+		MessageInvalidIPv4:             "フィールド[{field}]は有効なIPv4アドレスではありません",
+		MessageInvalidIPv6:             "フィールド[{field}]は有効なIPv6アドレスではありません",
+		MessageInvalidCIDR:             "フィールド[{field}]は有効なCIDRではありません",
+		MessageInvalidMAC:              "フィールド[{field}]は有効なMACアドレスではありません",
+		MessageInvalidEmail:            "フィールド[{field}]は有効なメールアドレスではありません",
+		MessageInvalidURL:              "フィールド[{field}]は有効なURLではありません",
+		MessageInvalidUUID:             "フィールド[{field}]は有効なUUIDではありません",
+		MessageValidateFailed:          "フィールド[{field}]はバリデータ{limit}のチェックに失敗しました",
+		MessageTimeNotAfter:            "フィールド[{field}]の時刻は{limit}より後でなければなりません",
+		MessageTimeNotBefore:           "フィールド[{field}]の時刻は{limit}より前でなければなりません",
+		MessageInvalidField:            "フィールド[{field}]の値が不正です",
+		MessageReadOnlyField:           "フィールド[{field}]は読み取り専用です",
+		MessageImmutableField:          "フィールド[{field}]は作成後に変更できません",
+		MessageDuplicateResource:       "{field}が同じ{type}リソースは既に存在します",
+		MessageResourceReferenced:      "{type}リソースは{referrer}リソースから参照されています",
+		MessageReferenceNotFound:       "{type}リソースの{field}が参照するリソースは存在しません",
+		MessageNotNullable:             "{type}リソースの{field}はnullにできません",
+		MessageCheckViolation:          "{type}リソースはチェック制約[{constraint}]に違反しています",
+		MessageConcurrentUpdate:        "{type}リソースは同時に変更されています, 再試行してください",
+		MessageDatabaseUnavailable:     "データベースが利用できません, 後で再試行してください",
+		MessageNoPatch:                 "リクエストにpatchドキュメントがありません",
+		MessagePatchCollection:         "{type}リソースコレクションのpatchはサポートされていません",
+		MessageInvalidPatch:            "patchドキュメントが不正です: {reason}",
+		MessageInvalidPatchedResource:  "patch後のリソースが不正です: {reason}",
+		MessageInvalidActionInput:      "アクション[{action}]の入力が不正です: {reason}",
+		MessageNoResourceInURL:         "url {url} にリソースがありません",
+		MessageUnknownResourceKind:     "種類が{kind}のリソースはありません",
+		MessageUnknownAPIVersion:       "{url}のapiバージョンは不明です",
+		MessageGenerateDocumentFailed:  "{kind}のドキュメントの生成に失敗しました: {reason}",
+		MessageConvertFailed:           "{type}リソースの変換に失敗しました: {reason}",
+		MessageInvalidListResult:       "listハンドラーの結果が不正です: {reason}",
+		MessageStreamingNotSupported:   "レスポンスはストリーミングをサポートしていません",
+		MessageUnsupportedMethod:       "メソッド{method}はサポートされていません",
+		MessageInvalidOwnerField:       "{type}リソースの親フィールド[{field}]は文字列ではありません",
+		MessageStoreFailed:             "データベース操作に失敗しました: {reason}",
+		MessageCompareFieldFailed:      "フィールド[{field}]の比較に失敗しました: {reason}",
+	},
+	LangRU: {
+		MessageNoHandler:               "операция {method} не поддерживается",
+		MessageResourceNotFound:        "ресурс {type} с id {id} не существует",
+		MessageGenerateLinksFailed:     "не удалось сгенерировать ссылки:{reason}",
+		MessageMarshalFailed:           "ошибка сериализации:{reason}",
+		MessageInternalServerError:     "внутренняя ошибка сервера, id запроса {requestId}",
+		MessageResourceVersionConflict: "ресурс [{id}] был изменен, обновите его и повторите попытку",
+		MessageWatchNotSupported:       "watch не поддерживается",
+		MessageUnknownAction:           "неизвестное действие [{action}]",
+		MessageInvalidResourceID:       "недопустимый id ресурса {type}",
+		MessageNotChild:                "{child} не является дочерним ресурсом {parent}",
+		MessageInvalidQuery:            "недопустимый параметр запроса [{name}]",
+		MessageReadBodyFailed:          "не удалось прочитать тело запроса: {reason}",
+		MessageBodyTooLarge:            "тело запроса превышает ограничение в {limit} байт",
+		MessageUnsupportedMediaType:    "неподдерживаемый тип содержимого [{contentType}], поддерживаемые типы: {supported}",
+		MessageInvalidJSON:             "тело запроса не является корректным json:{reason}",
+		MessageUnknownField:            "неизвестное поле [{field}] в теле запроса",
+		MessageInvalidBody:             "недопустимое тело запроса: {reason}",
+		MessageInvalidBodyField:        "недопустимый тип поля [{field}] в теле запроса: {reason}",
+		MessageMissingRequired:         "отсутствует обязательное поле [{field}]",
+		MessageInvalidOption:           "значение поля [{field}] должно быть одним из {limit}",
+		MessageMinLimitExceeded:        "значение поля [{field}] должно быть не меньше {limit}",
+		MessageMaxLimitExceeded:        "значение поля [{field}] должно быть меньше {limit}",
+		MessageMinLengthExceeded:       "длина поля [{field}] должна быть не меньше {limit}",
+		MessageMaxLengthExceeded:       "длина поля [{field}] должна быть меньше {limit}",
+		MessageInvalidDomain:           "поле [{field}] не является допустимым доменным именем",
+		MessagePatternMismatch:         "значение поля [{field}] не соответствует шаблону {limit}",
+		MessageInvalidIPv4:             "поле [{field}] не является допустимым адресом IPv4",
+		MessageInvalidIPv6:             "поле [{field}] не является допустимым адресом IPv6",
+		MessageInvalidCIDR:             "поле [{field}] не является допустимым CIDR",
+		MessageInvalidMAC:              "поле [{field}] не является допустимым MAC-адресом",
+		MessageInvalidEmail:            "поле [{field}] не является допустимым адресом электронной почты",
+		MessageInvalidURL:              "поле [{field}] не является допустимым URL",
+		MessageInvalidUUID:             "поле [{field}] не является допустимым UUID",
+		MessageValidateFailed:          "поле [{field}] не прошло проверку {limit}",
+		MessageTimeNotAfter:            "время в поле [{field}] должно быть позже {limit}",
+		MessageTimeNotBefore:           "время в поле [{field}] должно быть раньше {limit}",
+		MessageInvalidField:            "недопустимое значение поля [{field}]",
+		MessageReadOnlyField:           "поле [{field}] доступно только для чтения",
+		MessageImmutableField:          "поле [{field}] нельзя изменить после создания",
+		MessageDuplicateResource:       "ресурс {type} с таким же {field} уже существует",
+		MessageResourceReferenced:      "на ресурс {type} ссылаются ресурсы {referrer}",
+		MessageReferenceNotFound:       "ресурс, на который ссылается {field} ресурса {type}, не существует",
+		MessageNotNullable:             "{field} ресурса {type} не может быть null",
+		MessageCheckViolation:          "ресурс {type} нарушает ограничение-проверку [{constraint}]",
+		MessageConcurrentUpdate:        "ресурс {type} изменяется одновременно, повторите попытку",
+		MessageDatabaseUnavailable:     "база данных недоступна, повторите попытку позже",
+		MessageNoPatch:                 "в запросе нет документа patch",
+		MessagePatchCollection:         "patch коллекции ресурсов {type} не поддерживается",
+		MessageInvalidPatch:            "недопустимый документ patch: {reason}",
+		MessageInvalidPatchedResource:  "недопустимый ресурс после patch: {reason}",
+		MessageInvalidActionInput:      "недопустимые параметры действия [{action}]: {reason}",
+		MessageNoResourceInURL:         "в url {url} нет ресурса",
+		MessageUnknownResourceKind:     "нет ресурса типа {kind}",
+		MessageUnknownAPIVersion:       "неизвестная версия api в {url}",
+		MessageGenerateDocumentFailed:  "не удалось сгенерировать документ {kind}: {reason}",
+		MessageConvertFailed:           "не удалось преобразовать ресурс {type}: {reason}",
+		MessageInvalidListResult:       "обработчик list вернул недопустимый результат: {reason}",
+		MessageStreamingNotSupported:   "ответ не поддерживает потоковую передачу",
+		MessageUnsupportedMethod:       "метод {method} не поддерживается",
+		MessageInvalidOwnerField:       "поле владельца [{field}] ресурса {type} не является строкой",
+		MessageStoreFailed:             "ошибка операции с базой данных: {reason}",
+		MessageCompareFieldFailed:      "не удалось сравнить поле [{field}]: {reason}",
+	},
+}
@@ -0,0 +1,105 @@
+package gorest
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+)
+
+const (
+	ETagKey        = "ETag"
+	IfMatchKey     = "If-Match"
+	IfNoneMatchKey = "If-None-Match"
+)
+
+const matchAnyETag = "*"
+
+// etag of a resource is its quoted resource version, resource without
+// version has no etag
+func ResourceETag(r resource.Resource) string {
+	if r.GetResourceVersion() == 0 {
+		return ""
+	}
+	return strconv.Quote(strconv.FormatInt(r.GetResourceVersion(), 10))
+}
+
+func setETag(resp http.ResponseWriter, r resource.Resource) {
+	if etag := ResourceETag(r); etag != "" {
+		resp.Header().Set(ETagKey, etag)
+	}
+}
+
+// weak validator prefix is ignored, since the etag is only compared with
+// resource version
+func parseETags(header string) ([]string, bool) {
+	var etags []string
+	for _, etag := range strings.Split(header, ",") {
+		etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+		if etag == matchAnyETag {
+			return nil, true
+		} else if etag != "" {
+			etags = append(etags, etag)
+		}
+	}
+	return etags, false
+}
+
+func parseETagVersion(etag string) (int64, bool) {
+	unquoted, err := strconv.Unquote(etag)
+	if err != nil {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	return version, err == nil && version > 0
+}
+
+func isNotModified(ctx *resource.Context, r resource.Resource) bool {
+	etag := ResourceETag(r)
+	if etag == "" {
+		return false
+	}
+
+	etags, matchAny := parseETags(ctx.Request.Header.Get(IfNoneMatchKey))
+	return matchAny || slices.Contains(etags, etag)
+}
+
+// the expected version comes from If-Match header or resourceVersion in
+// request body, it's set to r so the handler could use it to do
+// compare-and-swap, if current resource is provided, stale version is
+// rejected with conflict error
+func checkResourceVersion(ctx *resource.Context, r, current resource.Resource) *goresterr.APIError {
+	etags, matchAny := parseETags(ctx.Request.Header.Get(IfMatchKey))
+	if len(etags) == 1 {
+		if version, ok := parseETagVersion(etags[0]); ok {
+			r.SetResourceVersion(version)
+		}
+	}
+
+	if current == nil || matchAny {
+		return nil
+	}
+
+	if len(etags) > 0 {
+		if slices.Contains(etags, ResourceETag(current)) == false {
+			return resourceVersionConflict(ctx, current)
+		}
+	} else if version := r.GetResourceVersion(); version != 0 && version != current.GetResourceVersion() {
+		return resourceVersionConflict(ctx, current)
+	}
+	return nil
+}
+
+func hasExpectedResourceVersion(ctx *resource.Context) bool {
+	return ctx.Request.Header.Get(IfMatchKey) != "" || ctx.Resource.GetResourceVersion() != 0
+}
+
+func resourceVersionConflict(ctx *resource.Context, current resource.Resource) *goresterr.APIError {
+	return goresterr.NewAPIError(goresterr.Conflict,
+		*goresterr.NewLocalizedErrorMessage("", goresterr.MessageResourceVersionConflict,
+			goresterr.Params{"type": ctx.Resource.GetType(), "id": ctx.Resource.GetID(), "version": current.GetResourceVersion()}))
+}
@@ -0,0 +1,163 @@
+package gorest
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+
+	"github.com/linkingthing/cement/uuid"
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+)
+
+const RequestIDKey = "X-Request-Id"
+
+type PanicReport struct {
+	RequestID string
+	Request   *http.Request
+	//nil if panic happens before the context is created
+	Context   *resource.Context
+	Recovered interface{}
+	Stack     []byte
+}
+
+type PanicReporter func(*PanicReport)
+
+func defaultPanicReporter(report *PanicReport) {
+	fmt.Fprintf(os.Stderr, "*** panic in %s %s with request id %s: %v\n%s\n",
+		report.Request.Method, report.Request.URL.Path, report.RequestID, report.Recovered, report.Stack)
+}
+
+// request id comes from the request header, or a new one is generated
+func getRequestID(req *http.Request) string {
+	if requestID := req.Header.Get(RequestIDKey); requestID != "" {
+		return requestID
+	}
+
+	requestID, _ := uuid.Gen()
+	return requestID
+}
+
+// responseWriter records whether the response is started, panic after
+// that can't be converted to an error response
+type responseWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.started = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *responseWriter) isStarted() bool {
+	return w.started
+}
+
+func (w *responseWriter) flush() {
+	w.started = true
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+func (w *responseWriter) hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.started = true
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+// the wrappers are used only if the underlying writer supports flush or
+// hijack, so handlers like watch could still check whether they're supported
+type flushResponseWriter struct {
+	*responseWriter
+}
+
+func (w flushResponseWriter) Flush() {
+	w.flush()
+}
+
+type hijackResponseWriter struct {
+	*responseWriter
+}
+
+func (w hijackResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.hijack()
+}
+
+type flushHijackResponseWriter struct {
+	*responseWriter
+}
+
+func (w flushHijackResponseWriter) Flush() {
+	w.flush()
+}
+
+func (w flushHijackResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.hijack()
+}
+
+func wrapResponseWriter(rw http.ResponseWriter) http.ResponseWriter {
+	w := &responseWriter{ResponseWriter: rw}
+	_, canFlush := rw.(http.Flusher)
+	_, canHijack := rw.(http.Hijacker)
+	switch {
+	case canFlush && canHijack:
+		return flushHijackResponseWriter{w}
+	case canFlush:
+		return flushResponseWriter{w}
+	case canHijack:
+		return hijackResponseWriter{w}
+	default:
+		return w
+	}
+}
+
+func responseStarted(rw http.ResponseWriter) bool {
+	w, ok := rw.(interface{ isStarted() bool })
+	return ok && w.isStarted()
+}
+
+func (s *Server) reportPanic(requestID string, req *http.Request, ctx *resource.Context, recovered interface{}) {
+	if s.panicReporter != nil {
+		s.panicReporter(&PanicReport{
+			RequestID: requestID,
+			Request:   req,
+			Context:   ctx,
+			Recovered: recovered,
+			Stack:     debug.Stack(),
+		})
+	}
+}
+
+// panic is reported and converted to server error with request id, so
+// the client could use the request id to find the report, if the response
+// is started, like a watch stream, no error response is written and the
+// connection should be aborted after the end handlers
+func (s *Server) recoverPanic(rw http.ResponseWriter, req *http.Request, ctx *resource.Context, recovered interface{}) (*goresterr.APIError, bool) {
+	requestID := getRequestID(req)
+	if recovered != http.ErrAbortHandler {
+		s.reportPanic(requestID, req, ctx, recovered)
+	}
+
+	err := goresterr.NewAPIError(goresterr.ServerError,
+		*goresterr.NewLocalizedErrorMessage("", goresterr.MessageInternalServerError,
+			goresterr.Params{"requestId": requestID})).Localization(resource.RequestAcceptLanguage(req))
+	err.RequestID = requestID
+	if recovered == http.ErrAbortHandler || responseStarted(rw) {
+		return err, true
+	}
+
+	rw.Header().Set(RequestIDKey, requestID)
+	WriteResponse(rw, err.Status, err)
+	return err, false
+}
@@ -0,0 +1,266 @@
+package resource
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/linkingthing/gorest/error"
+)
+
+const ContentTypeJSON = "application/json"
+
+type BodyOptions struct {
+	//max bytes of request body, 0 means no limit
+	MaxSize int64
+	//reject unknown fields and fields with mismatched type in request body
+	Strict bool
+}
+
+type bodyOptionsKey struct{}
+
+// WithBodyOptions attach the options to the request, they are used when
+// the resource is created from the request body
+func WithBodyOptions(req *http.Request, opts BodyOptions) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), bodyOptionsKey{}, opts))
+}
+
+func GetBodyOptions(req *http.Request) BodyOptions {
+	opts, _ := req.Context().Value(bodyOptionsKey{}).(BodyOptions)
+	return opts
+}
+
+func ReadRequestBody(req *http.Request) ([]byte, *error.APIError) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	defer req.Body.Close()
+
+	maxSize := GetBodyOptions(req).MaxSize
+	reader := io.Reader(req.Body)
+	if maxSize > 0 {
+		reader = io.LimitReader(req.Body, maxSize+1)
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, error.NewAPIError(error.InvalidBodyContent,
+			*error.NewLocalizedErrorMessage("", error.MessageReadBodyFailed, error.Params{"reason": err.Error()}))
+	}
+
+	if maxSize > 0 && int64(len(body)) > maxSize {
+		return nil, error.NewAPIError(error.RequestEntityTooLarge,
+			*error.NewLocalizedErrorMessage("", error.MessageBodyTooLarge, error.Params{"limit": maxSize}))
+	}
+	return body, nil
+}
+
+// empty content type is treated as json, for compatibility with old clients
+func CheckJSONContentType(req *http.Request) *error.APIError {
+	contentType := req.Header.Get("Content-Type")
+	if contentType == "" {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil && (mediaType == ContentTypeJSON || strings.HasSuffix(mediaType, "+json")) {
+		return nil
+	}
+
+	return UnsupportedMediaTypeError(contentType, ContentTypeJSON)
+}
+
+func UnsupportedMediaTypeError(contentType string, supported ...string) *error.APIError {
+	return error.NewAPIError(error.UnsupportedMediaType,
+		*error.NewLocalizedErrorMessage("", error.MessageUnsupportedMediaType,
+			error.Params{"contentType": contentType, "supported": strings.Join(supported, ",")}))
+}
+
+// DecodeJSON unmarshal body to v, in strict mode, unknown fields and
+// fields with mismatched type are reported with their json path
+func DecodeJSON(body []byte, v interface{}, strict bool) *error.APIError {
+	if strict == false {
+		json.Unmarshal(body, v)
+		return nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return error.NewAPIError(error.InvalidBodyContent,
+			*error.NewLocalizedErrorMessage("", error.MessageInvalidJSON, error.Params{"reason": err.Error()}))
+	}
+
+	if err := checkJSONValue(reflect.TypeOf(v), doc, ""); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		var path string
+		if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+			path = typeErr.Field
+		}
+		return invalidJSONFieldError(path, err.Error())
+	}
+	return nil
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func checkJSONValue(typ reflect.Type, value interface{}, path string) *error.APIError {
+	for typ.Kind() == reflect.Ptr {
+		if typ.Implements(jsonUnmarshalerType) {
+			return nil
+		}
+		typ = typ.Elem()
+	}
+
+	if value == nil || typ.Kind() == reflect.Interface ||
+		reflect.PointerTo(typ).Implements(jsonUnmarshalerType) {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		switch typ.Kind() {
+		case reflect.Struct:
+			fields := jsonFields(typ)
+			for key, fv := range v {
+				field, ok := lookupJSONField(fields, key)
+				if ok == false {
+					return error.NewAPIError(error.InvalidBodyContent,
+						*error.NewLocalizedErrorMessage("", error.MessageUnknownField,
+							error.Params{"field": joinJSONPath(path, key)}))
+				}
+				if err := checkJSONValue(field.Type, fv, joinJSONPath(path, key)); err != nil {
+					return err
+				}
+			}
+			return nil
+		case reflect.Map:
+			for key, fv := range v {
+				if err := checkJSONValue(typ.Elem(), fv, joinJSONPath(path, key)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case []interface{}:
+		if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+			for i, ev := range v {
+				if err := checkJSONValue(typ.Elem(), ev, path+"["+strconv.Itoa(i)+"]"); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case string:
+		if typ.Kind() == reflect.String || reflect.PointerTo(typ).Implements(textUnmarshalerType) {
+			return nil
+		}
+	case float64:
+		switch typ.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return nil
+		}
+	case bool:
+		if typ.Kind() == reflect.Bool {
+			return nil
+		}
+	}
+
+	return invalidJSONFieldError(path, fmt.Sprintf("should be %s but get %s", typ.Kind(), jsonValueKind(value)))
+}
+
+func invalidJSONFieldError(path, reason string) *error.APIError {
+	if path == "" {
+		return error.NewAPIError(error.InvalidBodyContent,
+			*error.NewLocalizedErrorMessage("", error.MessageInvalidBody, error.Params{"reason": reason}))
+	}
+
+	return error.NewAPIError(error.InvalidBodyContent,
+		*error.NewLocalizedErrorMessage("", error.MessageInvalidBodyField, error.Params{"field": path, "reason": reason}))
+}
+
+// fields of embedded struct without json name are promoted
+func jsonFields(typ reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedType := field.Type
+			if embedType.Kind() == reflect.Ptr {
+				embedType = embedType.Elem()
+			}
+			if embedType.Kind() == reflect.Struct {
+				for n, f := range jsonFields(embedType) {
+					if _, ok := fields[n]; ok == false {
+						fields[n] = f
+					}
+				}
+				continue
+			}
+		}
+
+		if field.IsExported() == false {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+// same with encoding/json, key matches field name case-insensitively
+func lookupJSONField(fields map[string]reflect.StructField, key string) (reflect.StructField, bool) {
+	if field, ok := fields[key]; ok {
+		return field, true
+	}
+
+	for name, field := range fields {
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func joinJSONPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func jsonValueKind(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case float64:
+		return "number"
+	default:
+		return reflect.TypeOf(value).Kind().String()
+	}
+}
@@ -0,0 +1,88 @@
+package resource
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	ut "github.com/linkingthing/cement/unittest"
+	"github.com/linkingthing/gorest/error"
+)
+
+type bodyInfo struct {
+	Name    string   `json:"name"`
+	Numbers []uint32 `json:"numbers"`
+}
+
+type bodyResource struct {
+	ResourceBase `json:",inline"`
+	Name         string              `json:"name"`
+	Count        uint32              `json:"count"`
+	Labels       map[string]string   `json:"labels"`
+	Infos        []bodyInfo          `json:"infos"`
+	Info         *bodyInfo           `json:"info"`
+	Extra        map[string]bodyInfo `json:"extra"`
+}
+
+func TestDecodeJSON(t *testing.T) {
+	cases := []struct {
+		body string
+		path string
+	}{
+		{`{"id":"b1","name":"b","count":1,"labels":{"a":"b"},"infos":[{"name":"i1","numbers":[1]}],"creationTimestamp":null}`, ""},
+		{`{"Name":"b","INFO":{"name":"i1"}}`, ""},
+		{`{"name":"b","color":"red"}`, "color"},
+		{`{"name":"b","infos":[{"name":"i1"},{"name":"i2","size":1}]}`, "infos[1].size"},
+		{`{"name":"b","info":{"numbers":["1"]}}`, "info.numbers[0]"},
+		{`{"name":"b","extra":{"e1":{"name":1}}}`, "extra.e1.name"},
+		{`{"count":"1"}`, "count"},
+		{`{"count":1.5}`, "count"},
+	}
+
+	for _, tc := range cases {
+		var r bodyResource
+		err := DecodeJSON([]byte(tc.body), &r, true)
+		if tc.path == "" {
+			ut.Assert(t, err == nil, "decode %s failed:%v", tc.body, err)
+		} else {
+			ut.Assert(t, err != nil, "decode %s should fail", tc.body)
+			ut.Equal(t, err.ErrorCode, error.InvalidBodyContent)
+			ut.Assert(t, strings.Contains(err.Message, tc.path), "%s doesn't contain %s", err.Message, tc.path)
+			ut.Assert(t, strings.Contains(err.MessageCN, tc.path), "%s doesn't contain %s", err.MessageCN, tc.path)
+		}
+
+		ut.Assert(t, DecodeJSON([]byte(tc.body), &bodyResource{}, false) == nil, "")
+	}
+}
+
+func TestReadRequestBody(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "/apis/testing/v1/bodyresources", bytes.NewBufferString(`{"name":"b"}`))
+	req = WithBodyOptions(req, BodyOptions{MaxSize: 12})
+	body, err := ReadRequestBody(req)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, string(body), `{"name":"b"}`)
+
+	req, _ = http.NewRequest(http.MethodPost, "/apis/testing/v1/bodyresources", bytes.NewBufferString(`{"name":"bb"}`))
+	req = WithBodyOptions(req, BodyOptions{MaxSize: 12})
+	_, err = ReadRequestBody(req)
+	ut.Equal(t, err.ErrorCode, error.RequestEntityTooLarge)
+
+	for contentType, ok := range map[string]bool{
+		"":                                true,
+		"application/json":                true,
+		"application/json; charset=utf-8": true,
+		"application/merge-patch+json":    true,
+		"text/plain":                      false,
+		"application/xml":                 false,
+		"json":                            false,
+	} {
+		req.Header.Set("Content-Type", contentType)
+		err := CheckJSONContentType(req)
+		if ok {
+			ut.Assert(t, err == nil, "content type %s should be accepted", contentType)
+		} else {
+			ut.Equal(t, err.ErrorCode, error.UnsupportedMediaType)
+		}
+	}
+}
@@ -14,6 +14,7 @@ type ResourceCollection struct {
 	Resources    []Resource                        `json:"data"`
 
 	collection Resource `json:"-"`
+	nextQuery  string   `json:"-"`
 }
 
 type Pagination struct {
@@ -21,6 +22,14 @@ type Pagination struct {
 	PageNum   int `json:"pageNum,omitempty"`
 	PageSize  int `json:"pageSize,omitempty"`
 	Total     int `json:"total,omitempty"`
+
+	//keyset pagination token in request, empty for the first page
+	Continue string `json:"-"`
+	//keyset pagination token of next page set by store,
+	//empty means current page is the last one
+	Next string `json:"-"`
+
+	keyset bool
 }
 
 func NewResourceCollection(ctx *Context, i interface{}) (*ResourceCollection, error) {
@@ -29,6 +38,9 @@ func NewResourceCollection(ctx *Context, i interface{}) (*ResourceCollection, er
 	if err != nil {
 		return nil, err
 	} else {
+		if ctx.IsSortsApplied() == false && isPaginatedByStore(ctx.GetPagination()) == false {
+			sortResources(rs, ctx.GetSorts())
+		}
 		resources, pagination := applyPagination(ctx.GetPagination(), rs)
 		return &ResourceCollection{
 			Type:         "collection",
@@ -36,6 +48,7 @@ func NewResourceCollection(ctx *Context, i interface{}) (*ResourceCollection, er
 			Pagination:   pagination,
 			Resources:    resources,
 			collection:   ctx.Resource,
+			nextQuery:    genNextQuery(ctx, pagination),
 		}, nil
 	}
 }
@@ -106,30 +119,82 @@ func (rc *ResourceCollection) GetResources() []Resource {
 	return rc.Resources
 }
 
-func applyPagination(pagination *Pagination, resources []Resource) ([]Resource, *Pagination) {
-	resourcesLen := len(resources)
-	if resourcesLen == 0 || pagination == nil ||
-		pagination.PageSize <= 0 || pagination.PageNum <= 0 || pagination.PageTotal != 0 {
-		return resources, pagination
+// query of the next page link, empty if there is no next page
+func (rc *ResourceCollection) GetNextQuery() string {
+	return rc.nextQuery
+}
+
+func genNextQuery(ctx *Context, pagination *Pagination) string {
+	if ctx.Request == nil || pagination.IsKeyset() == false || pagination.Next == "" {
+		return ""
 	}
 
-	pageSize := pagination.PageSize
-	pageNum := pagination.PageNum
-	if pageSize > resourcesLen {
-		pageSize = resourcesLen
+	query := ctx.Request.URL.Query()
+	query.Set(FilterNameContinue, pagination.Next)
+	return query.Encode()
+}
+
+func NewKeysetPagination(pageSize int, token string) *Pagination {
+	return &Pagination{
+		PageSize: pageSize,
+		Continue: token,
+		keyset:   true,
 	}
+}
+
+// offset pagination specified by page num and page size
+func (p *Pagination) IsEnabled() bool {
+	return p != nil && p.keyset == false && p.PageSize > 0 && p.PageNum > 0
+}
+
+func (p *Pagination) IsKeyset() bool {
+	return p != nil && p.keyset && p.PageSize > 0
+}
 
-	pageTotal := int(math.Ceil(float64(resourcesLen) / float64(pageSize)))
-	if pageNum > pageTotal {
-		pageNum = pageTotal
+// set the total count of resources, page total is calculated
+// and page size, page num are adjusted into valid range
+func (p *Pagination) SetTotal(total int) {
+	p.Total = total
+	if total == 0 {
+		p.PageTotal = 0
+		return
+	}
+
+	if p.PageSize > total {
+		p.PageSize = total
+	}
+
+	p.PageTotal = int(math.Ceil(float64(total) / float64(p.PageSize)))
+	if p.PageNum > p.PageTotal {
+		p.PageNum = p.PageTotal
+	}
+}
+
+// offset of the first resource in current page
+func (p *Pagination) GetOffset() int {
+	return (p.PageNum - 1) * p.PageSize
+}
+
+// if page total is set, the resources has been paginated by handler
+// or store which report the total, so return them directly
+// resources paginated by store are sorted by store too
+func isPaginatedByStore(pagination *Pagination) bool {
+	return pagination != nil && (pagination.PageTotal != 0 || pagination.IsKeyset())
+}
+
+func applyPagination(pagination *Pagination, resources []Resource) ([]Resource, *Pagination) {
+	resourcesLen := len(resources)
+	if resourcesLen == 0 || pagination.IsEnabled() == false || pagination.PageTotal != 0 {
+		return resources, pagination
 	}
 
-	startIndex := (pageNum - 1) * pageSize
-	endIndex := startIndex + pageSize
+	p := &Pagination{PageSize: pagination.PageSize, PageNum: pagination.PageNum}
+	p.SetTotal(resourcesLen)
+	startIndex := p.GetOffset()
+	endIndex := startIndex + p.PageSize
 	if endIndex >= resourcesLen {
 		endIndex = resourcesLen
 	}
 
-	return resources[startIndex:endIndex],
-		&Pagination{PageTotal: pageTotal, PageNum: pageNum, PageSize: pageSize, Total: resourcesLen}
+	return resources[startIndex:endIndex], p
 }
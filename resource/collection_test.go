@@ -2,6 +2,7 @@ package resource
 
 import (
 	"encoding/json"
+	"net/http"
 	"net/url"
 	"testing"
 
@@ -19,7 +20,7 @@ func TestCollectionToJson(t *testing.T) {
 	ut.Assert(t, err == nil, "")
 	ut.Assert(t, rs.Resources != nil, "")
 	d, _ := json.Marshal(rs)
-	ut.Equal(t, string(d), `{"type":"collection","data":[]}`)
+	ut.Equal(t, string(d), `{"type":"collection","pagination":null,"data":[]}`)
 
 	rs2, err := NewResourceCollection(ctx, []*dumbResource{})
 	ut.Assert(t, err == nil, "")
@@ -50,6 +51,74 @@ func TestPagination(t *testing.T) {
 	ut.Assert(t, pagination.PageNum == 1, "")
 	ut.Assert(t, pagination.Total == 55, "")
 	ut.Assert(t, pagination.PageSize == 55, "")
+
+	//pagination has been applied by store
+	storePagination := &Pagination{PageSize: 10, PageNum: 2}
+	storePagination.SetTotal(1000)
+	retrs, pagination = applyPagination(storePagination, rs[:10])
+	ut.Assert(t, len(retrs) == 10, "")
+	ut.Assert(t, pagination.PageTotal == 100, "")
+	ut.Assert(t, pagination.PageNum == 2, "")
+	ut.Assert(t, pagination.Total == 1000, "")
+	ut.Assert(t, pagination.GetOffset() == 10, "")
+}
+
+func TestPaginationSetTotal(t *testing.T) {
+	cases := []struct {
+		pagination Pagination
+		total      int
+		expect     Pagination
+	}{
+		{Pagination{PageSize: 10, PageNum: 3}, 55, Pagination{PageTotal: 6, PageNum: 3, PageSize: 10, Total: 55}},
+		{Pagination{PageSize: 10, PageNum: 7}, 55, Pagination{PageTotal: 6, PageNum: 6, PageSize: 10, Total: 55}},
+		{Pagination{PageSize: 100, PageNum: 2}, 55, Pagination{PageTotal: 1, PageNum: 1, PageSize: 55, Total: 55}},
+		{Pagination{PageSize: 10, PageNum: 2}, 0, Pagination{PageTotal: 0, PageNum: 2, PageSize: 10, Total: 0}},
+	}
+
+	for _, tc := range cases {
+		tc.pagination.SetTotal(tc.total)
+		ut.Equal(t, tc.pagination, tc.expect)
+	}
+}
+
+func TestKeysetPagination(t *testing.T) {
+	cases := []struct {
+		query     string
+		isValid   bool
+		continue_ string
+	}{
+		{"page_size=10&continue=", true, ""},
+		{"page_size=10&continue=abc&name=n1", true, "abc"},
+		{"continue=abc", false, ""},
+		{"page_size=10&page_num=2&continue=abc", false, ""},
+	}
+
+	for _, tc := range cases {
+		_, pagination, err := genFiltersAndPagination(&url.URL{RawQuery: tc.query})
+		if tc.isValid {
+			ut.Assert(t, err == nil, "parse %s failed %v", tc.query, err)
+			ut.Assert(t, pagination.IsKeyset(), "")
+			ut.Assert(t, pagination.IsEnabled() == false, "")
+			ut.Equal(t, pagination.Continue, tc.continue_)
+		} else {
+			ut.Assert(t, err != nil, "parse %s should fail", tc.query)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v1/clusters?page_size=2&continue=&name=n1", nil)
+	pagination := NewKeysetPagination(2, "")
+	pagination.Next = "next_token"
+	collection := &dumbResource{}
+	collection.SetType("dumbresource")
+	ctx := &Context{Request: req, Resource: collection, pagination: pagination}
+	rs, err := NewResourceCollection(ctx, []*dumbResource{&dumbResource{}, &dumbResource{}})
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, rs.GetNextQuery(), "continue=next_token&name=n1&page_size=2")
+
+	pagination.Next = ""
+	rs, err = NewResourceCollection(ctx, []*dumbResource{&dumbResource{}})
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, rs.GetNextQuery(), "")
 }
 
 func TestGenFiltersAndPagination(t *testing.T) {
@@ -1,7 +1,6 @@
 package resource
 
 import (
-	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -26,17 +25,23 @@ const (
 
 	FilterNamePageSize = "page_size"
 	FilterNamePageNum  = "page_num"
+	FilterNameContinue = "continue"
+	FilterNameFields   = "fields"
+	FilterNameWatch    = "watch"
 )
 
 type Context struct {
-	Schemas    SchemaManager
-	Request    *http.Request
-	Response   http.ResponseWriter
-	Resource   Resource
-	Method     string
-	params     map[string]interface{}
-	filters    []Filter
-	pagination *Pagination
+	Schemas      SchemaManager
+	Request      *http.Request
+	Response     http.ResponseWriter
+	Resource     Resource
+	Method       string
+	params       map[string]interface{}
+	filters      []Filter
+	pagination   *Pagination
+	sorts        []Sort
+	sortsApplied bool
+	fields       []string
 }
 
 type Filter struct {
@@ -50,12 +55,28 @@ type Modifier string
 func NewContext(resp http.ResponseWriter, req *http.Request, schemas SchemaManager) (*Context, *error.APIError) {
 	filters, pagination, err := genFiltersAndPagination(req.URL)
 	if err != nil {
-		return nil, err.Localization(IsRequestAcceptLanguageZH(req))
+		return nil, err.Localization(RequestAcceptLanguage(req))
+	}
+
+	sorts, err := parseSorts(req.URL.Query()[FilterNameSort])
+	if err != nil {
+		return nil, err.Localization(RequestAcceptLanguage(req))
 	}
 
 	r, err := schemas.CreateResourceFromRequest(req)
 	if err != nil {
-		return nil, err
+		return nil, err.Localization(RequestAcceptLanguage(req))
+	}
+
+	if err := validateSorts(r, sorts); err != nil {
+		return nil, err.Localization(RequestAcceptLanguage(req))
+	}
+
+	fields := parseFields(req.URL.Query()[FilterNameFields])
+	if len(fields) > 0 && r.GetSchema() != nil {
+		if err := r.GetSchema().ValidateFields(fields); err != nil {
+			return nil, err.Localization(RequestAcceptLanguage(req))
+		}
 	}
 
 	return &Context{
@@ -67,6 +88,8 @@ func NewContext(resp http.ResponseWriter, req *http.Request, schemas SchemaManag
 		params:     make(map[string]interface{}),
 		filters:    filters,
 		pagination: pagination,
+		sorts:      sorts,
+		fields:     fields,
 	}, nil
 }
 
@@ -87,16 +110,51 @@ func (ctx *Context) GetPagination() *Pagination {
 	return ctx.pagination
 }
 
+func (ctx *Context) GetSorts() []Sort {
+	return ctx.sorts
+}
+
+// list handler which returns the resources in the order of the sorts,
+// like the db store handler, calls it to skip sorting in memory
+func (ctx *Context) SetSortsApplied() {
+	ctx.sortsApplied = true
+}
+
+func (ctx *Context) IsSortsApplied() bool {
+	return ctx.sortsApplied
+}
+
+// watch=true on collection keeps the connection and streams the
+// changes of the resources
+func (ctx *Context) IsWatch() bool {
+	if ctx.Request == nil || ctx.Method != http.MethodGet || ctx.Resource.GetID() != "" {
+		return false
+	}
+
+	watch, _ := strconv.ParseBool(ctx.Request.URL.Query().Get(FilterNameWatch))
+	return watch
+}
+
+// json names of the fields in response, empty means all the fields
+func (ctx *Context) GetFields() []string {
+	return ctx.fields
+}
+
 func (ctx *Context) SetPagination(pagination *Pagination) {
 	ctx.pagination = pagination
 }
 
+// language negotiated with the Accept-Language header of the request
+func (ctx *Context) AcceptLanguage() string {
+	return RequestAcceptLanguage(ctx.Request)
+}
+
 func (ctx *Context) IsAcceptLanguageZH() bool {
-	return strings.HasPrefix(ctx.Request.Header.Get("accept-language"), "zh")
+	return IsRequestAcceptLanguageZH(ctx.Request)
 }
 
 func IsRequestAcceptLanguageZH(request *http.Request) bool {
-	return strings.HasPrefix(request.Header.Get("accept-language"), "zh")
+	return error.BaseLanguage(RequestAcceptLanguage(request)) == error.LangZH
 }
 
 func genFiltersAndPagination(requestUrl *url.URL) ([]Filter, *Pagination, *error.APIError) {
@@ -132,25 +190,57 @@ func genFiltersAndPagination(requestUrl *url.URL) ([]Filter, *Pagination, *error
 			if pagination.PageNum, err = filtersValuesToInt(filter.Values); err != nil {
 				return nil, nil, err
 			}
+		case FilterNameSort, FilterNameFields, FilterNameWatch:
+			//sort, fields and watch aren't filters
+		case FilterNameContinue:
+			pagination.keyset = true
+			if len(filter.Values) > 0 {
+				pagination.Continue = filter.Values[0]
+			}
 		default:
 			filters = append(filters, filter)
 		}
 	}
 
+	if pagination.keyset {
+		if pagination.PageNum != 0 {
+			return nil, nil, error.NewAPIError(error.InvalidFormat,
+				*error.NewLocalizedErrorMessage("continue can't be used with page_num",
+					error.MessageInvalidQuery, error.Params{"name": FilterNameContinue}))
+		} else if pagination.PageSize == 0 {
+			return nil, nil, error.NewAPIError(error.InvalidFormat,
+				*error.NewLocalizedErrorMessage("continue should be used with page_size",
+					error.MessageInvalidQuery, error.Params{"name": FilterNameContinue}))
+		}
+	}
+
 	return filters, &pagination, nil
 }
 
+// fields=name,address means only name and address are in response
+func parseFields(values []string) []string {
+	var fields []string
+	for _, value := range values {
+		for _, field := range strings.Split(value, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
+
 func filtersValuesToInt(values []string) (int, *error.APIError) {
 	var i int
 	for _, value := range values {
 		if valueInt, err := strconv.Atoi(value); err != nil {
 			return 0, error.NewAPIError(error.InvalidFormat,
-				*error.NewErrorMessage("negative number",
-					fmt.Sprintf(error.ErrorCHNameInvalidQuery, value)))
+				*error.NewLocalizedErrorMessage("negative number",
+					error.MessageInvalidQuery, error.Params{"name": value}))
 		} else if i = valueInt; i < 0 {
 			return 0, error.NewAPIError(error.InvalidFormat,
-				*error.NewErrorMessage("negative number",
-					fmt.Sprintf(error.ErrorCHNameInvalidQuery, value)))
+				*error.NewLocalizedErrorMessage("negative number",
+					error.MessageInvalidQuery, error.Params{"name": value}))
 		} else {
 			break
 		}
@@ -0,0 +1,65 @@
+package resource
+
+import (
+	"fmt"
+)
+
+// Converter convert resource between the kind of an api version and the
+// hub kind which is handled by the handler, fields of ResourceBase like
+// id, parent, action and patch are copied by the caller, converter only
+// needs to handle the fields of the kind
+type Converter struct {
+	ToHub   func(Resource) (Resource, error)
+	FromHub func(Resource) (Resource, error)
+}
+
+// NewConverter create converter with typed convert functions, like
+// NewConverter(func(p *v1.Pod) (*Pod, error), func(p *Pod) (*v1.Pod, error))
+func NewConverter[V, H any, PV interface {
+	*V
+	Resource
+}, PH interface {
+	*H
+	Resource
+}](toHub func(PV) (PH, error), fromHub func(PH) (PV, error)) Converter {
+	return Converter{
+		ToHub: func(r Resource) (Resource, error) {
+			v, ok := r.(PV)
+			if ok == false {
+				return nil, fmt.Errorf("resource %T isn't %T", r, v)
+			}
+			return toHub(v)
+		},
+		FromHub: func(r Resource) (Resource, error) {
+			h, ok := r.(PH)
+			if ok == false {
+				return nil, fmt.Errorf("resource %T isn't %T", r, h)
+			}
+			return fromHub(h)
+		},
+	}
+}
+
+// ConvertResource convert the resource by the convert function, and copy
+// the fields of ResourceBase
+func ConvertResource(r Resource, convert func(Resource) (Resource, error)) (Resource, error) {
+	converted, err := convert(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if converted == nil {
+		return nil, fmt.Errorf("convert %s to nil", r.GetType())
+	}
+
+	converted.SetID(r.GetID())
+	converted.SetType(r.GetType())
+	converted.SetParent(r.GetParent())
+	converted.SetSchema(r.GetSchema())
+	converted.SetCreationTimestamp(r.GetCreationTimestamp())
+	converted.SetDeletionTimestamp(r.GetDeletionTimestamp())
+	converted.SetResourceVersion(r.GetResourceVersion())
+	converted.SetAction(r.GetAction())
+	converted.SetPatch(r.GetPatch())
+	return converted, nil
+}
@@ -12,6 +12,7 @@ const (
 	CreateMethod string = "Create"
 	DeleteMethod string = "Delete"
 	UpdateMethod string = "Update"
+	PatchMethod  string = "Patch"
 	ListMethod   string = "List"
 	GetMethod    string = "Get"
 	ActionMethod string = "Action"
@@ -20,6 +21,7 @@ const (
 type CreateHandler func(*Context) (Resource, *goresterr.APIError)
 type DeleteHandler func(*Context) *goresterr.APIError
 type UpdateHandler func(*Context) (Resource, *goresterr.APIError)
+type PatchHandler func(*Context) (Resource, *goresterr.APIError)
 type ListHandler func(*Context) (interface{}, *goresterr.APIError)
 type GetHandler func(*Context) (Resource, *goresterr.APIError)
 type ActionHandler func(*Context) (interface{}, *goresterr.APIError)
@@ -28,6 +30,7 @@ type Handler interface {
 	GetCreateHandler() CreateHandler
 	GetDeleteHandler() DeleteHandler
 	GetUpdateHandler() UpdateHandler
+	GetPatchHandler() PatchHandler
 	GetListHandler() ListHandler
 	GetGetHandler() GetHandler
 	GetActionHandler() ActionHandler
@@ -73,6 +76,20 @@ func HandlerAdaptor(obj interface{}) (Handler, error) {
 		}
 	}
 
+	if mv := val.MethodByName(PatchMethod); mv.IsValid() {
+		if method, ok := mv.Interface().(func(*Context) (Resource, *goresterr.APIError)); ok {
+			handler.patchHandler = method
+			hasAnyHandler = true
+		} else {
+			return nil, fmt.Errorf("handler has '%s' method but with wrong signature", PatchMethod)
+		}
+
+		//patch is applied to the resource returned by get handler
+		if handler.getHandler == nil {
+			return nil, fmt.Errorf("handler has '%s' method but without '%s' method", PatchMethod, GetMethod)
+		}
+	}
+
 	if mv := val.MethodByName(CreateMethod); mv.IsValid() {
 		if method, ok := mv.Interface().(func(*Context) (Resource, *goresterr.APIError)); ok {
 			handler.createHandler = method
@@ -105,6 +122,7 @@ type DefaultHandler struct {
 	createHandler CreateHandler
 	deleteHandler DeleteHandler
 	updateHandler UpdateHandler
+	patchHandler  PatchHandler
 	listHandler   ListHandler
 	getHandler    GetHandler
 	actionHandler ActionHandler
@@ -122,6 +140,10 @@ func (h *DefaultHandler) GetUpdateHandler() UpdateHandler {
 	return h.updateHandler
 }
 
+func (h *DefaultHandler) GetPatchHandler() PatchHandler {
+	return h.patchHandler
+}
+
 func (h *DefaultHandler) GetListHandler() ListHandler {
 	return h.listHandler
 }
@@ -156,6 +178,9 @@ func GetResourceMethods(handler Handler) []HttpMethod {
 	if handler.GetUpdateHandler() != nil {
 		resourceMethods = append(resourceMethods, http.MethodPut)
 	}
+	if handler.GetPatchHandler() != nil {
+		resourceMethods = append(resourceMethods, http.MethodPatch)
+	}
 	if handler.GetActionHandler() != nil {
 		resourceMethods = append(resourceMethods, http.MethodPost)
 	}
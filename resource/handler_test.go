@@ -16,6 +16,22 @@ func (h *dumbHandlerTwo) Create(ctx *Context) (Resource, *err.APIError) {
 
 type emptyHandler struct{}
 
+type patchHandler struct{}
+
+func (h *patchHandler) Get(ctx *Context) (Resource, *err.APIError) {
+	return &dumbResource{Number: 70}, nil
+}
+
+func (h *patchHandler) Patch(ctx *Context) (Resource, *err.APIError) {
+	return &dumbResource{Number: 80}, nil
+}
+
+type patchWithoutGetHandler struct{}
+
+func (h *patchWithoutGetHandler) Patch(ctx *Context) (Resource, *err.APIError) {
+	return &dumbResource{Number: 80}, nil
+}
+
 func TestHandlerGen(t *testing.T) {
 	handler, _ := HandlerAdaptor(&DumbHandler{})
 	resourceMethods := GetResourceMethods(handler)
@@ -53,3 +69,16 @@ func TestHandlerGen(t *testing.T) {
 	_, err_ := HandlerAdaptor(&emptyHandler{})
 	ut.Assert(t, err_ != nil, "")
 }
+
+func TestPatchHandlerGen(t *testing.T) {
+	handler, err := HandlerAdaptor(&patchHandler{})
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, GetResourceMethods(handler), []HttpMethod{http.MethodGet, http.MethodPatch})
+	ut.Equal(t, len(GetCollectionMethods(handler)), 0)
+
+	patchResult, _ := handler.GetPatchHandler()(nil)
+	ut.Equal(t, patchResult.(*dumbResource).Number, 80)
+
+	_, err = HandlerAdaptor(&patchWithoutGetHandler{})
+	ut.Assert(t, err != nil, "")
+}
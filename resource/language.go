@@ -0,0 +1,86 @@
+package resource
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/linkingthing/gorest/error"
+)
+
+const (
+	AcceptLanguageKey = "Accept-Language"
+	DefaultLanguage   = error.LangEN
+)
+
+type languageRange struct {
+	tag string
+	q   float64
+}
+
+// RequestAcceptLanguage negotiate the language of request with the
+// languages in error catalog
+func RequestAcceptLanguage(req *http.Request) string {
+	return NegotiateLanguage(req.Header.Get(AcceptLanguageKey), error.GetCatalog().Languages())
+}
+
+// NegotiateLanguage choose the supported language with the highest
+// q-value in the accept language header, language tag matches itself and
+// its base language, like zh-CN matches zh, default language is returned
+// for * or no match
+func NegotiateLanguage(acceptLanguage string, supported []string) string {
+	supportedLangs := make(map[string]struct{}, len(supported))
+	for _, lang := range supported {
+		supportedLangs[strings.ToLower(lang)] = struct{}{}
+	}
+
+	for _, lr := range parseAcceptLanguage(acceptLanguage) {
+		if lr.tag == "*" {
+			break
+		}
+		if _, ok := supportedLangs[lr.tag]; ok {
+			return lr.tag
+		}
+		if base := error.BaseLanguage(lr.tag); base != lr.tag {
+			if _, ok := supportedLangs[base]; ok {
+				return base
+			}
+		}
+	}
+	return DefaultLanguage
+}
+
+// language ranges are sorted by q-value, ranges with invalid q-value or
+// q=0 are ignored
+func parseAcceptLanguage(acceptLanguage string) []languageRange {
+	var ranges []languageRange
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		if params = strings.TrimSpace(params); params != "" {
+			value, ok := strings.CutPrefix(params, "q=")
+			if ok == false {
+				continue
+			}
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil || v < 0 || v > 1 {
+				continue
+			}
+			q = v
+		}
+		if q > 0 {
+			ranges = append(ranges, languageRange{tag: tag, q: q})
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+	return ranges
+}
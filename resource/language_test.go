@@ -0,0 +1,29 @@
+package resource
+
+import (
+	"testing"
+
+	ut "github.com/linkingthing/cement/unittest"
+)
+
+func TestNegotiateLanguage(t *testing.T) {
+	supported := []string{"en", "ja", "ru", "zh"}
+	cases := []struct {
+		header string
+		lang   string
+	}{
+		{"", "en"},
+		{"zh-CN", "zh"},
+		{"ja;q=0.5, ru;q=0.9", "ru"},
+		{"fr, zh-CN;q=0.8", "zh"},
+		{"fr, zh;q=0", "en"},
+		{"ru;q=abc, ja", "ja"},
+		{"*", "en"},
+		{"fr, *;q=0.9, ja;q=0.5", "en"},
+		{"ZH-TW;q=0.3, JA;q=0.3", "zh"},
+	}
+
+	for _, c := range cases {
+		ut.Equal(t, NegotiateLanguage(c.header, supported), c.lang)
+	}
+}
@@ -0,0 +1,15 @@
+package resource
+
+type PatchType string
+
+const (
+	MergePatch PatchType = "application/merge-patch+json"
+	JSONPatch  PatchType = "application/json-patch+json"
+)
+
+// Data is the raw patch document which has been checked
+// according to Type
+type Patch struct {
+	Type PatchType
+	Data []byte
+}
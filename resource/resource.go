@@ -16,6 +16,7 @@ const (
 	UpdateLink     ResourceLinkType = "update"
 	RemoveLink     ResourceLinkType = "remove"
 	CollectionLink ResourceLinkType = "collection"
+	NextLink       ResourceLinkType = "next"
 )
 
 type Resource interface {
@@ -34,6 +35,9 @@ type Resource interface {
 	GetDeletionTimestamp() time.Time
 	SetDeletionTimestamp(time.Time)
 
+	GetResourceVersion() int64
+	SetResourceVersion(int64)
+
 	GetSchema() Schema
 	SetSchema(Schema)
 
@@ -43,6 +47,9 @@ type Resource interface {
 
 	GetAction() *Action
 	SetAction(*Action)
+
+	GetPatch() *Patch
+	SetPatch(*Patch)
 }
 
 // struct implement ResourceKind
@@ -54,12 +61,22 @@ type ResourceKind interface {
 	//NOTE: default field shouldn't include map
 	//json unmarshal will merge map, in this case
 	//when real data is provided, it will merge with
-	//default value
+	//default value, use rest tag default= for map,
+	//it's only set when the field isn't specified
 	CreateDefaultResource() Resource
 	GetActions() []Action
 	SupportAsyncDelete() bool
 }
 
+// ResourceValidator is optional for resource kind, it's used for the rules
+// across fields or resources, like endIP should be greater than startIP.
+// Validate is called after the rest tags are checked, parent and id of the
+// resource are set, method is POST, PUT or PATCH. return *APIError to
+// specify the error code, resourcefield.FieldError to specify the field
+type ResourceValidator interface {
+	Validate(method string) error
+}
+
 // lowercase singluar
 // eg: type Node struct -> node
 func DefaultKindName(t interface{}) string {
@@ -11,8 +11,10 @@ type ResourceBase struct {
 	Links             map[ResourceLinkType]ResourceLink `json:"links,omitempty"`
 	CreationTimestamp ISOTime                           `json:"creationTimestamp,omitempty"`
 	DeletionTimestamp ISOTime                           `json:"deletionTimestamp,omitempty"`
+	ResourceVersion   int64                             `json:"resourceVersion,omitempty"`
 
 	action *Action  `json:"-"`
+	patch  *Patch   `json:"-"`
 	parent Resource `json:"-"`
 	schema Schema   `json:"-"`
 }
@@ -67,6 +69,14 @@ func (r *ResourceBase) SetDeletionTimestamp(timestamp time.Time) {
 	r.DeletionTimestamp = ISOTime(timestamp)
 }
 
+func (r *ResourceBase) GetResourceVersion() int64 {
+	return r.ResourceVersion
+}
+
+func (r *ResourceBase) SetResourceVersion(version int64) {
+	r.ResourceVersion = version
+}
+
 func (r *ResourceBase) GetParent() Resource {
 	return r.parent
 }
@@ -91,6 +101,14 @@ func (r *ResourceBase) SetAction(action *Action) {
 	r.action = action
 }
 
+func (r *ResourceBase) GetPatch() *Patch {
+	return r.patch
+}
+
+func (r *ResourceBase) SetPatch(patch *Patch) {
+	r.patch = patch
+}
+
 func (r *ResourceBase) SetType(typ string) {
 	r.Type = typ
 }
@@ -6,7 +6,7 @@ import (
 
 type HttpMethod string
 
-var SupportedMethods = []HttpMethod{http.MethodGet, http.MethodPut, http.MethodDelete, http.MethodPost}
+var SupportedMethods = []HttpMethod{http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodPost}
 
 type ResourceRoute map[HttpMethod][]string
 
@@ -16,7 +16,9 @@ func NewResourceRoute() ResourceRoute {
 
 func (a ResourceRoute) Merge(b ResourceRoute) ResourceRoute {
 	for _, method := range SupportedMethods {
-		a[method] = append(a[method], b[method]...)
+		if paths, ok := b[method]; ok {
+			a[method] = append(a[method], paths...)
+		}
 	}
 	return a
 }
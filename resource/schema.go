@@ -14,9 +14,17 @@ type SchemaManager interface {
 	//for GET/ DELETE, return empty resource, with id and parent set,
 	//for POST and PUT, the resource unmarshal from body will be returned
 	//also support default value and validation check
+	//for PATCH, the patch document in body is checked and attached to the
+	//resource, it's applied to the current resource by Schema.ApplyPatch
 	CreateResourceFromRequest(*http.Request) (Resource, *goresterr.APIError)
 
-	//based on handler to generate route for the resources
+	//for GET /apis, /apis/{group}/{version} and /apis/{group}/{version}/schemas/{kind},
+	//return the discovery document, return nil if it isn't a discovery request
+	//or discovery is disabled
+	Discover(*http.Request) (interface{}, *goresterr.APIError)
+
+	//based on handler to generate route for the resources, and discovery if
+	//it's enabled
 	GenerateResourceRoute() ResourceRoute
 	WriteJsonDocs(v *APIVersion, path string) error
 }
@@ -25,5 +33,20 @@ type Schema interface {
 	GetHandler() Handler
 	AddLinksToResource(r Resource, httpSchemeAndHost string) error
 	AddLinksToResourceCollection(rs *ResourceCollection, httpSchemeAndHost string) error
+	//apply the patch attached to r to current, and validate the touched fields
+	ApplyPatch(r Resource, current Resource) (Resource, *goresterr.APIError)
+	//check fields in sparse fieldsets are json names of the resource
+	ValidateFields(fields []string) *goresterr.APIError
+	//check immutable fields of r aren't changed compare with current,
+	//which is used by update
+	HasImmutableFields() bool
+	CheckImmutableFields(r Resource, current Resource) *goresterr.APIError
+	//readOnly fields of r which are reset from request body get the value
+	//of current, which is used by update
+	HasReadOnlyFields() bool
+	RestoreReadOnlyFields(r Resource, current Resource)
 	WriteJsonDoc(path string) error
+	//convert resource with same kind name of other version to the kind of
+	//the schema, used to deliver events to watchers of all the versions
+	ConvertResource(r Resource) (Resource, error)
 }
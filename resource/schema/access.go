@@ -0,0 +1,118 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+	"github.com/linkingthing/gorest/resource/schema/resourcefield"
+)
+
+// readOnly fields in request body are reset to zero value, in strict mode
+// they are rejected, the fields are removed from raw to skip validation
+func (s *Schema) stripReadOnlyFields(r resource.Resource, raw map[string]interface{}, strict bool) *goresterr.APIError {
+	var errs resourcefield.FieldErrors
+	value := reflect.ValueOf(r)
+	for _, name := range s.access.ReadOnlyFields() {
+		if jsonVal, ok := raw[name]; ok == false || jsonVal == nil {
+			continue
+		}
+
+		if strict {
+			errs = append(errs, &resourcefield.FieldError{
+				Path: name,
+				Rule: resourcefield.RuleReadOnly,
+				Err:  fmt.Errorf("field is read only"),
+			})
+			continue
+		}
+
+		if field := s.access.FieldValue(value, name); field.IsValid() && field.CanSet() {
+			field.Set(reflect.Zero(field.Type()))
+		}
+		delete(raw, name)
+	}
+
+	if len(errs) > 0 {
+		return fieldValidateError(errs)
+	}
+	return nil
+}
+
+func (s *Schema) HasReadOnlyFields() bool {
+	return len(s.access.ReadOnlyFields()) > 0
+}
+
+// RestoreReadOnlyFields set readOnly fields of r to the value of current
+func (s *Schema) RestoreReadOnlyFields(r, current resource.Resource) {
+	value := reflect.ValueOf(r)
+	currentValue := reflect.ValueOf(current)
+	for _, name := range s.access.ReadOnlyFields() {
+		field := s.access.FieldValue(value, name)
+		old := s.access.FieldValue(currentValue, name)
+		if field.IsValid() && old.IsValid() && field.CanSet() && old.Type() == field.Type() {
+			field.Set(old)
+		}
+	}
+}
+
+func (s *Schema) HasImmutableFields() bool {
+	return len(s.access.ImmutableFields()) > 0
+}
+
+// CheckImmutableFields compare the immutable fields of r with current,
+// field with zero value in r is treated as not specified and gets the
+// value of current
+func (s *Schema) CheckImmutableFields(r, current resource.Resource) *goresterr.APIError {
+	var errs resourcefield.FieldErrors
+	value := reflect.ValueOf(r)
+	currentValue := reflect.ValueOf(current)
+	for _, name := range s.access.ImmutableFields() {
+		field := s.access.FieldValue(value, name)
+		old := s.access.FieldValue(currentValue, name)
+		if field.IsValid() == false || old.IsValid() == false {
+			continue
+		}
+
+		if field.IsZero() {
+			if field.CanSet() && old.Type() == field.Type() {
+				field.Set(old)
+			}
+			continue
+		}
+
+		if equal, err := jsonEqual(field.Interface(), old.Interface()); err != nil {
+			return goresterr.NewAPIError(goresterr.ServerError,
+				*goresterr.NewLocalizedErrorMessage("", goresterr.MessageCompareFieldFailed, goresterr.Params{"field": name, "reason": err.Error()}))
+		} else if equal == false {
+			errs = append(errs, &resourcefield.FieldError{
+				Path: name,
+				Rule: resourcefield.RuleImmutable,
+				Err:  fmt.Errorf("field can't be modified"),
+			})
+		}
+	}
+
+	if len(errs) > 0 {
+		return fieldValidateError(errs)
+	}
+	return nil
+}
+
+// values are compared with json encoding, current resource may be loaded
+// from database, values with same json are equal
+func jsonEqual(a, b interface{}) (bool, error) {
+	da, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+
+	db, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(da, db), nil
+}
@@ -0,0 +1,41 @@
+package schema
+
+import (
+	"os"
+	"path"
+
+	"github.com/linkingthing/gorest/resource"
+	"github.com/linkingthing/gorest/resource/schema/clientgen"
+)
+
+// GenerateClient return go source of the typed client for the version
+func (m *SchemaManager) GenerateClient(v *resource.APIVersion, pkgName string) ([]byte, error) {
+	vs := m.getVersionedSchemas(v)
+	if vs == nil {
+		vs = NewVersionedSchemas(v)
+	}
+
+	api := &clientgen.API{Version: *v}
+	for _, schema := range getSchemas(vs) {
+		doc, err := schema.ResourceDocument()
+		if err != nil {
+			return nil, err
+		}
+		api.Resources = append(api.Resources, doc)
+	}
+	return clientgen.Generate(pkgName, api)
+}
+
+// the client is written to the file named with group and version, like
+// testing_v1_client.go
+func (m *SchemaManager) WriteClient(v *resource.APIVersion, pkgName, targetPath string) error {
+	src, err := m.GenerateClient(v, pkgName)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(targetPath, os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(path.Join(targetPath, clientgen.FileName(*v)), src, 0644)
+}
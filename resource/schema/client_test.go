@@ -0,0 +1,31 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	ut "github.com/linkingthing/cement/unittest"
+)
+
+func TestGenerateClient(t *testing.T) {
+	mgr := createSchemaManager()
+	mgr.MustImport(&version, Volume{}, &volumeHandler{})
+	src, err := mgr.GenerateClient(&version, "testingv1")
+	ut.Assert(t, err == nil, "generate client failed:%v", err)
+
+	code := string(src)
+	for _, decl := range []string{
+		"const APIVersionPath = \"/apis/testing/v1\"",
+		"func (c *Client) Clusters() ClusterClient",
+		"func (c NameSpaceClient) Deployments(nameSpaceID string) DeploymentClient",
+		"func (c DeploymentClient) Pods(deploymentID string) PodClient",
+		"func (c DaemonSetClient) Pods(daemonSetID string) PodClient",
+		"func (c PodClient) Move(ctx context.Context, id string, input *PodMoveInput) error",
+		"func (c VolumeClient) Get(ctx context.Context, id string, opts *client.GetOptions) (*Volume, error)",
+		"type OtherPodInfo struct",
+	} {
+		ut.Assert(t, strings.Contains(code, decl), "%s isn't generated", decl)
+	}
+	ut.Assert(t, strings.Contains(code, "func (c VolumeClient) List") == false, "")
+	ut.Equal(t, strings.Count(code, "type Pod struct"), 1)
+}
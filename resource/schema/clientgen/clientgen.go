@@ -0,0 +1,411 @@
+package clientgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"net/http"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/linkingthing/gorest/resource"
+	"github.com/linkingthing/gorest/resource/schema/resourcedoc"
+	"github.com/linkingthing/gorest/util"
+)
+
+const (
+	resourceBaseField = "ResourceBase"
+	resourceBaseType  = "resourceBase"
+
+	typeDate = "date"
+	typeJson = "json"
+)
+
+// API is the input of the generator, resources are the documents of the
+// resources in one api version, which can be generated by SchemaManager
+// or fetched from the discovery endpoints
+type API struct {
+	Version   resource.APIVersion
+	Resources []*resourcedoc.ResourceDocument
+}
+
+type generator struct {
+	api       *API
+	buf       bytes.Buffer
+	resources map[string]*resourcedoc.ResourceDocument
+	children  map[string][]*resourcedoc.ResourceDocument
+	structs   map[string]resourcedoc.ResourceFields
+	//names of the generated types to avoid duplicate declaration
+	types    map[string]struct{}
+	useJson  bool
+	useTime  bool
+	useNetip bool
+}
+
+// Generate return the go source of the typed client for the api version,
+// each resource kind gets a struct and a client with the methods supported
+// by its handler, clients of children are got from the client of parent
+// with parent id
+func Generate(pkgName string, api *API) ([]byte, error) {
+	g := &generator{
+		api:       api,
+		resources: make(map[string]*resourcedoc.ResourceDocument),
+		children:  make(map[string][]*resourcedoc.ResourceDocument),
+		structs:   make(map[string]resourcedoc.ResourceFields),
+		types:     make(map[string]struct{}),
+	}
+
+	var resources []*resourcedoc.ResourceDocument
+	for _, r := range api.Resources {
+		if _, ok := g.resources[r.ResourceType]; ok {
+			continue
+		}
+		g.resources[r.ResourceType] = r
+		g.types[r.GoStructName] = struct{}{}
+		resources = append(resources, r)
+	}
+
+	for _, r := range resources {
+		for _, parent := range r.ParentResources {
+			if _, ok := g.resources[parent]; ok == false {
+				return nil, fmt.Errorf("parent %s of %s is unknown", parent, r.ResourceType)
+			}
+			g.children[parent] = append(g.children[parent], r)
+		}
+	}
+
+	g.printf("const APIVersionPath = %q\n\n", api.Version.GetUrl())
+	g.printf("type Client struct {\nc *client.Client\n}\n\n")
+	g.printf("func NewClient(c *client.Client) *Client {\nreturn &Client{c: c}\n}\n\n")
+	for _, r := range resources {
+		if len(r.ParentResources) == 0 {
+			g.printf("func (c *Client) %s() %sClient {\n", pluralGoName(r), r.GoStructName)
+			g.printf("return %sClient{c: c.c, path: APIVersionPath + %q}\n}\n\n", r.GoStructName, "/"+r.CollectionName)
+		}
+	}
+
+	for _, r := range resources {
+		if err := g.generateResource(r); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := g.generateStructs(); err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(append(g.header(pkgName), g.buf.Bytes()...))
+	if err != nil {
+		return nil, fmt.Errorf("format generated code failed: %s", err.Error())
+	}
+	return src, nil
+}
+
+// packages are imported only if they are used
+func (g *generator) header(pkgName string) []byte {
+	var header bytes.Buffer
+	fmt.Fprintf(&header, "// Code generated by gorest client generator. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&header, "package %s\n\nimport (\n", pkgName)
+	if len(g.resources) > 0 {
+		fmt.Fprintf(&header, "\"context\"\n")
+	}
+	if g.useJson {
+		fmt.Fprintf(&header, "\"encoding/json\"\n")
+	}
+	if g.useNetip {
+		fmt.Fprintf(&header, "\"net/netip\"\n")
+	}
+	if g.useTime {
+		fmt.Fprintf(&header, "\"time\"\n")
+	}
+	fmt.Fprintf(&header, "\n\"github.com/linkingthing/gorest/client\"\n")
+	if len(g.resources) > 0 {
+		fmt.Fprintf(&header, "\"github.com/linkingthing/gorest/resource\"\n")
+	}
+	fmt.Fprintf(&header, ")\n\n")
+	return header.Bytes()
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) generateResource(r *resourcedoc.ResourceDocument) error {
+	g.addStructs(r.SubResources)
+	if err := g.generateStruct(r.GoStructName, r.ResourceFields, true); err != nil {
+		return fmt.Errorf("generate resource %s failed: %s", r.ResourceType, err.Error())
+	}
+
+	name := r.GoStructName
+	clientName := name + "Client"
+	methods := make(map[string]struct{})
+	addMethod := func(method string) error {
+		if _, ok := methods[method]; ok {
+			return fmt.Errorf("method %s of %s is duplicate", method, clientName)
+		}
+		methods[method] = struct{}{}
+		return nil
+	}
+
+	g.printf("type %s struct {\nc *client.Client\npath string\n}\n\n", clientName)
+	if slices.Contains(r.CollectionMethods, http.MethodGet) {
+		addMethod("List")
+		g.printf("func (c %s) List(ctx context.Context, opts *client.ListOptions) (*client.Collection[%s], error) {\n", clientName, name)
+		g.printf("return client.List[%s](ctx, c.c, c.path, opts)\n}\n\n", name)
+	}
+	if slices.Contains(r.CollectionMethods, http.MethodPost) {
+		addMethod("Create")
+		g.printf("func (c %s) Create(ctx context.Context, r *%s) (*%s, error) {\n", clientName, name, name)
+		g.printf("return client.Create(ctx, c.c, c.path, r)\n}\n\n")
+	}
+	if slices.Contains(r.ResourceMethods, http.MethodGet) {
+		addMethod("Get")
+		g.printf("func (c %s) Get(ctx context.Context, id string, opts *client.GetOptions) (*%s, error) {\n", clientName, name)
+		g.printf("return client.Get[%s](ctx, c.c, c.path, id, opts)\n}\n\n", name)
+	}
+	if slices.Contains(r.ResourceMethods, http.MethodPut) {
+		addMethod("Update")
+		g.printf("func (c %s) Update(ctx context.Context, r *%s) (*%s, error) {\n", clientName, name, name)
+		g.printf("return client.Update(ctx, c.c, c.path, r.ID, r)\n}\n\n")
+	}
+	if slices.Contains(r.ResourceMethods, http.MethodPatch) {
+		addMethod("Patch")
+		g.printf("func (c %s) Patch(ctx context.Context, id string, patch interface{}) (*%s, error) {\n", clientName, name)
+		g.printf("return client.Patch[%s](ctx, c.c, c.path, id, patch)\n}\n\n", name)
+	}
+	if slices.Contains(r.ResourceMethods, http.MethodDelete) {
+		addMethod("Delete")
+		g.printf("func (c %s) Delete(ctx context.Context, id string) error {\n", clientName)
+		g.printf("return client.Delete(ctx, c.c, c.path, id)\n}\n\n")
+	}
+
+	if slices.Contains(r.ResourceMethods, http.MethodPost) {
+		for _, action := range r.ResourceActions {
+			method := goName(action.Name)
+			if err := addMethod(method); err != nil {
+				return err
+			}
+			if err := g.generateAction(r, action, clientName, method); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, child := range g.children[r.ResourceType] {
+		method := pluralGoName(child)
+		if err := addMethod(method); err != nil {
+			return err
+		}
+		g.printf("func (c %s) %s(%sID string) %sClient {\n", clientName, method, lowerFirst(name), child.GoStructName)
+		g.printf("return %sClient{c: c.c, path: client.JoinPath(c.path, %sID, %q)}\n}\n\n",
+			child.GoStructName, lowerFirst(name), child.CollectionName)
+	}
+	return nil
+}
+
+// input and output of action are named with resource and action name,
+// like PodMoveInput
+func (g *generator) generateAction(r *resourcedoc.ResourceDocument, action resourcedoc.ResourceAction, clientName, method string) error {
+	g.addStructs(action.SubResources)
+	params := "ctx context.Context, id string"
+	input := "nil"
+	if action.Input != nil {
+		inputName := r.GoStructName + method + "Input"
+		if err := g.generateStruct(inputName, action.Input, false); err != nil {
+			return fmt.Errorf("generate input of action %s failed: %s", action.Name, err.Error())
+		}
+		params += ", input *" + inputName
+		input = "input"
+	}
+
+	if action.Output == nil && action.OutputType == nil {
+		g.printf("func (c %s) %s(%s) error {\n", clientName, method, params)
+		g.printf("return client.Action(ctx, c.c, c.path, id, %q, %s, nil)\n}\n\n", action.Name, input)
+		return nil
+	}
+
+	outputName, err := g.actionOutputType(r.GoStructName+method+"Output", action)
+	if err != nil {
+		return fmt.Errorf("generate output of action %s failed: %s", action.Name, err.Error())
+	}
+	g.printf("func (c %s) %s(%s) (*%s, error) {\n", clientName, method, params, outputName)
+	g.printf("var output %s\n", outputName)
+	g.printf("if err := client.Action(ctx, c.c, c.path, id, %q, %s, &output); err != nil {\nreturn nil, err\n}\n", action.Name, input)
+	g.printf("return &output, nil\n}\n\n")
+	return nil
+}
+
+// struct output is generated with the name, and slice or map of struct
+// use it as element type
+func (g *generator) actionOutputType(name string, action resourcedoc.ResourceAction) (string, error) {
+	if action.Output != nil {
+		if err := g.generateStruct(name, action.Output, false); err != nil {
+			return "", err
+		}
+	}
+
+	if action.OutputType == nil {
+		return name, nil
+	}
+	switch action.OutputType.Type {
+	case resourcedoc.Array:
+		if action.Output != nil {
+			return "[]*" + name, nil
+		}
+	case resourcedoc.Map:
+		if action.Output != nil {
+			return "map[string]*" + name, nil
+		}
+	default:
+		if action.Output != nil {
+			return name, nil
+		}
+	}
+	return g.goType(*action.OutputType)
+}
+
+func (g *generator) addStructs(subResources map[string]resourcedoc.ResourceFields) {
+	for name, fields := range subResources {
+		if name == resourceBaseType {
+			continue
+		}
+		if _, ok := g.structs[name]; ok == false {
+			g.structs[name] = fields
+		}
+	}
+}
+
+// structs which are shared by resources and actions are generated in
+// name order
+func (g *generator) generateStructs() error {
+	names := make([]string, 0, len(g.structs))
+	for name := range g.structs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		typeName := goName(name)
+		if _, ok := g.types[typeName]; ok {
+			continue
+		}
+		if err := g.generateStruct(typeName, g.structs[name], false); err != nil {
+			return fmt.Errorf("generate struct %s failed: %s", typeName, err.Error())
+		}
+	}
+	return nil
+}
+
+func (g *generator) generateStruct(name string, fields resourcedoc.ResourceFields, isResource bool) error {
+	g.types[name] = struct{}{}
+	jsonNames := make([]string, 0, len(fields))
+	for jsonName := range fields {
+		jsonNames = append(jsonNames, jsonName)
+	}
+	sort.Strings(jsonNames)
+
+	g.printf("type %s struct {\n", name)
+	if isResource {
+		g.printf("resource.ResourceBase `json:\",inline\"`\n")
+	}
+	for _, jsonName := range jsonNames {
+		field := fields[jsonName]
+		if field.Type == resourceBaseType && jsonName == resourceBaseField {
+			continue
+		}
+
+		typ, err := g.goType(field)
+		if err != nil {
+			return fmt.Errorf("field %s: %s", jsonName, err.Error())
+		}
+		if field.Pointer {
+			typ = "*" + typ
+		}
+		jsonTag := jsonName
+		if field.OmitEmpty {
+			jsonTag += ",omitempty"
+		}
+		g.printf("%s %s `json:\"%s\"`\n", goName(jsonName), typ, jsonTag)
+	}
+	g.printf("}\n\n")
+	return nil
+}
+
+func (g *generator) goType(field resourcedoc.ResourceField) (string, error) {
+	switch field.Type {
+	case resourcedoc.Array:
+		elem, err := g.elemGoType(field.ElemType)
+		return "[]" + elem, err
+	case resourcedoc.Map:
+		value, err := g.elemGoType(field.ValueType)
+		return "map[string]" + value, err
+	case resourcedoc.Enum:
+		return string(util.String), nil
+	default:
+		return g.elemGoType(field.Type)
+	}
+}
+
+// type which isn't a known struct, like named string, is kept as raw json
+func (g *generator) elemGoType(typ string) (string, error) {
+	switch typ {
+	case string(util.String), string(util.Int), string(util.Uint), string(util.Bool):
+		return typ, nil
+	case string(util.Float):
+		return "float64", nil
+	case string(util.Time):
+		g.useTime = true
+		return "time.Time", nil
+	case string(util.IP):
+		g.useNetip = true
+		return "netip.Addr", nil
+	case typeDate:
+		return "resource.ISOTime", nil
+	case resourcedoc.Unknow:
+		return "", fmt.Errorf("unknown type")
+	}
+
+	if _, ok := g.structs[typ]; ok && typ != typeJson {
+		return goName(typ), nil
+	}
+	g.useJson = true
+	return "json.RawMessage", nil
+}
+
+func pluralGoName(r *resourcedoc.ResourceDocument) string {
+	return util.GuessPluralName(r.GoStructName)
+}
+
+// goName convert json name to exported go name, characters which aren't
+// letter or digit are used as word separator, like node_name to NodeName
+func goName(name string) string {
+	var sb strings.Builder
+	upper := true
+	for _, c := range name {
+		if unicode.IsLetter(c) == false && unicode.IsDigit(c) == false {
+			upper = true
+			continue
+		}
+		if sb.Len() == 0 && unicode.IsDigit(c) {
+			sb.WriteString("F")
+		}
+		if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String()
+}
+
+func lowerFirst(name string) string {
+	return resourcedoc.LowerFirstCharacter(name)
+}
+
+// file name of the generated client, like testing_v1_client.go
+func FileName(v resource.APIVersion) string {
+	return path.Base(v.Group) + "_" + v.Version + "_client.go"
+}
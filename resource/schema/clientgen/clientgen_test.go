@@ -0,0 +1,211 @@
+package clientgen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	ut "github.com/linkingthing/cement/unittest"
+	"github.com/linkingthing/gorest/resource"
+	"github.com/linkingthing/gorest/resource/schema/resourcedoc"
+)
+
+func testAPI() *API {
+	return &API{
+		Version: resource.APIVersion{Group: "testing", Version: "v1"},
+		Resources: []*resourcedoc.ResourceDocument{
+			{
+				ResourceType:      "cluster",
+				CollectionName:    "clusters",
+				GoStructName:      "Cluster",
+				ResourceFields:    resourcedoc.ResourceFields{"name": {Type: "string"}},
+				ResourceMethods:   []resource.HttpMethod{"GET", "DELETE", "POST"},
+				CollectionMethods: []resource.HttpMethod{"GET", "POST"},
+				ResourceActions: []resourcedoc.ResourceAction{{
+					Name:   "upgrade",
+					Input:  resourcedoc.ResourceFields{"version": {Type: "string"}},
+					Output: resourcedoc.ResourceFields{"nodes": {Type: "array", ElemType: "nodeInfo"}},
+					SubResources: map[string]resourcedoc.ResourceFields{
+						"nodeInfo": {"node_name": {Type: "string"}},
+					},
+				}, {
+					Name:         "listNodes",
+					Output:       resourcedoc.ResourceFields{"name": {Type: "string"}},
+					OutputType:   &resourcedoc.ResourceField{Type: "array", ElemType: "nodeName"},
+					SubResources: map[string]resourcedoc.ResourceFields{},
+				}, {
+					Name:         "count",
+					OutputType:   &resourcedoc.ResourceField{Type: "int"},
+					SubResources: map[string]resourcedoc.ResourceFields{},
+				}},
+			},
+			{
+				ResourceType:    "node",
+				CollectionName:  "nodes",
+				ParentResources: []string{"cluster"},
+				GoStructName:    "Node",
+				ResourceFields: resourcedoc.ResourceFields{
+					"ResourceBase": {Type: "resourceBase"},
+					"labels":       {Type: "map", KeyType: "string", ValueType: "string"},
+					"role":         {Type: "enum", ValidValues: []string{"master", "worker"}},
+					"cpu":          {Type: "uint"},
+					"info":         {Type: "json"},
+					"bootTime":     {Type: "date"},
+					"addresses":    {Type: "array", ElemType: "address"},
+					"load":         {Type: "float"},
+					"lastSeen":     {Type: "time"},
+					"dnsServers":   {Type: "array", ElemType: "ip"},
+					"spec":         {Type: "nodeSpec", Pointer: true, OmitEmpty: true},
+					"comment":      {Type: "string", Pointer: true},
+				},
+				SubResources: map[string]resourcedoc.ResourceFields{
+					"resourceBase": {"id": {Type: "string"}},
+					"address":      {"ip": {Type: "string"}},
+					"nodeSpec":     {"os": {Type: "string"}},
+				},
+				ResourceMethods: []resource.HttpMethod{"GET", "PUT", "PATCH"},
+			},
+		},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	src, err := Generate("testingv1", testAPI())
+	ut.Assert(t, err == nil, "generate failed:%v", err)
+
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	ut.Assert(t, err == nil, "generated code is invalid:%v", err)
+	ut.Equal(t, file.Name.Name, "testingv1")
+
+	funcs := make(map[string]*ast.FuncDecl)
+	structs := make(map[string]*ast.StructType)
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			name := decl.Name.Name
+			if decl.Recv != nil {
+				name = strings.TrimPrefix(exprString(decl.Recv.List[0].Type), "*") + "." + name
+			}
+			funcs[name] = decl
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				if spec, ok := spec.(*ast.TypeSpec); ok {
+					structs[spec.Name.Name] = spec.Type.(*ast.StructType)
+				}
+			}
+		}
+	}
+
+	for _, name := range []string{
+		"NewClient", "Client.Clusters",
+		"ClusterClient.List", "ClusterClient.Create", "ClusterClient.Get", "ClusterClient.Delete",
+		"ClusterClient.Upgrade", "ClusterClient.Nodes",
+		"NodeClient.Get", "NodeClient.Update", "NodeClient.Patch",
+	} {
+		_, ok := funcs[name]
+		ut.Assert(t, ok, "%s isn't generated", name)
+	}
+	for _, name := range []string{"ClusterClient.Update", "ClusterClient.Patch", "NodeClient.List", "NodeClient.Delete", "Client.Nodes"} {
+		_, ok := funcs[name]
+		ut.Assert(t, ok == false, "%s shouldn't be generated", name)
+	}
+
+	for _, name := range []string{"Cluster", "ClusterClient", "ClusterUpgradeInput", "ClusterUpgradeOutput", "NodeInfo", "Node", "NodeClient", "Address", "NodeSpec"} {
+		_, ok := structs[name]
+		ut.Assert(t, ok, "struct %s isn't generated", name)
+	}
+	_, ok := structs["ResourceBase"]
+	ut.Assert(t, ok == false, "")
+
+	fieldTypes := make(map[string]string)
+	for _, field := range structs["Node"].Fields.List {
+		if len(field.Names) == 0 {
+			fieldTypes[""] = exprString(field.Type)
+		} else {
+			fieldTypes[field.Names[0].Name] = exprString(field.Type)
+		}
+	}
+	ut.Equal(t, fieldTypes, map[string]string{
+		"":           "resource.ResourceBase",
+		"Addresses":  "[]Address",
+		"BootTime":   "resource.ISOTime",
+		"Comment":    "*string",
+		"Cpu":        "uint",
+		"DnsServers": "[]netip.Addr",
+		"Info":       "json.RawMessage",
+		"Labels":     "map[string]string",
+		"LastSeen":   "time.Time",
+		"Load":       "float64",
+		"Role":       "string",
+		"Spec":       "*NodeSpec",
+	})
+	ut.Equal(t, structs["NodeInfo"].Fields.List[0].Names[0].Name, "NodeName")
+
+	for name, output := range map[string]string{
+		"ClusterClient.Upgrade":   "*ClusterUpgradeOutput",
+		"ClusterClient.ListNodes": "*[]*ClusterListNodesOutput",
+		"ClusterClient.Count":     "*int",
+	} {
+		fn, ok := funcs[name]
+		ut.Assert(t, ok, "%s isn't generated", name)
+		ut.Equal(t, exprString(fn.Type.Results.List[0].Type), output)
+	}
+}
+
+// generated package is type checked with the client package
+func TestGeneratedCodeTypeCheck(t *testing.T) {
+	src, err := Generate("testingv1", testAPI())
+	ut.Assert(t, err == nil, "generate failed:%v", err)
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, FileName(testAPI().Version), src, 0)
+	ut.Assert(t, err == nil, "generated code is invalid:%v", err)
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("testingv1", fset, []*ast.File{file}, nil)
+	ut.Assert(t, err == nil, "generated code doesn't compile:%v", err)
+
+	node := pkg.Scope().Lookup("Node").Type()
+	patch, _, _ := types.LookupFieldOrMethod(pkg.Scope().Lookup("NodeClient").Type(), false, pkg, "Patch")
+	ut.Assert(t, patch != nil, "patch of node isn't generated")
+	ut.Equal(t, patch.Type().(*types.Signature).Results().At(0).Type().String(), "*testingv1.Node")
+
+	nodeStruct := node.Underlying().(*types.Struct)
+	tags := make(map[string]string)
+	fieldTypes := make(map[string]string)
+	for i := 0; i < nodeStruct.NumFields(); i++ {
+		tags[nodeStruct.Field(i).Name()] = nodeStruct.Tag(i)
+		fieldTypes[nodeStruct.Field(i).Name()] = nodeStruct.Field(i).Type().String()
+	}
+	ut.Equal(t, fieldTypes["Spec"], "*testingv1.NodeSpec")
+	ut.Equal(t, fieldTypes["Comment"], "*string")
+	ut.Equal(t, tags["Spec"], `json:"spec,omitempty"`)
+	ut.Equal(t, tags["Comment"], `json:"comment"`)
+}
+
+func TestGenerateUnknownParent(t *testing.T) {
+	api := testAPI()
+	api.Resources = api.Resources[1:]
+	_, err := Generate("testingv1", api)
+	ut.Assert(t, err != nil, "")
+}
+
+func exprString(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.StarExpr:
+		return "*" + exprString(expr.X)
+	case *ast.SelectorExpr:
+		return exprString(expr.X) + "." + expr.Sel.Name
+	case *ast.ArrayType:
+		return "[]" + exprString(expr.Elt)
+	case *ast.MapType:
+		return "map[" + exprString(expr.Key) + "]" + exprString(expr.Value)
+	default:
+		return ""
+	}
+}
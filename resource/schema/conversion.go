@@ -0,0 +1,255 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+)
+
+// conversionHandler call the handler of hub kind, resource in context is
+// converted to hub kind with the hub schema before calling and restored
+// after, the returned resources are converted back
+type conversionHandler struct {
+	hub       resource.Handler
+	hubSchema *Schema
+	kind      reflect.Type
+	converter resource.Converter
+}
+
+var _ resource.Handler = &conversionHandler{}
+
+func (h *conversionHandler) toHub(ctx *resource.Context) (func(), *goresterr.APIError) {
+	r := ctx.Resource
+	hub, err := resource.ConvertResource(r, h.converter.ToHub)
+	if err != nil {
+		return nil, conversionError(r, err)
+	}
+	hub.SetSchema(h.hubSchema)
+	ctx.Resource = hub
+	return func() { ctx.Resource = r }, nil
+}
+
+func (h *conversionHandler) fromHub(ctx *resource.Context, hub resource.Resource) (resource.Resource, *goresterr.APIError) {
+	if hub == nil || (reflect.ValueOf(hub).Kind() == reflect.Ptr && reflect.ValueOf(hub).IsNil()) {
+		return nil, nil
+	}
+
+	r, err := resource.ConvertResource(hub, h.converter.FromHub)
+	if err != nil {
+		return nil, conversionError(hub, err)
+	}
+	r.SetSchema(ctx.Resource.GetSchema())
+	return r, nil
+}
+
+func conversionError(r resource.Resource, err error) *goresterr.APIError {
+	return goresterr.NewAPIError(goresterr.ServerError,
+		*goresterr.NewLocalizedErrorMessage("", goresterr.MessageConvertFailed, goresterr.Params{"type": r.GetType(), "reason": err.Error()}))
+}
+
+// resource handlers with same signature share the wrapper
+func (h *conversionHandler) wrapResourceHandler(handler func(*resource.Context) (resource.Resource, *goresterr.APIError)) func(*resource.Context) (resource.Resource, *goresterr.APIError) {
+	return func(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+		restore, err := h.toHub(ctx)
+		if err != nil {
+			return nil, err
+		}
+		hub, err := handler(ctx)
+		restore()
+		if err != nil {
+			return nil, err
+		}
+		return h.fromHub(ctx, hub)
+	}
+}
+
+func (h *conversionHandler) GetCreateHandler() resource.CreateHandler {
+	if handler := h.hub.GetCreateHandler(); handler != nil {
+		return h.wrapResourceHandler(handler)
+	}
+	return nil
+}
+
+func (h *conversionHandler) GetUpdateHandler() resource.UpdateHandler {
+	if handler := h.hub.GetUpdateHandler(); handler != nil {
+		return h.wrapResourceHandler(handler)
+	}
+	return nil
+}
+
+func (h *conversionHandler) GetPatchHandler() resource.PatchHandler {
+	if handler := h.hub.GetPatchHandler(); handler != nil {
+		return h.wrapResourceHandler(handler)
+	}
+	return nil
+}
+
+func (h *conversionHandler) GetGetHandler() resource.GetHandler {
+	if handler := h.hub.GetGetHandler(); handler != nil {
+		return h.wrapResourceHandler(handler)
+	}
+	return nil
+}
+
+func (h *conversionHandler) GetDeleteHandler() resource.DeleteHandler {
+	handler := h.hub.GetDeleteHandler()
+	if handler == nil {
+		return nil
+	}
+
+	return func(ctx *resource.Context) *goresterr.APIError {
+		restore, err := h.toHub(ctx)
+		if err != nil {
+			return err
+		}
+		defer restore()
+		return handler(ctx)
+	}
+}
+
+func (h *conversionHandler) GetActionHandler() resource.ActionHandler {
+	handler := h.hub.GetActionHandler()
+	if handler == nil {
+		return nil
+	}
+
+	return func(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+		restore, err := h.toHub(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer restore()
+		return handler(ctx)
+	}
+}
+
+// resources in the returned slice are converted one by one, and put into
+// a slice of the kind to keep the same shape with other list handlers
+func (h *conversionHandler) GetListHandler() resource.ListHandler {
+	handler := h.hub.GetListHandler()
+	if handler == nil {
+		return nil
+	}
+
+	return func(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+		restore, err := h.toHub(ctx)
+		if err != nil {
+			return nil, err
+		}
+		data, err := handler(ctx)
+		restore()
+		if err != nil || data == nil {
+			return data, err
+		}
+
+		hubs := reflect.ValueOf(data)
+		if hubs.Kind() != reflect.Slice {
+			return data, nil
+		}
+
+		rs := reflect.MakeSlice(reflect.SliceOf(reflect.PointerTo(h.kind)), 0, hubs.Len())
+		for i := 0; i < hubs.Len(); i++ {
+			hub, ok := hubs.Index(i).Interface().(resource.Resource)
+			if ok == false {
+				return nil, goresterr.NewAPIError(goresterr.ServerError,
+					*goresterr.NewLocalizedErrorMessage("", goresterr.MessageInvalidListResult, goresterr.Params{"reason": fmt.Sprintf("%T isn't resource", hubs.Index(i).Interface())}))
+			}
+
+			r, err := h.fromHub(ctx, hub)
+			if err != nil {
+				return nil, err
+			}
+			if r == nil || reflect.TypeOf(r) != rs.Type().Elem() {
+				return nil, conversionError(hub, fmt.Errorf("result isn't %s", rs.Type().Elem().String()))
+			}
+			rs = reflect.Append(rs, reflect.ValueOf(r))
+		}
+		return rs.Interface(), nil
+	}
+}
+
+// ConvertResource convert resource with the same kind name from other
+// version to the kind of the schema through the hub kind
+func (s *Schema) ConvertResource(r resource.Resource) (resource.Resource, error) {
+	if reflect.TypeOf(r) == reflect.PointerTo(reflect.TypeOf(s.resourceKind)) {
+		return r, nil
+	}
+
+	other, ok := r.GetSchema().(*Schema)
+	if ok == false || other == nil || other.resourceKindName != s.resourceKindName {
+		return nil, fmt.Errorf("resource %T can't be converted to %s", r, s.resourceKindName)
+	}
+
+	hub := r
+	if other.converter != nil {
+		var err error
+		if hub, err = resource.ConvertResource(r, other.converter.ToHub); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.converter == nil {
+		if reflect.TypeOf(hub) != reflect.PointerTo(reflect.TypeOf(s.resourceKind)) {
+			return nil, fmt.Errorf("resource %T and %s have no same hub kind", r, s.resourceKindName)
+		}
+		hub.SetSchema(s)
+		return hub, nil
+	}
+
+	converted, err := resource.ConvertResource(hub, s.converter.FromHub)
+	if err != nil {
+		return nil, err
+	}
+	converted.SetSchema(s)
+	return converted, nil
+}
+
+// ImportConversion import kind whose requests are handled by the handler
+// of hub kind which is imported to hub version, the kind and hub should
+// have same kind name
+func (m *SchemaManager) ImportConversion(v *resource.APIVersion, kind resource.ResourceKind, hubVersion *resource.APIVersion, hub resource.ResourceKind, converter resource.Converter) error {
+	if converter.ToHub == nil || converter.FromHub == nil {
+		return fmt.Errorf("converter of %s is incomplete", resource.DefaultKindName(kind))
+	}
+
+	if resource.DefaultKindName(kind) != resource.DefaultKindName(hub) {
+		return fmt.Errorf("kind %s and hub %s have different name", resource.DefaultKindName(kind), resource.DefaultKindName(hub))
+	}
+
+	hubVersionedSchemas := m.getVersionedSchemas(hubVersion)
+	if hubVersionedSchemas == nil {
+		return fmt.Errorf("hub version %s hasn't been imported", hubVersion.GetUrl())
+	}
+	hubSchema := hubVersionedSchemas.GetSchema(hub)
+	if hubSchema == nil || hubSchema.converter != nil ||
+		reflect.TypeOf(hubSchema.resourceKind) != reflect.TypeOf(hub) {
+		return fmt.Errorf("hub %s hasn't been imported to %s", resource.DefaultKindName(hub), hubVersion.GetUrl())
+	}
+
+	handler := &conversionHandler{
+		hub:       hubSchema.handler,
+		hubSchema: hubSchema,
+		kind:      reflect.TypeOf(kind),
+		converter: converter,
+	}
+
+	vs := m.getVersionedSchemas(v)
+	if vs == nil {
+		vs = NewVersionedSchemas(v)
+		m.schemas = append(m.schemas, vs)
+	}
+	schema, err := vs.importSchema(kind, handler)
+	if err != nil {
+		return err
+	}
+	schema.converter = &converter
+	return nil
+}
+
+func (m *SchemaManager) MustImportConversion(v *resource.APIVersion, kind resource.ResourceKind, hubVersion *resource.APIVersion, hub resource.ResourceKind, converter resource.Converter) {
+	if err := m.ImportConversion(v, kind, hubVersion, hub, converter); err != nil {
+		panic(fmt.Sprintf("import %s failed: %s", resource.DefaultKindName(kind), err.Error()))
+	}
+}
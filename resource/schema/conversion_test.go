@@ -0,0 +1,95 @@
+package schema
+
+import (
+	"testing"
+
+	ut "github.com/linkingthing/cement/unittest"
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+)
+
+var version2 = resource.APIVersion{
+	Group:   "testing",
+	Version: "v2",
+}
+
+func TestImportConversion(t *testing.T) {
+	type Node2 = Node
+	type Node struct {
+		resource.ResourceBase
+		Hostname string
+	}
+	converter := resource.NewConverter(
+		func(n *Node) (*Node2, error) { return &Node2{Name: n.Hostname}, nil },
+		func(n *Node2) (*Node, error) { return &Node{Hostname: n.Name}, nil })
+
+	mgr := createSchemaManager()
+	ut.Assert(t, mgr.ImportConversion(&version2, Node{}, &version2, Node2{}, converter) != nil, "hub version isn't imported")
+	ut.Assert(t, mgr.ImportConversion(&version2, Node{}, &version, Cluster{}, converter) != nil, "different kind name")
+	ut.Assert(t, mgr.ImportConversion(&version2, Node{}, &version, Node2{}, resource.Converter{}) != nil, "empty converter")
+	ut.Assert(t, mgr.ImportConversion(&version2, Node{}, &version, Node2{}, converter) == nil, "")
+	ut.Assert(t, mgr.ImportConversion(&version2, Node{}, &version, Node2{}, converter) != nil, "duplicate import")
+	node2 := mgr.GetSchema(&version2, Node{}).(*Schema)
+	ut.Equal(t, resource.GetResourceMethods(node2.GetHandler()),
+		resource.GetResourceMethods(mgr.GetSchema(&version, Node2{}).(*Schema).GetHandler()))
+
+	//convert resource between versions
+	node1 := mgr.GetSchema(&version, Node2{}).(*Schema)
+	hub := &Node2{Name: "n1"}
+	hub.SetID("n1")
+	hub.SetSchema(node1)
+	r, err := node2.ConvertResource(hub)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, r.(*Node).Hostname, "n1")
+	ut.Equal(t, r.GetID(), "n1")
+	ut.Assert(t, r.GetSchema() == node2, "")
+
+	back, err := node1.ConvertResource(r)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, back.(*Node2).Name, "n1")
+
+	same, err := node1.ConvertResource(hub)
+	ut.Assert(t, err == nil && same == hub, "")
+
+	cluster := &Cluster{}
+	cluster.SetSchema(mgr.GetSchema(&version, Cluster{}))
+	_, err = node2.ConvertResource(cluster)
+	ut.Assert(t, err != nil, "")
+}
+
+type gatewayHandler struct {
+	schema resource.Schema
+}
+
+func (h *gatewayHandler) Get(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	h.schema = ctx.Resource.GetSchema()
+	return ctx.Resource, nil
+}
+
+func TestConversionHubSchema(t *testing.T) {
+	type Gateway2 = Gateway
+	type Gateway struct {
+		resource.ResourceBase
+		Hostname string
+	}
+	converter := resource.NewConverter(
+		func(g *Gateway) (*Gateway2, error) { return &Gateway2{Domain: g.Hostname}, nil },
+		func(g *Gateway2) (*Gateway, error) { return &Gateway{Hostname: g.Domain}, nil })
+
+	mgr := createSchemaManager()
+	handler := &gatewayHandler{}
+	mgr.MustImport(&version, Gateway2{}, handler)
+	mgr.MustImportConversion(&version2, Gateway{}, &version, Gateway2{}, converter)
+
+	spoke := mgr.GetSchema(&version2, Gateway{})
+	r := &Gateway{Hostname: "gw.example.com"}
+	r.SetID("g1")
+	r.SetSchema(spoke)
+	ctx := &resource.Context{Resource: r}
+	got, err := spoke.GetHandler().GetGetHandler()(ctx)
+	ut.Assert(t, err == nil, "")
+	ut.Assert(t, handler.schema == mgr.GetSchema(&version, Gateway2{}), "hub handler should get hub schema")
+	ut.Assert(t, ctx.Resource == r, "")
+	ut.Equal(t, got.(*Gateway).Hostname, "gw.example.com")
+	ut.Assert(t, got.GetSchema() == spoke, "")
+}
@@ -0,0 +1,169 @@
+package schema
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+)
+
+const schemasSegment = "schemas"
+
+type APIGroupList struct {
+	Groups []*APIGroup `json:"groups"`
+}
+
+type APIGroup struct {
+	Name     string            `json:"name"`
+	Versions []APIGroupVersion `json:"versions"`
+}
+
+type APIGroupVersion struct {
+	Version string                `json:"version"`
+	Link    resource.ResourceLink `json:"link"`
+}
+
+type APIResourceList struct {
+	Group     string         `json:"group"`
+	Version   string         `json:"version"`
+	Resources []*APIResource `json:"resources"`
+}
+
+type APIResource struct {
+	ResourceType       string                `json:"resourceType"`
+	CollectionName     string                `json:"collectionName"`
+	ParentResources    []string              `json:"parentResources,omitempty"`
+	SupportAsyncDelete bool                  `json:"supportAsyncDelete"`
+	ResourceMethods    []resource.HttpMethod `json:"resourceMethods,omitempty"`
+	CollectionMethods  []resource.HttpMethod `json:"collectionMethods,omitempty"`
+	Actions            []string              `json:"actions,omitempty"`
+	Link               resource.ResourceLink `json:"link"`
+}
+
+// discovery requests are answered before the context is created, so
+// middlewares like authentication aren't called for them, it's disabled
+// by default, enable it only if the documents could be public
+func (m *SchemaManager) SetDiscovery(enable bool) {
+	m.discovery = enable
+}
+
+// Discover answer GET /apis, /apis/{group}/{version} and
+// /apis/{group}/{version}/schemas/{kind}, documents are generated from the
+// imported schemas for each request
+func (m *SchemaManager) Discover(req *http.Request) (interface{}, *goresterr.APIError) {
+	if m.discovery == false || req.Method != http.MethodGet {
+		return nil, nil
+	}
+
+	p := strings.TrimSuffix(multiSlashRegexp.ReplaceAllString(req.URL.Path, "/"), "/")
+	if p == resource.GroupPrefix {
+		return m.apiGroupList(), nil
+	}
+
+	for _, vs := range m.schemas {
+		if r, err := vs.discover(p); r != nil || err != nil {
+			return r, err
+		}
+	}
+	return nil, nil
+}
+
+func (m *SchemaManager) apiGroupList() *APIGroupList {
+	groupList := &APIGroupList{Groups: make([]*APIGroup, 0)}
+	groups := make(map[string]*APIGroup)
+	for _, vs := range m.schemas {
+		group, ok := groups[vs.version.Group]
+		if ok == false {
+			group = &APIGroup{Name: vs.version.Group}
+			groups[vs.version.Group] = group
+			groupList.Groups = append(groupList.Groups, group)
+		}
+		group.Versions = append(group.Versions, APIGroupVersion{
+			Version: vs.version.Version,
+			Link:    resource.ResourceLink(vs.versionUrl),
+		})
+	}
+	return groupList
+}
+
+// discovery routes are only for GET, schemas path is ignored if there is
+// a toplevel resource with the same name
+func (m *SchemaManager) generateDiscoveryRoute() resource.ResourceRoute {
+	route := resource.NewResourceRoute()
+	route.AddPathForMethod(http.MethodGet, resource.GroupPrefix)
+	for _, vs := range m.schemas {
+		route.AddPathForMethod(http.MethodGet, vs.versionUrl)
+		if vs.hasToplevelSchemasResource() == false {
+			route.AddPathForMethod(http.MethodGet, path.Join(vs.versionUrl, schemasSegment, ":kind"))
+		}
+	}
+	return route
+}
+
+func (s *VersionedSchemas) discover(p string) (interface{}, *goresterr.APIError) {
+	if p == s.versionUrl {
+		return s.apiResourceList(), nil
+	}
+
+	prefix := path.Join(s.versionUrl, schemasSegment) + "/"
+	if strings.HasPrefix(p, prefix) == false || s.hasToplevelSchemasResource() {
+		return nil, nil
+	}
+
+	kind := strings.TrimPrefix(p, prefix)
+	for _, schema := range getSchemas(s) {
+		if schema.resourceKindName == kind {
+			doc, err := schema.ResourceDocument()
+			if err != nil {
+				return nil, goresterr.NewAPIError(goresterr.ServerError,
+					*goresterr.NewLocalizedErrorMessage("", goresterr.MessageGenerateDocumentFailed, goresterr.Params{"kind": kind, "reason": err.Error()}))
+			}
+			return doc, nil
+		}
+	}
+	return nil, goresterr.NewAPIError(goresterr.NotFound,
+		*goresterr.NewLocalizedErrorMessage("", goresterr.MessageUnknownResourceKind, goresterr.Params{"kind": kind}))
+}
+
+func (s *VersionedSchemas) hasToplevelSchemasResource() bool {
+	for _, schema := range s.toplevelSchemas {
+		if schema.resourceName == schemasSegment {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *VersionedSchemas) apiResourceList() *APIResourceList {
+	resourceList := &APIResourceList{
+		Group:     s.version.Group,
+		Version:   s.version.Version,
+		Resources: make([]*APIResource, 0),
+	}
+	added := make(map[string]struct{})
+	for _, schema := range getSchemas(s) {
+		//schema with multiple parents is visited more than once
+		if _, ok := added[schema.resourceKindName]; ok {
+			continue
+		}
+		added[schema.resourceKindName] = struct{}{}
+
+		var actions []string
+		for _, action := range schema.resourceKind.GetActions() {
+			actions = append(actions, action.Name)
+		}
+		resourceList.Resources = append(resourceList.Resources, &APIResource{
+			ResourceType:       schema.resourceKindName,
+			CollectionName:     schema.resourceName,
+			ParentResources:    schema.parentKindNames(),
+			SupportAsyncDelete: schema.resourceKind.SupportAsyncDelete(),
+			ResourceMethods:    resource.GetResourceMethods(schema.handler),
+			CollectionMethods:  resource.GetCollectionMethods(schema.handler),
+			Actions:            actions,
+			Link:               resource.ResourceLink(path.Join(s.versionUrl, schemasSegment, schema.resourceKindName)),
+		})
+	}
+	return resourceList
+}
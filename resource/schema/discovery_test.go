@@ -0,0 +1,69 @@
+package schema
+
+import (
+	"net/http"
+	"testing"
+
+	ut "github.com/linkingthing/cement/unittest"
+	"github.com/linkingthing/gorest/resource"
+	"github.com/linkingthing/gorest/resource/schema/resourcedoc"
+)
+
+func TestDiscover(t *testing.T) {
+	mgr := createSchemaManager()
+	mgr.MustImport(&resource.APIVersion{Group: "testing", Version: "v2"}, Cluster{}, &resource.DumbHandler{})
+	mgr.MustImport(&resource.APIVersion{Group: "other", Version: "v1"}, Cluster{}, &resource.DumbHandler{})
+	mgr.SetDiscovery(true)
+
+	discover := func(method, url string) (interface{}, int) {
+		req, _ := http.NewRequest(method, url, nil)
+		doc, err := mgr.Discover(req)
+		if err != nil {
+			return nil, err.Status
+		}
+		return doc, http.StatusOK
+	}
+
+	doc, _ := discover(http.MethodGet, "/apis/")
+	ut.Equal(t, doc, &APIGroupList{Groups: []*APIGroup{
+		{Name: "testing", Versions: []APIGroupVersion{{"v1", "/apis/testing/v1"}, {"v2", "/apis/testing/v2"}}},
+		{Name: "other", Versions: []APIGroupVersion{{"v1", "/apis/other/v1"}}},
+	}})
+
+	doc, _ = discover(http.MethodGet, "/apis/testing/v1")
+	resources := doc.(*APIResourceList)
+	ut.Equal(t, resources.Group, "testing")
+	ut.Equal(t, resources.Version, "v1")
+	var kinds []string
+	for _, r := range resources.Resources {
+		kinds = append(kinds, r.ResourceType)
+	}
+	ut.Equal(t, kinds, []string{"cluster", "node", "namespace", "deployment", "pod", "statefulset", "daemonset"})
+	pod := resources.Resources[4]
+	ut.Equal(t, pod.CollectionName, "pods")
+	ut.Equal(t, pod.ParentResources, []string{"deployment", "daemonset", "statefulset"})
+	ut.Equal(t, pod.Actions, []string{"move"})
+	ut.Equal(t, pod.Link, resource.ResourceLink("/apis/testing/v1/schemas/pod"))
+
+	doc, _ = discover(http.MethodGet, "/apis/testing/v1/schemas/pod")
+	podDoc := doc.(*resourcedoc.ResourceDocument)
+	ut.Equal(t, podDoc.ResourceType, "pod")
+	ut.Equal(t, podDoc.ParentResources, []string{"deployment", "daemonset", "statefulset"})
+	ut.Equal(t, podDoc.ResourceActions[0].Name, "move")
+
+	_, status := discover(http.MethodGet, "/apis/testing/v1/schemas/unknown")
+	ut.Equal(t, status, http.StatusNotFound)
+
+	//not discovery requests
+	for _, url := range []string{"/apis/testing/v1/clusters", "/apis/testing/v3", "/apis/testing"} {
+		doc, status = discover(http.MethodGet, url)
+		ut.Assert(t, doc == nil && status == http.StatusOK, "%s shouldn't be discovered", url)
+	}
+	doc, _ = discover(http.MethodPost, "/apis/testing/v1")
+	ut.Assert(t, doc == nil, "")
+
+	//discovery is disabled
+	mgr.SetDiscovery(false)
+	doc, status = discover(http.MethodGet, "/apis/testing/v1")
+	ut.Assert(t, doc == nil && status == http.StatusOK, "disabled discovery shouldn't answer")
+}
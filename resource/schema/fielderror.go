@@ -0,0 +1,95 @@
+package schema
+
+import (
+	"errors"
+
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource/schema/resourcefield"
+	"github.com/linkingthing/gorest/resource/schema/resourcefield/validator"
+)
+
+type ruleError struct {
+	code       goresterr.ErrorCode
+	messageKey string
+}
+
+var ruleErrors = map[string]ruleError{
+	resourcefield.RuleRequired:  {goresterr.MissingRequired, goresterr.MessageMissingRequired},
+	validator.RuleOptions:       {goresterr.InvalidOption, goresterr.MessageInvalidOption},
+	validator.RuleMin:           {goresterr.MinLimitExceeded, goresterr.MessageMinLimitExceeded},
+	validator.RuleMax:           {goresterr.MaxLimitExceeded, goresterr.MessageMaxLimitExceeded},
+	validator.RuleMinLen:        {goresterr.MinLengthExceeded, goresterr.MessageMinLengthExceeded},
+	validator.RuleMaxLen:        {goresterr.MaxLengthExceeded, goresterr.MessageMaxLengthExceeded},
+	validator.RuleIsDomain:      {goresterr.InvalidFormat, goresterr.MessageInvalidDomain},
+	validator.RulePattern:       {goresterr.InvalidFormat, goresterr.MessagePatternMismatch},
+	validator.RuleIsIPv4:        {goresterr.InvalidFormat, goresterr.MessageInvalidIPv4},
+	validator.RuleIsIPv6:        {goresterr.InvalidFormat, goresterr.MessageInvalidIPv6},
+	validator.RuleIsCIDR:        {goresterr.InvalidFormat, goresterr.MessageInvalidCIDR},
+	validator.RuleIsMAC:         {goresterr.InvalidFormat, goresterr.MessageInvalidMAC},
+	validator.RuleIsEmail:       {goresterr.InvalidFormat, goresterr.MessageInvalidEmail},
+	validator.RuleIsURL:         {goresterr.InvalidFormat, goresterr.MessageInvalidURL},
+	validator.RuleIsUUID:        {goresterr.InvalidFormat, goresterr.MessageInvalidUUID},
+	validator.RuleValidate:      {goresterr.InvalidBodyContent, goresterr.MessageValidateFailed},
+	validator.RuleAfter:         {goresterr.MinLimitExceeded, goresterr.MessageTimeNotAfter},
+	validator.RuleBefore:        {goresterr.MaxLimitExceeded, goresterr.MessageTimeNotBefore},
+	resourcefield.RuleReadOnly:  {goresterr.InvalidBodyContent, goresterr.MessageReadOnlyField},
+	resourcefield.RuleImmutable: {goresterr.InvalidBodyContent, goresterr.MessageImmutableField},
+}
+
+// every field error becomes one detail, code of the api error is the
+// code shared by all the details, or InvalidBodyContent if they differ
+func fieldValidateError(err error) *goresterr.APIError {
+	var fes resourcefield.FieldErrors
+	var fe *resourcefield.FieldError
+	if errors.As(err, &fes) == false {
+		if errors.As(err, &fe) == false {
+			return goresterr.NewAPIError(goresterr.InvalidBodyContent,
+				*goresterr.NewLocalizedErrorMessage("", goresterr.MessageInvalidBody, goresterr.Params{"reason": err.Error()}))
+		}
+		fes = resourcefield.FieldErrors{fe}
+	}
+
+	details := make([]goresterr.ErrorDetail, 0, len(fes))
+	code := fieldErrorCode(fes[0])
+	for _, fe := range fes {
+		details = append(details, fieldErrorDetail(fe))
+		if fieldErrorCode(fe) != code {
+			code = goresterr.InvalidBodyContent
+		}
+	}
+
+	return goresterr.NewDetailsAPIError(code, details...)
+}
+
+// error returned by ResourceValidator, api error is kept, field errors
+// are converted to details
+func resourceValidateError(err error) *goresterr.APIError {
+	var apiErr *goresterr.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return fieldValidateError(err)
+}
+
+func fieldErrorCode(fe *resourcefield.FieldError) goresterr.ErrorCode {
+	if re, ok := ruleErrors[fe.Rule]; ok {
+		return re.code
+	}
+	return goresterr.InvalidBodyContent
+}
+
+func fieldErrorDetail(fe *resourcefield.FieldError) goresterr.ErrorDetail {
+	key := goresterr.MessageInvalidField
+	if re, ok := ruleErrors[fe.Rule]; ok {
+		key = re.messageKey
+	}
+
+	return goresterr.ErrorDetail{
+		Code:  fieldErrorCode(fe).Code,
+		Field: fe.Path,
+		Rule:  fe.Rule,
+		Limit: fe.Limit,
+		ErrorMessage: *goresterr.NewLocalizedErrorMessage(fe.Error(), key,
+			goresterr.Params{"field": fe.Path, "limit": fe.Limit}),
+	}
+}
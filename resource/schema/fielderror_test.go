@@ -0,0 +1,62 @@
+package schema
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	ut "github.com/linkingthing/cement/unittest"
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource/schema/resourcefield"
+	"github.com/linkingthing/gorest/resource/schema/resourcefield/validator"
+)
+
+func TestFieldErrorDetails(t *testing.T) {
+	mgr := createWorkloadSchemaManager()
+	req, _ := http.NewRequest(http.MethodPost, workloadMoveUrl, bytes.NewBufferString(`{"policy":"stop"}`))
+	_, err := mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err != nil, "")
+	ut.Equal(t, err.ErrorCode, goresterr.InvalidBodyContent)
+	ut.Equal(t, len(err.Details), 2)
+
+	missing := err.Details[0]
+	ut.Equal(t, missing.Code, goresterr.MissingRequired.Code)
+	ut.Equal(t, missing.Field, "nodeName")
+	ut.Equal(t, missing.Rule, resourcefield.RuleRequired)
+	ut.Equal(t, missing.MessageEN, "field nodeName is missing")
+	ut.Equal(t, missing.MessageCN, "缺少必填字段[nodeName]")
+
+	option := err.Details[1]
+	ut.Equal(t, option.Code, goresterr.InvalidOption.Code)
+	ut.Equal(t, option.Field, "policy")
+	ut.Equal(t, option.Rule, validator.RuleOptions)
+	ut.Equal(t, option.Limit, []string{"drain", "force"})
+	ut.Equal(t, option.MessageCN, "字段[policy]的值必须是[drain force]中的一个")
+	ut.Equal(t, err.Message, missing.MessageEN+"; "+option.MessageEN)
+
+	req, _ = http.NewRequest(http.MethodPost, workloadMoveUrl, bytes.NewBufferString(`{"nodeName":"n","policy":"drain"}`))
+	_, err = mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err != nil, "")
+	ut.Equal(t, err.ErrorCode, goresterr.MinLengthExceeded)
+	ut.Equal(t, len(err.Details), 1)
+	ut.Equal(t, err.Details[0].Limit, int64(2))
+	ut.Equal(t, err.Details[0].MessageCN, "字段[nodeName]的长度不能小于2")
+}
+
+func TestFormatFieldErrorDetails(t *testing.T) {
+	mgr := createVolumeSchemaManager()
+	req, _ := http.NewRequest(http.MethodPatch, "/apis/testing/v1/clusters/c1/volumes/v1",
+		bytes.NewBufferString(`{"host":"2001:db8::1","selector":"db,Web"}`))
+	r, err := mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err == nil, "")
+
+	_, err = r.GetSchema().ApplyPatch(r, &Volume{Name: "v1", Driver: "lvm", Size: 10})
+	ut.Assert(t, err != nil, "")
+	ut.Equal(t, err.ErrorCode, goresterr.InvalidFormat)
+	ut.Equal(t, len(err.Details), 2)
+	ut.Equal(t, err.Details[0].Rule, validator.RuleIsIPv4)
+	ut.Equal(t, err.Details[0].MessageCN, "字段[host]不是合法的IPv4地址")
+	ut.Equal(t, err.Details[1].Rule, validator.RulePattern)
+	ut.Equal(t, err.Details[1].Limit, "^[a-z]+(,[a-z]+)*$")
+	ut.Equal(t, err.Details[1].MessageCN, "字段[selector]的值不匹配正则表达式^[a-z]+(,[a-z]+)*$")
+}
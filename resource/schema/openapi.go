@@ -0,0 +1,300 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+	"github.com/linkingthing/gorest/resource/schema/openapi"
+)
+
+const (
+	errorResponseName    = "Error"
+	paginationSchemaName = "Pagination"
+	jsonPatchSchemaName  = "JSONPatch"
+	collectionSuffix     = "Collection"
+)
+
+func (m *SchemaManager) GenerateOpenAPI(v *resource.APIVersion) (*openapi.Document, error) {
+	vs := m.getVersionedSchemas(v)
+	if vs == nil {
+		vs = NewVersionedSchemas(v)
+	}
+	return vs.GenerateOpenAPI()
+}
+
+// the document is written to the file named with group and version,
+// like testing_v1.json
+func (m *SchemaManager) WriteOpenAPI(v *resource.APIVersion, path string) error {
+	doc, err := m.GenerateOpenAPI(v)
+	if err != nil {
+		return err
+	}
+	return doc.WriteJsonFile(path, v.Group+"_"+v.Version)
+}
+
+// GenerateOpenAPI walk all the schemas of the version, and generate
+// one openapi document
+func (s *VersionedSchemas) GenerateOpenAPI() (*openapi.Document, error) {
+	doc := openapi.NewDocument(s.version.Group, s.version.Version)
+	errSchema, err := doc.SchemaOf(reflect.TypeOf(goresterr.APIError{}))
+	if err != nil {
+		return nil, err
+	}
+	doc.AddResponse(errorResponseName, &openapi.Response{
+		Description: "error",
+		Content:     openapi.JsonContent(errSchema),
+	})
+
+	if _, err := doc.SchemaOf(reflect.TypeOf(resource.Pagination{})); err != nil {
+		return nil, err
+	}
+	doc.AddSchema(jsonPatchSchemaName, jsonPatchSchema())
+
+	for _, schema := range s.toplevelSchemas {
+		if err := schema.addToOpenAPI(doc, nil); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+func jsonPatchSchema() *openapi.Schema {
+	return &openapi.Schema{
+		Type: openapi.TypeArray,
+		Items: &openapi.Schema{
+			Type: openapi.TypeObject,
+			Properties: map[string]*openapi.Schema{
+				"op":    {Type: openapi.TypeString, Enum: []string{"add", "remove", "replace", "move", "copy", "test"}},
+				"path":  {Type: openapi.TypeString},
+				"from":  {Type: openapi.TypeString},
+				"value": {},
+			},
+			Required: []string{"op", "path"},
+		},
+	}
+}
+
+func (s *Schema) addToOpenAPI(doc *openapi.Document, parents []*Schema) error {
+	kindSchema, err := doc.SchemaOf(reflect.TypeOf(s.resourceKind))
+	if err != nil {
+		return fmt.Errorf("generate schema of %s failed: %s", s.resourceKindName, err.Error())
+	}
+
+	collectionPath, pathParams := s.openAPICollectionPath(parents)
+	resourcePath := collectionPath + "/{" + s.resourceKindName + "_id}"
+	resourceParams := append(append([]*openapi.Parameter{}, pathParams...), pathParameter(s.resourceKindName))
+	goName := reflect.TypeOf(s.resourceKind).Name()
+	handler := s.handler
+
+	if len(resource.GetCollectionMethods(handler)) > 0 {
+		item := doc.GetPathItem(collectionPath)
+		item.Parameters = pathParams
+		if handler.GetListHandler() != nil {
+			collection := doc.AddSchema(goName+collectionSuffix, collectionSchema(kindSchema))
+			item.Get = s.newOperation("list"+goName, "list "+s.resourceName, listParameters(),
+				map[string]*openapi.Response{"200": jsonResponse("resource collection", collection)})
+		}
+		if handler.GetCreateHandler() != nil {
+			op := s.newOperation("create"+goName, "create "+s.resourceKindName, nil,
+				map[string]*openapi.Response{"201": jsonResponse("created resource", kindSchema)})
+			op.RequestBody = &openapi.RequestBody{Required: true, Content: openapi.JsonContent(kindSchema)}
+			item.Post = op
+		}
+	}
+
+	if len(resource.GetResourceMethods(handler)) > 0 {
+		item := doc.GetPathItem(resourcePath)
+		item.Parameters = resourceParams
+		if handler.GetGetHandler() != nil {
+			ok := jsonResponse("resource", kindSchema)
+			ok.Headers = etagHeader()
+			item.Get = s.newOperation("get"+goName, "get "+s.resourceKindName,
+				[]*openapi.Parameter{fieldsParameter(), headerParameter("If-None-Match")},
+				map[string]*openapi.Response{"200": ok, "304": {Description: "not modified"}})
+		}
+		if handler.GetUpdateHandler() != nil {
+			ok := jsonResponse("updated resource", kindSchema)
+			ok.Headers = etagHeader()
+			op := s.newOperation("update"+goName, "update "+s.resourceKindName,
+				[]*openapi.Parameter{headerParameter("If-Match")}, map[string]*openapi.Response{"200": ok})
+			op.RequestBody = &openapi.RequestBody{Required: true, Content: openapi.JsonContent(kindSchema)}
+			item.Put = op
+		}
+		if handler.GetPatchHandler() != nil {
+			ok := jsonResponse("patched resource", kindSchema)
+			ok.Headers = etagHeader()
+			op := s.newOperation("patch"+goName, "patch "+s.resourceKindName,
+				[]*openapi.Parameter{headerParameter("If-Match")}, map[string]*openapi.Response{"200": ok})
+			content := openapi.JsonContent(kindSchema, string(resource.MergePatch), "application/json")
+			content[string(resource.JSONPatch)] = &openapi.MediaType{Schema: openapi.RefSchema(jsonPatchSchemaName)}
+			op.RequestBody = &openapi.RequestBody{Required: true, Content: content}
+			item.Patch = op
+		}
+		if handler.GetDeleteHandler() != nil {
+			status := "204"
+			if s.resourceKind.SupportAsyncDelete() {
+				status = "202"
+			}
+			item.Delete = s.newOperation("delete"+goName, "delete "+s.resourceKindName, nil,
+				map[string]*openapi.Response{status: {Description: "resource is deleted"}})
+		}
+		if handler.GetActionHandler() != nil {
+			op, err := s.actionOperation(doc, goName)
+			if err != nil {
+				return err
+			}
+			item.Post = op
+		}
+	}
+
+	for _, child := range s.children {
+		if err := child.addToOpenAPI(doc, append(parents, s)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parameters of the parents in path are named with kind name and _id,
+// same with the route
+func (s *Schema) openAPICollectionPath(parents []*Schema) (string, []*openapi.Parameter) {
+	segments := []string{s.version.GetUrl()}
+	var params []*openapi.Parameter
+	for _, parent := range parents {
+		segments = append(segments, parent.resourceName, "{"+parent.resourceKindName+"_id}")
+		params = append(params, pathParameter(parent.resourceKindName))
+	}
+	segments = append(segments, s.resourceName)
+	return strings.Join(segments, "/"), params
+}
+
+// error responses of all the operations share the APIError schema
+func (s *Schema) newOperation(id, summary string, params []*openapi.Parameter, responses map[string]*openapi.Response) *openapi.Operation {
+	errResponse := openapi.RefResponse(errorResponseName)
+	responses["4XX"] = errResponse
+	responses["5XX"] = errResponse
+	return &openapi.Operation{
+		OperationID: id,
+		Tags:        []string{s.resourceKindName},
+		Summary:     summary,
+		Parameters:  params,
+		Responses:   responses,
+	}
+}
+
+// actions share the same path, action name is in query, and inputs and
+// outputs of the actions are listed in oneOf
+func (s *Schema) actionOperation(doc *openapi.Document, goName string) (*openapi.Operation, error) {
+	var names []string
+	var inputs, outputs []*openapi.Schema
+	var descriptions []string
+	for _, action := range s.resourceKind.GetActions() {
+		names = append(names, action.Name)
+		description := "action " + action.Name
+		if action.Input != nil {
+			input, err := doc.SchemaOf(reflect.TypeOf(action.Input))
+			if err != nil {
+				return nil, fmt.Errorf("generate input schema of action %s failed: %s", action.Name, err.Error())
+			}
+			inputs = append(inputs, input)
+			description += ", input: " + reflect.Indirect(reflect.ValueOf(action.Input)).Type().Name()
+		}
+		if action.Output != nil {
+			output, err := doc.SchemaOf(reflect.TypeOf(action.Output))
+			if err != nil {
+				return nil, fmt.Errorf("generate output schema of action %s failed: %s", action.Name, err.Error())
+			}
+			outputs = append(outputs, output)
+			description += ", output: " + reflect.Indirect(reflect.ValueOf(action.Output)).Type().Name()
+		}
+		descriptions = append(descriptions, description)
+	}
+
+	params := []*openapi.Parameter{{
+		Name:     "action",
+		In:       openapi.InQuery,
+		Required: true,
+		Schema:   &openapi.Schema{Type: openapi.TypeString, Enum: names},
+	}}
+	op := s.newOperation("action"+goName, "actions of "+s.resourceKindName, params,
+		map[string]*openapi.Response{"200": jsonResponse("action result", oneOf(outputs))})
+	op.Description = strings.Join(descriptions, "\n")
+	if len(inputs) > 0 {
+		op.RequestBody = &openapi.RequestBody{Content: openapi.JsonContent(oneOf(inputs))}
+	}
+	return op, nil
+}
+
+func oneOf(schemas []*openapi.Schema) *openapi.Schema {
+	switch len(schemas) {
+	case 0:
+		return &openapi.Schema{}
+	case 1:
+		return schemas[0]
+	default:
+		return &openapi.Schema{OneOf: schemas}
+	}
+}
+
+func collectionSchema(kindSchema *openapi.Schema) *openapi.Schema {
+	return &openapi.Schema{
+		Type: openapi.TypeObject,
+		Properties: map[string]*openapi.Schema{
+			"type":         {Type: openapi.TypeString},
+			"resourceType": {Type: openapi.TypeString},
+			"links":        {Type: openapi.TypeObject, AdditionalProperties: &openapi.Schema{Type: openapi.TypeString}},
+			"pagination":   openapi.RefSchema(paginationSchemaName),
+			"data":         {Type: openapi.TypeArray, Items: kindSchema},
+		},
+	}
+}
+
+func jsonResponse(description string, schema *openapi.Schema) *openapi.Response {
+	return &openapi.Response{Description: description, Content: openapi.JsonContent(schema)}
+}
+
+func pathParameter(kindName string) *openapi.Parameter {
+	return &openapi.Parameter{
+		Name:     kindName + "_id",
+		In:       openapi.InPath,
+		Required: true,
+		Schema:   &openapi.Schema{Type: openapi.TypeString},
+	}
+}
+
+func headerParameter(name string) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: openapi.InHeader, Schema: &openapi.Schema{Type: openapi.TypeString}}
+}
+
+func etagHeader() map[string]*openapi.Header {
+	return map[string]*openapi.Header{
+		"ETag": {Description: "resource version of the resource", Schema: &openapi.Schema{Type: openapi.TypeString}},
+	}
+}
+
+func fieldsParameter() *openapi.Parameter {
+	return queryParameter(resource.FilterNameFields, "json names of the returned fields, separated by comma", openapi.TypeString)
+}
+
+func listParameters() []*openapi.Parameter {
+	return []*openapi.Parameter{
+		queryParameter(resource.FilterNamePageSize, "page size", openapi.TypeInteger),
+		queryParameter(resource.FilterNamePageNum, "page number, start from 1", openapi.TypeInteger),
+		queryParameter(resource.FilterNameContinue, "token of next page in keyset pagination", openapi.TypeString),
+		queryParameter(resource.FilterNameSort, "sort fields separated by comma, prefix - means descending", openapi.TypeString),
+		fieldsParameter(),
+		queryParameter(resource.FilterNameWatch, "stream the changes of the collection", openapi.TypeBoolean),
+	}
+}
+
+func queryParameter(name, description, typ string) *openapi.Parameter {
+	return &openapi.Parameter{
+		Name:        name,
+		In:          openapi.InQuery,
+		Description: description,
+		Schema:      &openapi.Schema{Type: typ},
+	}
+}
@@ -0,0 +1,151 @@
+package openapi
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"reflect"
+)
+
+const (
+	OpenAPIVersion = "3.1.0"
+
+	componentSchemaPrefix   = "#/components/schemas/"
+	componentResponsePrefix = "#/components/responses/"
+	docFileSuffix           = ".json"
+)
+
+const (
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+)
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+
+	types map[string]reflect.Type `json:"-"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type PathItem struct {
+	Parameters []*Parameter `json:"parameters,omitempty"`
+	Get        *Operation   `json:"get,omitempty"`
+	Put        *Operation   `json:"put,omitempty"`
+	Post       *Operation   `json:"post,omitempty"`
+	Delete     *Operation   `json:"delete,omitempty"`
+	Patch      *Operation   `json:"patch,omitempty"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Tags        []string             `json:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Ref         string                `json:"$ref,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas   map[string]*Schema   `json:"schemas,omitempty"`
+	Responses map[string]*Response `json:"responses,omitempty"`
+}
+
+func NewDocument(title, version string) *Document {
+	return &Document{
+		OpenAPI: OpenAPIVersion,
+		Info:    Info{Title: title, Version: version},
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			Schemas:   make(map[string]*Schema),
+			Responses: make(map[string]*Response),
+		},
+		types: make(map[string]reflect.Type),
+	}
+}
+
+func (d *Document) GetPathItem(p string) *PathItem {
+	item, ok := d.Paths[p]
+	if ok == false {
+		item = &PathItem{}
+		d.Paths[p] = item
+	}
+	return item
+}
+
+// AddSchema add a schema to components with the name, and return the
+// reference to it
+func (d *Document) AddSchema(name string, schema *Schema) *Schema {
+	d.Components.Schemas[name] = schema
+	return RefSchema(name)
+}
+
+func (d *Document) AddResponse(name string, response *Response) *Response {
+	d.Components.Responses[name] = response
+	return RefResponse(name)
+}
+
+func RefResponse(name string) *Response {
+	return &Response{Ref: componentResponsePrefix + name}
+}
+
+func (d *Document) WriteJsonFile(targetPath, name string) error {
+	if err := os.MkdirAll(targetPath, os.ModePerm); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path.Join(targetPath, name+docFileSuffix), data, 0644)
+}
+
+func JsonContent(schema *Schema, contentTypes ...string) map[string]*MediaType {
+	if len(contentTypes) == 0 {
+		contentTypes = []string{"application/json"}
+	}
+
+	content := make(map[string]*MediaType, len(contentTypes))
+	for _, contentType := range contentTypes {
+		content[contentType] = &MediaType{Schema: schema}
+	}
+	return content
+}
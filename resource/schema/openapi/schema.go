@@ -0,0 +1,350 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/linkingthing/gorest/util"
+)
+
+const (
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	TypeArray   = "array"
+	TypeObject  = "object"
+)
+
+const (
+	requiredTag    = "required="
+	optionsTag     = "options="
+	minLenTag      = "minLen="
+	maxLenTag      = "maxLen="
+	minTag         = "min="
+	maxTag         = "max="
+	patternTag     = "pattern="
+	descriptionTag = "description="
+	readOnlyTag    = "readOnly"
+	writeOnlyTag   = "writeOnly"
+)
+
+// formats of the format validators, isCIDR and isMAC have no standard
+// format in json schema
+var formatTags = map[string]string{
+	"isIPv4":   "ipv4",
+	"isIPv6":   "ipv6",
+	"isCIDR":   "cidr",
+	"isMAC":    "mac",
+	"isEmail":  "email",
+	"isURL":    "uri",
+	"isUUID":   "uuid",
+	"isDomain": "hostname",
+}
+
+const resourceBaseName = "ResourceBase"
+
+// fields of ResourceBase which are set by server
+var readOnlyBaseFields = map[string]struct{}{
+	"type":              {},
+	"links":             {},
+	"creationTimestamp": {},
+	"deletionTimestamp": {},
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	MinLength            *int64             `json:"minLength,omitempty"`
+	MaxLength            *int64             `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	ReadOnly             bool               `json:"readOnly,omitempty"`
+	WriteOnly            bool               `json:"writeOnly,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+}
+
+func RefSchema(name string) *Schema {
+	return &Schema{Ref: componentSchemaPrefix + name}
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// SchemaOf return the schema of the go type, named struct is added to
+// components and referenced by its name
+func (d *Document) SchemaOf(t reflect.Type) (*Schema, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType || isTimeType(t) {
+		return &Schema{Type: TypeString, Format: "date-time"}, nil
+	} else if t == rawMessageType || t.Kind() == reflect.Interface {
+		return &Schema{}, nil
+	} else if implements(t, textMarshalerType) {
+		return &Schema{Type: TypeString}, nil
+	} else if t.Kind() == reflect.Struct && implements(t, jsonMarshalerType) == false {
+		return d.structSchema(t)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: TypeString}, nil
+	case reflect.Bool:
+		return &Schema{Type: TypeBoolean}, nil
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: TypeInteger, Format: "int64"}, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: TypeInteger, Format: "int32"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := float64(0)
+		return &Schema{Type: TypeInteger, Minimum: &zero}, nil
+	case reflect.Float32:
+		return &Schema{Type: TypeNumber, Format: "float"}, nil
+	case reflect.Float64:
+		return &Schema{Type: TypeNumber, Format: "double"}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: TypeString, Format: "byte"}, nil
+		}
+		items, err := d.SchemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: TypeArray, Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("map key of %s isn't string", t.String())
+		}
+		values, err := d.SchemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: TypeObject, AdditionalProperties: values}, nil
+	case reflect.Struct:
+		//struct with customized json marshaler
+		return &Schema{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t.String())
+	}
+}
+
+// time type like resource.ISOTime
+func isTimeType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.ConvertibleTo(timeType)
+}
+
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
+
+// struct with same name in different package gets a name with package
+// suffix
+func (d *Document) structSchema(t reflect.Type) (*Schema, error) {
+	name := t.Name()
+	if name == "" {
+		return d.buildStructSchema(t)
+	}
+
+	if old, ok := d.types[name]; ok && old != t {
+		pkg := t.PkgPath()
+		name = name + "_" + pkg[strings.LastIndex(pkg, "/")+1:]
+	}
+
+	if _, ok := d.types[name]; ok {
+		return RefSchema(name), nil
+	}
+
+	//register before building to support recursive type
+	d.types[name] = t
+	d.Components.Schemas[name] = &Schema{}
+	schema, err := d.buildStructSchema(t)
+	if err != nil {
+		delete(d.types, name)
+		delete(d.Components.Schemas, name)
+		return nil, err
+	}
+	return d.AddSchema(name, schema), nil
+}
+
+func (d *Document) buildStructSchema(t reflect.Type) (*Schema, error) {
+	schema := &Schema{Type: TypeObject, Properties: make(map[string]*Schema)}
+	if err := d.addStructFields(schema, t, false); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func (d *Document) addStructFields(schema *Schema, t reflect.Type, isResourceBase bool) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := jsonName(field)
+		if ok == false {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := d.addStructFields(schema, ft, ft.Name() == resourceBaseName); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if field.IsExported() == false {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldSchema, err := d.SchemaOf(field.Type)
+		if err != nil {
+			return fmt.Errorf("field %s of %s: %s", field.Name, t.Name(), err.Error())
+		}
+
+		required, err := applyRestTags(fieldSchema, field.Tag.Get("rest"))
+		if err != nil {
+			return fmt.Errorf("field %s of %s: %s", field.Name, t.Name(), err.Error())
+		}
+
+		if _, ok := readOnlyBaseFields[name]; ok && isResourceBase {
+			fieldSchema.ReadOnly = true
+		}
+
+		schema.Properties[name] = fieldSchema
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return nil
+}
+
+// return false if the field is ignored by json
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	return name, true
+}
+
+// rules of string and int are applied to elements of slice and map,
+// same with validators, max and maxLen of validators are exclusive
+func applyRestTags(schema *Schema, rest string) (bool, error) {
+	if rest == "" {
+		return false, nil
+	}
+
+	target := schema
+	if target.Items != nil {
+		target = target.Items
+	} else if target.AdditionalProperties != nil {
+		target = target.AdditionalProperties
+	}
+
+	required := false
+	for _, tag := range util.SplitRestTag(rest) {
+		var err error
+		switch {
+		case strings.HasPrefix(tag, requiredTag):
+			required, err = strconv.ParseBool(strings.TrimPrefix(tag, requiredTag))
+		case strings.HasPrefix(tag, optionsTag):
+			target.Enum = strings.Split(strings.TrimPrefix(tag, optionsTag), "|")
+		case strings.HasPrefix(tag, minLenTag):
+			target.MinLength, err = parseInt(strings.TrimPrefix(tag, minLenTag))
+		case strings.HasPrefix(tag, maxLenTag):
+			if target.MaxLength, err = parseInt(strings.TrimPrefix(tag, maxLenTag)); err == nil {
+				*target.MaxLength -= 1
+			}
+		case strings.HasPrefix(tag, minTag):
+			target.Minimum, err = parseFloat(strings.TrimPrefix(tag, minTag))
+		case strings.HasPrefix(tag, maxTag):
+			target.ExclusiveMaximum, err = parseFloat(strings.TrimPrefix(tag, maxTag))
+		case strings.HasPrefix(tag, patternTag):
+			target.Pattern = strings.TrimPrefix(tag, patternTag)
+		case strings.HasPrefix(tag, descriptionTag):
+			schema.Description = strings.TrimPrefix(tag, descriptionTag)
+		case tagEnabled(tag, readOnlyTag):
+			schema.ReadOnly = true
+		case tagEnabled(tag, writeOnlyTag):
+			schema.WriteOnly = true
+		default:
+			if format, ok := formatOfTag(tag); ok {
+				target.Format = format
+			}
+		}
+		if err != nil {
+			return false, fmt.Errorf("invalid rest tag %s: %s", tag, err.Error())
+		}
+	}
+	return required, nil
+}
+
+// tag of format validator is like isIPv4 or isIPv4=true
+func formatOfTag(tag string) (string, bool) {
+	name, value, hasValue := strings.Cut(tag, "=")
+	format, ok := formatTags[name]
+	if ok == false {
+		return "", false
+	}
+	if hasValue {
+		if enabled, _ := strconv.ParseBool(value); enabled == false {
+			return "", false
+		}
+	}
+	return format, true
+}
+
+// bool tag like readOnly or readOnly=true
+func tagEnabled(tag, name string) bool {
+	tagName, value, hasValue := strings.Cut(tag, "=")
+	if tagName != name {
+		return false
+	}
+	enabled, _ := strconv.ParseBool(value)
+	return hasValue == false || enabled
+}
+
+func parseFloat(s string) (*float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func parseInt(s string) (*int64, error) {
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	ut "github.com/linkingthing/cement/unittest"
+	"github.com/linkingthing/gorest/resource"
+	"github.com/linkingthing/gorest/resource/schema/openapi"
+)
+
+func TestGenerateOpenAPI(t *testing.T) {
+	mgr := createSchemaManager()
+	mgr.MustImport(&version, Volume{}, &volumeHandler{})
+	doc, err := mgr.GenerateOpenAPI(&version)
+	ut.Assert(t, err == nil, "generate openapi failed:%v", err)
+	ut.Equal(t, doc.OpenAPI, openapi.OpenAPIVersion)
+	ut.Equal(t, doc.Info, openapi.Info{Title: "testing", Version: "v1"})
+
+	podPath := "/apis/testing/v1/clusters/{cluster_id}/namespaces/{namespace_id}/deployments/{deployment_id}/pods/{pod_id}"
+	pod, ok := doc.Paths[podPath]
+	ut.Assert(t, ok, "")
+	ut.Equal(t, len(pod.Parameters), 4)
+	ut.Equal(t, pod.Parameters[3].Name, "pod_id")
+	ut.Equal(t, pod.Post.Parameters[0].Schema.Enum, []string{"move"})
+	ut.Equal(t, pod.Post.RequestBody.Content["application/json"].Schema.Ref, "#/components/schemas/Location")
+	ut.Equal(t, pod.Get.Responses["200"].Content["application/json"].Schema.Ref, "#/components/schemas/Pod")
+	ut.Equal(t, pod.Get.Responses["4XX"].Ref, "#/components/responses/Error")
+
+	pods := doc.Paths["/apis/testing/v1/clusters/{cluster_id}/namespaces/{namespace_id}/deployments/{deployment_id}/pods"]
+	ut.Equal(t, len(pods.Parameters), 3)
+	ut.Equal(t, pods.Get.Responses["200"].Content["application/json"].Schema.Ref, "#/components/schemas/PodCollection")
+	ut.Equal(t, pods.Post.RequestBody.Content["application/json"].Schema.Ref, "#/components/schemas/Pod")
+
+	//volume only supports get and patch
+	_, ok = doc.Paths["/apis/testing/v1/clusters/{cluster_id}/volumes"]
+	ut.Assert(t, ok == false, "")
+	volume := doc.Paths["/apis/testing/v1/clusters/{cluster_id}/volumes/{volume_id}"]
+	ut.Assert(t, volume.Get != nil && volume.Patch != nil, "")
+	ut.Assert(t, volume.Put == nil && volume.Delete == nil && volume.Post == nil, "")
+	ut.Equal(t, volume.Patch.RequestBody.Content[string(resource.JSONPatch)].Schema.Ref, "#/components/schemas/JSONPatch")
+
+	volumeSchema := doc.Components.Schemas["Volume"]
+	ut.Equal(t, volumeSchema.Required, []string{"name", "driver"})
+	ut.Equal(t, *volumeSchema.Properties["name"].MinLength, int64(2))
+	ut.Equal(t, *volumeSchema.Properties["name"].MaxLength, int64(9))
+	ut.Equal(t, volumeSchema.Properties["driver"].Enum, []string{"lvm", "ceph"})
+	ut.Equal(t, *volumeSchema.Properties["size"].Minimum, float64(1))
+	ut.Equal(t, *volumeSchema.Properties["size"].ExclusiveMaximum, float64(100))
+	ut.Equal(t, volumeSchema.Properties["creationTimestamp"].Format, "date-time")
+	ut.Assert(t, volumeSchema.Properties["links"].ReadOnly, "")
+	ut.Equal(t, volumeSchema.Properties["labels"].AdditionalProperties.Type, openapi.TypeString)
+	ut.Equal(t, volumeSchema.Properties["host"].Format, "ipv4")
+	ut.Equal(t, volumeSchema.Properties["selector"].Pattern, "^[a-z]+(,[a-z]+)*$")
+	ut.Equal(t, *volumeSchema.Properties["selector"].MinLength, int64(1))
+
+	errSchema := doc.Components.Schemas["APIError"]
+	for _, name := range []string{"code", "status", "type", "message", "details"} {
+		_, ok := errSchema.Properties[name]
+		ut.Assert(t, ok, "api error has no %s", name)
+	}
+	detailSchema := doc.Components.Schemas["ErrorDetail"]
+	for _, name := range []string{"code", "field", "rule", "limit", "messageEN", "messageCN"} {
+		_, ok := detailSchema.Properties[name]
+		ut.Assert(t, ok, "error detail has no %s", name)
+	}
+
+	_, err = json.Marshal(doc)
+	ut.Assert(t, err == nil, "")
+}
+
+type Gateway struct {
+	resource.ResourceBase `json:",inline"`
+	Domain                string `json:"domain" rest:"isDomain"`
+	Alias                 string `json:"alias" rest:"isDomain=false"`
+}
+
+func (g Gateway) GetParents() []resource.ResourceKind {
+	return []resource.ResourceKind{Cluster{}}
+}
+
+func TestOpenAPIDomainFormat(t *testing.T) {
+	mgr := createSchemaManager()
+	mgr.MustImport(&version, Gateway{}, &volumeHandler{})
+	doc, err := mgr.GenerateOpenAPI(&version)
+	ut.Assert(t, err == nil, "generate openapi failed:%v", err)
+	gatewaySchema := doc.Components.Schemas["Gateway"]
+	ut.Equal(t, gatewaySchema.Properties["domain"].Format, "hostname")
+	ut.Equal(t, gatewaySchema.Properties["alias"].Format, "")
+}
@@ -0,0 +1,195 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+	"github.com/linkingthing/gorest/util"
+)
+
+func parsePatch(req *http.Request, r resource.Resource, body []byte, strict bool) *goresterr.APIError {
+	if r.GetID() == "" {
+		return goresterr.NewAPIError(goresterr.MethodNotAllowed,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessagePatchCollection, goresterr.Params{"type": r.GetType()}))
+	}
+
+	patchType, err := getPatchType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return resource.UnsupportedMediaTypeError(req.Header.Get("Content-Type"),
+			string(resource.MergePatch), string(resource.JSONPatch), resource.ContentTypeJSON)
+	}
+
+	switch patchType {
+	case resource.JSONPatch:
+		if _, err := util.ParseJSONPatch(body); err != nil {
+			return goresterr.NewAPIError(goresterr.InvalidBodyContent,
+				*goresterr.NewLocalizedErrorMessage("", goresterr.MessageInvalidPatch, goresterr.Params{"reason": err.Error()}))
+		}
+	case resource.MergePatch:
+		var doc map[string]interface{}
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return goresterr.NewAPIError(goresterr.InvalidBodyContent,
+				*goresterr.NewLocalizedErrorMessage("", goresterr.MessageInvalidPatch, goresterr.Params{"reason": "merge patch isn't a json object:" + err.Error()}))
+		}
+
+		if strict {
+			if err := resource.DecodeJSON(body, reflect.New(reflect.TypeOf(r).Elem()).Interface(), true); err != nil {
+				return err
+			}
+		}
+	}
+
+	r.SetPatch(&resource.Patch{Type: patchType, Data: body})
+	return nil
+}
+
+// application/json is treated as merge patch
+func getPatchType(contentType string) (resource.PatchType, error) {
+	if contentType == "" {
+		return resource.MergePatch, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("invalid content type %s:%s", contentType, err.Error())
+	}
+
+	switch mediaType {
+	case string(resource.JSONPatch):
+		return resource.JSONPatch, nil
+	case string(resource.MergePatch), "application/json":
+		return resource.MergePatch, nil
+	default:
+		return "", fmt.Errorf("unsupported patch content type %s", mediaType)
+	}
+}
+
+func (s *Schema) ApplyPatch(r resource.Resource, current resource.Resource) (resource.Resource, *goresterr.APIError) {
+	patch := r.GetPatch()
+	if patch == nil {
+		return nil, goresterr.NewAPIError(goresterr.InvalidBodyContent,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageNoPatch, nil))
+	}
+
+	data, err := json.Marshal(current)
+	if err != nil {
+		return nil, goresterr.NewAPIError(goresterr.ServerError,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageMarshalFailed, goresterr.Params{"reason": err.Error()}))
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, goresterr.NewAPIError(goresterr.ServerError,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageMarshalFailed, goresterr.Params{"reason": err.Error()}))
+	}
+
+	doc, touched, err := applyPatchToDocument(doc, patch)
+	if err != nil {
+		return nil, goresterr.NewAPIError(goresterr.InvalidBodyContent,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageInvalidPatch, goresterr.Params{"reason": err.Error()}))
+	}
+
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, goresterr.NewAPIError(goresterr.InvalidBodyContent,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageInvalidPatchedResource, goresterr.Params{"reason": "not a json object"}))
+	}
+
+	if touched == nil {
+		for k := range obj {
+			touched = append(touched, k)
+		}
+	}
+
+	if data, err = json.Marshal(obj); err != nil {
+		return nil, goresterr.NewAPIError(goresterr.ServerError,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageMarshalFailed, goresterr.Params{"reason": err.Error()}))
+	}
+
+	patched := reflect.New(reflect.TypeOf(s.resourceKind)).Interface().(resource.Resource)
+	if err := json.Unmarshal(data, patched); err != nil {
+		return nil, goresterr.NewAPIError(goresterr.InvalidBodyContent,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageInvalidPatchedResource, goresterr.Params{"reason": err.Error()}))
+	}
+
+	s.RestoreReadOnlyFields(patched, current)
+	if s.fields != nil {
+		raw := make(map[string]interface{})
+		for _, k := range touched {
+			if s.access.IsReadOnly(k) == false {
+				raw[k] = obj[k]
+			}
+		}
+		if err := s.fields.ValidateSpecified(patched, raw); err != nil {
+			return nil, fieldValidateError(err)
+		}
+	}
+
+	patched.SetID(r.GetID())
+	patched.SetType(r.GetType())
+	patched.SetParent(r.GetParent())
+	patched.SetSchema(s)
+	patched.SetCreationTimestamp(current.GetCreationTimestamp())
+	if err := s.CheckImmutableFields(patched, current); err != nil {
+		return nil, err
+	}
+	if err := validateResource(patched, http.MethodPatch); err != nil {
+		return nil, err
+	}
+	return patched, nil
+}
+
+// return the patched document and the top level keys touched by the patch,
+// nil keys means the whole document is replaced
+func applyPatchToDocument(doc interface{}, patch *resource.Patch) (interface{}, []string, error) {
+	switch patch.Type {
+	case resource.JSONPatch:
+		ops, err := util.ParseJSONPatch(patch.Data)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		touched := make([]string, 0, len(ops))
+		wholeDocument := false
+		for _, op := range ops {
+			pointers := []string{op.Path}
+			if op.Op == util.JSONPatchOpMove {
+				pointers = append(pointers, op.From)
+			}
+
+			for _, pointer := range pointers {
+				if tokens, _ := util.JSONPointerTokens(pointer); len(tokens) == 0 {
+					wholeDocument = true
+				} else {
+					touched = append(touched, tokens[0])
+				}
+			}
+		}
+
+		doc, err = util.ApplyJSONPatch(doc, ops)
+		if err != nil {
+			return nil, nil, fmt.Errorf("apply json patch failed:%s", err.Error())
+		}
+
+		if wholeDocument {
+			touched = nil
+		}
+		return doc, touched, nil
+	default:
+		var patchDoc map[string]interface{}
+		if err := json.Unmarshal(patch.Data, &patchDoc); err != nil {
+			return nil, nil, fmt.Errorf("merge patch isn't a json object:%s", err.Error())
+		}
+
+		touched := make([]string, 0, len(patchDoc))
+		for k := range patchDoc {
+			touched = append(touched, k)
+		}
+		return util.MergePatch(doc, patchDoc), touched, nil
+	}
+}
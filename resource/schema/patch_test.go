@@ -0,0 +1,129 @@
+package schema
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	ut "github.com/linkingthing/cement/unittest"
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+)
+
+type Volume struct {
+	resource.ResourceBase `json:",inline"`
+	Name                  string            `json:"name" rest:"required=true,minLen=2,maxLen=10"`
+	Driver                string            `json:"driver" rest:"required=true,options=lvm|ceph"`
+	Size                  int               `json:"size" rest:"min=1,max=100"`
+	Labels                map[string]string `json:"labels,omitempty"`
+	Host                  string            `json:"host,omitempty" rest:"isIPv4=true"`
+	Selector              string            `json:"selector,omitempty" rest:"minLen=1,pattern=^[a-z]+(,[a-z]+)*$"`
+}
+
+func (v Volume) GetParents() []resource.ResourceKind {
+	return []resource.ResourceKind{Cluster{}}
+}
+
+type volumeHandler struct{}
+
+func (h *volumeHandler) Get(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	return nil, nil
+}
+
+func (h *volumeHandler) Patch(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	return ctx.Resource, nil
+}
+
+func createVolumeSchemaManager() *SchemaManager {
+	mgr := NewSchemaManager()
+	mgr.MustImport(&version, Cluster{}, &resource.DumbHandler{})
+	mgr.MustImport(&version, Volume{}, &volumeHandler{})
+	return mgr
+}
+
+func TestApplyPatch(t *testing.T) {
+	mgr := createVolumeSchemaManager()
+	current := &Volume{
+		Name:   "v1",
+		Driver: "lvm",
+		Size:   10,
+		Labels: map[string]string{"app": "db", "tier": "backend"},
+	}
+	//invalid size in current resource is ignored if patch doesn't touch it
+	currentWithInvalidSize := &Volume{
+		Name:   "v1",
+		Driver: "lvm",
+		Size:   1000,
+	}
+
+	cases := []struct {
+		contentType string
+		patch       string
+		current     *Volume
+		expect      *Volume
+		isValid     bool
+	}{
+		{"", `{"size":20}`, current,
+			&Volume{Name: "v1", Driver: "lvm", Size: 20, Labels: map[string]string{"app": "db", "tier": "backend"}}, true},
+		{"application/merge-patch+json", `{"labels":{"tier":null,"zone":"a"}}`, current,
+			&Volume{Name: "v1", Driver: "lvm", Size: 10, Labels: map[string]string{"app": "db", "zone": "a"}}, true},
+		{"application/json", `{"name":"v2"}`, currentWithInvalidSize,
+			&Volume{Name: "v2", Driver: "lvm", Size: 1000}, true},
+		{"application/json-patch+json", `[{"op":"replace","path":"/driver","value":"ceph"},{"op":"remove","path":"/labels/app"}]`, current,
+			&Volume{Name: "v1", Driver: "ceph", Size: 10, Labels: map[string]string{"tier": "backend"}}, true},
+		{"", `{"size":200}`, current, nil, false},
+		{"", `{"driver":"nfs"}`, current, nil, false},
+		{"", `{"name":null}`, current, nil, false},
+		{"application/json-patch+json", `[{"op":"remove","path":"/driver"}]`, current, nil, false},
+		{"application/json-patch+json", `[{"op":"test","path":"/size","value":11}]`, current, nil, false},
+	}
+
+	for _, tc := range cases {
+		url := "/apis/testing/v1/clusters/c1/volumes/v1"
+		req, _ := http.NewRequest(http.MethodPatch, url, bytes.NewBufferString(tc.patch))
+		if tc.contentType != "" {
+			req.Header.Set("Content-Type", tc.contentType)
+		}
+		r, err := mgr.CreateResourceFromRequest(req)
+		ut.Assert(t, err == nil, "parse patch %s failed %v", tc.patch, err)
+		ut.Assert(t, r.GetPatch() != nil, "")
+
+		patched, err := r.GetSchema().ApplyPatch(r, tc.current)
+		if tc.isValid {
+			ut.Assert(t, err == nil, "apply patch %s failed %v", tc.patch, err)
+			volume := patched.(*Volume)
+			ut.Equal(t, volume.GetID(), "v1")
+			ut.Equal(t, volume.GetParent().GetID(), "c1")
+			ut.Equal(t, volume.Name, tc.expect.Name)
+			ut.Equal(t, volume.Driver, tc.expect.Driver)
+			ut.Equal(t, volume.Size, tc.expect.Size)
+			ut.Equal(t, volume.Labels, tc.expect.Labels)
+		} else {
+			ut.Assert(t, err != nil, "patch %s should fail", tc.patch)
+		}
+	}
+}
+
+func TestParsePatch(t *testing.T) {
+	mgr := createVolumeSchemaManager()
+	cases := []struct {
+		url         string
+		contentType string
+		patch       string
+	}{
+		{"/apis/testing/v1/clusters/c1/volumes", "", `{"size":20}`},
+		{"/apis/testing/v1/clusters/c1/volumes/v1", "text/plain", `{"size":20}`},
+		{"/apis/testing/v1/clusters/c1/volumes/v1", "", `[{"op":"add","path":"/size","value":20}]`},
+		{"/apis/testing/v1/clusters/c1/volumes/v1", "application/json-patch+json", `{"size":20}`},
+		{"/apis/testing/v1/clusters/c1/volumes/v1", "application/json-patch+json", `[{"op":"append","path":"/size","value":20}]`},
+	}
+
+	for _, tc := range cases {
+		req, _ := http.NewRequest(http.MethodPatch, tc.url, bytes.NewBufferString(tc.patch))
+		if tc.contentType != "" {
+			req.Header.Set("Content-Type", tc.contentType)
+		}
+		_, err := mgr.CreateResourceFromRequest(req)
+		ut.Assert(t, err != nil, "patch %s to %s should fail", tc.patch, tc.url)
+	}
+}
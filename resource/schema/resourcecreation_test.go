@@ -3,10 +3,17 @@ package schema
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/netip"
+	"strings"
 	"testing"
 
 	ut "github.com/linkingthing/cement/unittest"
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+	"github.com/linkingthing/gorest/resource/schema/resourcefield"
+	"github.com/linkingthing/gorest/resource/schema/resourcefield/validator"
 )
 
 type podGenJson struct {
@@ -233,3 +240,239 @@ func TestAction(t *testing.T) {
 	_, err = mgr.CreateResourceFromRequest(req)
 	ut.Assert(t, err != nil, "")
 }
+
+type Placement struct {
+	NodeName string `json:"nodeName" rest:"required=true,minLen=2"`
+	Policy   string `json:"policy,omitempty" rest:"options=drain|force"`
+}
+
+type Workload struct {
+	resource.ResourceBase `json:",inline"`
+	Name                  string `json:"name"`
+}
+
+func (w Workload) GetParents() []resource.ResourceKind {
+	return []resource.ResourceKind{Cluster{}}
+}
+
+func (w Workload) GetActions() []resource.Action {
+	return []resource.Action{
+		resource.Action{
+			Name:  "move",
+			Input: &Placement{},
+		},
+	}
+}
+
+const workloadMoveUrl = "/apis/testing/v1/clusters/c1/workloads/w1?action=move"
+
+func createWorkloadSchemaManager() *SchemaManager {
+	mgr := NewSchemaManager()
+	mgr.MustImport(&version, Cluster{}, &resource.DumbHandler{})
+	mgr.MustImport(&version, Workload{}, &resource.DumbHandler{})
+	return mgr
+}
+
+func TestActionInputValidation(t *testing.T) {
+	mgr := createWorkloadSchemaManager()
+	req, _ := http.NewRequest(http.MethodPost, workloadMoveUrl, bytes.NewBufferString(`{"nodeName":"n1","policy":"drain"}`))
+	r, err := mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err == nil, "get err:%v", err)
+	ut.Equal(t, r.GetAction().Input.(*Placement).Policy, "drain")
+
+	for _, tc := range []struct {
+		body  string
+		code  goresterr.ErrorCode
+		field string
+	}{
+		{`{"policy":"drain"}`, goresterr.MissingRequired, "nodeName"},
+		{`{"nodeName":"n"}`, goresterr.MinLengthExceeded, "nodeName"},
+		{`{"nodeName":"n1","policy":"stop"}`, goresterr.InvalidOption, "policy"},
+	} {
+		req, _ = http.NewRequest(http.MethodPost, workloadMoveUrl, bytes.NewBufferString(tc.body))
+		_, err = mgr.CreateResourceFromRequest(req)
+		ut.Assert(t, err != nil, "action input %s should be invalid", tc.body)
+		ut.Equal(t, err.ErrorCode, tc.code)
+		ut.Assert(t, strings.Contains(err.Message, "field "+tc.field), "%s doesn't name the field", err.Message)
+	}
+}
+
+type Subnet struct {
+	resource.ResourceBase `json:",inline"`
+	StartIP               string `json:"startIP" rest:"required=true,isIPv4"`
+	EndIP                 string `json:"endIP" rest:"required=true,isIPv4"`
+	Gateway               string `json:"gateway,omitempty" rest:"validate=privateIPv4"`
+}
+
+func (s Subnet) GetParents() []resource.ResourceKind {
+	return []resource.ResourceKind{Cluster{}}
+}
+
+func (s *Subnet) Validate(method string) error {
+	if s.GetParent().GetID() == "locked" {
+		return goresterr.NewAPIError(goresterr.PermissionDenied,
+			goresterr.ErrorMessage{MessageEN: method + " subnet in locked cluster"})
+	}
+
+	start, _ := netip.ParseAddr(s.StartIP)
+	end, _ := netip.ParseAddr(s.EndIP)
+	if end.Less(start) {
+		return &resourcefield.FieldError{Path: "endIP", Err: fmt.Errorf("endIP should >= startIP")}
+	}
+	return nil
+}
+
+type subnetHandler struct{}
+
+func (h *subnetHandler) Create(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	return ctx.Resource, nil
+}
+
+func (h *subnetHandler) Update(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	return ctx.Resource, nil
+}
+
+func (h *subnetHandler) Get(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	return nil, nil
+}
+
+func (h *subnetHandler) Patch(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	return ctx.Resource, nil
+}
+
+func TestResourceValidator(t *testing.T) {
+	validator.MustRegisterValidator("privateIPv4", func(v interface{}) error {
+		if addr, err := netip.ParseAddr(v.(string)); err != nil || addr.Is4() == false || addr.IsPrivate() == false {
+			return fmt.Errorf("%v isn't private ipv4 address", v)
+		}
+		return nil
+	})
+
+	mgr := NewSchemaManager()
+	mgr.MustImport(&version, Cluster{}, &resource.DumbHandler{})
+	mgr.MustImport(&version, Subnet{}, &subnetHandler{})
+
+	cases := []struct {
+		method string
+		url    string
+		body   string
+		code   *goresterr.ErrorCode
+		field  string
+		rule   string
+	}{
+		{http.MethodPost, "/apis/testing/v1/clusters/c1/subnets", `{"startIP":"10.0.0.1","endIP":"10.0.0.9","gateway":"10.0.0.254"}`, nil, "", ""},
+		{http.MethodPost, "/apis/testing/v1/clusters/c1/subnets", `{"startIP":"10.0.0.9","endIP":"10.0.0.1"}`, &goresterr.InvalidBodyContent, "endIP", ""},
+		{http.MethodPut, "/apis/testing/v1/clusters/c1/subnets/s1", `{"startIP":"10.0.0.9","endIP":"10.0.0.1"}`, &goresterr.InvalidBodyContent, "endIP", ""},
+		{http.MethodPost, "/apis/testing/v1/clusters/locked/subnets", `{"startIP":"10.0.0.1","endIP":"10.0.0.9"}`, &goresterr.PermissionDenied, "", ""},
+		{http.MethodPost, "/apis/testing/v1/clusters/c1/subnets", `{"startIP":"10.0.0.1","endIP":"10.0.0.9","gateway":"8.8.8.8"}`, &goresterr.InvalidBodyContent, "gateway", validator.RuleValidate},
+		//tag validation fails first
+		{http.MethodPost, "/apis/testing/v1/clusters/c1/subnets", `{"startIP":"10.0.0.9","endIP":"10.0.0.1000"}`, &goresterr.InvalidFormat, "endIP", validator.RuleIsIPv4},
+	}
+
+	for _, c := range cases {
+		req, _ := http.NewRequest(c.method, c.url, bytes.NewBufferString(c.body))
+		_, err := mgr.CreateResourceFromRequest(req)
+		if c.code == nil {
+			ut.Assert(t, err == nil, "%s %s should succeed: %v", c.method, c.body, err)
+			continue
+		}
+
+		ut.Assert(t, err != nil, "%s %s should fail", c.method, c.body)
+		ut.Equal(t, err.ErrorCode, *c.code)
+		if c.field != "" {
+			ut.Equal(t, len(err.Details), 1)
+			ut.Equal(t, err.Details[0].Field, c.field)
+			ut.Equal(t, err.Details[0].Rule, c.rule)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodPatch, "/apis/testing/v1/clusters/c1/subnets/s1", bytes.NewBufferString(`{"endIP":"10.0.0.1"}`))
+	r, err := mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err == nil, "")
+	_, err = r.GetSchema().ApplyPatch(r, &Subnet{StartIP: "10.0.0.5", EndIP: "10.0.0.9"})
+	ut.Assert(t, err != nil, "")
+	ut.Equal(t, err.Details[0].Field, "endIP")
+	ut.Equal(t, err.Details[0].MessageEN, "field endIP: endIP should >= startIP")
+	ut.Equal(t, err.Details[0].MessageCN, "字段[endIP]的值不合法")
+}
+
+type Pool struct {
+	resource.ResourceBase `json:",inline"`
+	Name                  string            `json:"name" rest:"required=true"`
+	Lease                 int               `json:"lease" rest:"default=3600,min=60"`
+	Servers               []string          `json:"servers" rest:"default=10.0.0.2|10.0.0.3,isIPv4"`
+	Labels                map[string]string `json:"labels" rest:"default=env:prod"`
+	Owner                 string            `json:"owner"`
+}
+
+func (p Pool) GetParents() []resource.ResourceKind {
+	return []resource.ResourceKind{Cluster{}}
+}
+
+func (p Pool) CreateDefaultResource() resource.Resource {
+	return &Pool{Owner: "admin"}
+}
+
+func (p Pool) GetActions() []resource.Action {
+	return []resource.Action{
+		resource.Action{
+			Name:  "resize",
+			Input: &Pool{},
+		},
+	}
+}
+
+type poolHandler struct {
+	subnetHandler
+}
+
+func (h *poolHandler) Action(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+	return nil, nil
+}
+
+func TestTagDefaultValue(t *testing.T) {
+	mgr := NewSchemaManager()
+	mgr.MustImport(&version, Cluster{}, &resource.DumbHandler{})
+	mgr.MustImport(&version, Pool{}, &poolHandler{})
+
+	cases := []struct {
+		method string
+		url    string
+		body   string
+		expect Pool
+	}{
+		{http.MethodPost, "/apis/testing/v1/clusters/c1/pools", `{"name":"p1"}`,
+			Pool{Name: "p1", Lease: 3600, Servers: []string{"10.0.0.2", "10.0.0.3"}, Labels: map[string]string{"env": "prod"}, Owner: "admin"}},
+		//map isn't merged with the default value
+		{http.MethodPost, "/apis/testing/v1/clusters/c1/pools", `{"name":"p1","lease":120,"servers":[],"labels":{"tier":"web"},"owner":"root"}`,
+			Pool{Name: "p1", Lease: 120, Servers: []string{}, Labels: map[string]string{"tier": "web"}, Owner: "root"}},
+		//null is same with not specified
+		{http.MethodPut, "/apis/testing/v1/clusters/c1/pools/p1", `{"name":"p1","labels":null}`,
+			Pool{Name: "p1", Lease: 3600, Servers: []string{"10.0.0.2", "10.0.0.3"}, Labels: map[string]string{"env": "prod"}, Owner: "admin"}},
+	}
+
+	for _, c := range cases {
+		req, _ := http.NewRequest(c.method, c.url, bytes.NewBufferString(c.body))
+		r, err := mgr.CreateResourceFromRequest(req)
+		ut.Assert(t, err == nil, "%s %s should succeed: %v", c.method, c.body, err)
+		p := r.(*Pool)
+		ut.Equal(t, p.Name, c.expect.Name)
+		ut.Equal(t, p.Lease, c.expect.Lease)
+		ut.Equal(t, p.Servers, c.expect.Servers)
+		ut.Equal(t, p.Labels, c.expect.Labels)
+		ut.Equal(t, p.Owner, c.expect.Owner)
+	}
+
+	//patch doesn't fill default value
+	req, _ := http.NewRequest(http.MethodPatch, "/apis/testing/v1/clusters/c1/pools/p1", bytes.NewBufferString(`{"name":"p2"}`))
+	r, err := mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, r.(*Pool).Lease, 0)
+
+	req, _ = http.NewRequest(http.MethodPost, "/apis/testing/v1/clusters/c1/pools/p1?action=resize", bytes.NewBufferString(`{"name":"p1","lease":7200}`))
+	r, err = mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err == nil, "action should succeed: %v", err)
+	input := r.GetAction().Input.(*Pool)
+	ut.Equal(t, input.Lease, 7200)
+	ut.Equal(t, input.Servers, []string{"10.0.0.2", "10.0.0.3"})
+}
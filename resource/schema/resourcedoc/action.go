@@ -11,6 +11,7 @@ type ResourceAction struct {
 	Name         string                    `json:"name"`
 	Input        ResourceFields            `json:"input,omitempty"`
 	Output       ResourceFields            `json:"output,omitempty"`
+	OutputType   *ResourceField            `json:"outputType,omitempty"`
 	SubResources map[string]ResourceFields `json:"subResources,omitempty"`
 }
 
@@ -32,15 +33,23 @@ func genActions(kind resource.ResourceKind) ([]ResourceAction, error) {
 				return nil, fmt.Errorf("kind %s action %s input must be struct", reflect.TypeOf(kind).Name(), action.Name)
 			}
 		}
+		//output could be struct, basic type, or slice and map of them
 		if action.Output != nil {
-			if t := getStructType(reflect.TypeOf(action.Output)); t != nil {
+			typ := reflect.TypeOf(action.Output)
+			if typ.Kind() == reflect.Ptr && typ.Elem().Kind() != reflect.Struct {
+				typ = typ.Elem()
+			}
+			outputType, err := buildResourceField(typ, "")
+			if err != nil {
+				return nil, fmt.Errorf("kind %s action %s output has %s", reflect.TypeOf(kind).Name(), action.Name, err.Error())
+			}
+			resourceAction.OutputType = &outputType
+			if t := getStructType(typ); t != nil {
 				resourceFields, err := buildResourceFields(resourceAction.SubResources, t)
 				if err != nil {
 					return nil, err
 				}
 				resourceAction.Output = resourceFields
-			} else {
-				return nil, fmt.Errorf("kind %s action %s output must be struct", reflect.TypeOf(kind).Name(), action.Name)
 			}
 		}
 		resourceActions = append(resourceActions, resourceAction)
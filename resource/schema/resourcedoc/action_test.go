@@ -10,6 +10,8 @@ import (
 const (
 	ActionLogin    = "actionLogin"
 	ActionOnlyName = "onlyName"
+	ActionList     = "list"
+	ActionCount    = "count"
 )
 
 type Action struct {
@@ -62,6 +64,14 @@ func (a Action) GetActions() []resource.Action {
 		resource.Action{
 			Name: ActionOnlyName,
 		},
+		resource.Action{
+			Name:   ActionList,
+			Output: []*LoginInfo{},
+		},
+		resource.Action{
+			Name:   ActionCount,
+			Output: new(int),
+		},
 	}
 }
 
@@ -90,15 +100,16 @@ func TestIngressRuleValidate(t *testing.T) {
 					Input: ResourceFields{
 						"mapStringInt8":   ResourceField{Type: "map", KeyType: "string", ValueType: "int"},
 						"mapStringStruct": ResourceField{Type: "map", KeyType: "string", ValueType: "struct"},
-						"structPtr":       ResourceField{Type: "struct"},
+						"structPtr":       ResourceField{Type: "struct", Pointer: true},
 						"sliceStructPtr":  ResourceField{Type: "array", ElemType: "struct"},
 					},
 					Output: ResourceFields{
 						"uint32":          ResourceField{Type: "uint"},
 						"mapStringString": ResourceField{Type: "map", KeyType: "string", ValueType: "string"},
 						"mapStringInt":    ResourceField{Type: "map", KeyType: "string", ValueType: "int"},
-						"boolPtr":         ResourceField{Type: "bool"},
+						"boolPtr":         ResourceField{Type: "bool", Pointer: true},
 					},
+					OutputType: &ResourceField{Type: "loginInfo"},
 					SubResources: map[string]ResourceFields{
 						"struct2": map[string]ResourceField{
 							"Id": ResourceField{Type: "int"},
@@ -118,8 +129,24 @@ func TestIngressRuleValidate(t *testing.T) {
 					Name:         "onlyName",
 					SubResources: map[string]ResourceFields{},
 				},
+				ResourceAction{
+					Name: "list",
+					Output: ResourceFields{
+						"uint32":          ResourceField{Type: "uint"},
+						"mapStringString": ResourceField{Type: "map", KeyType: "string", ValueType: "string"},
+						"mapStringInt":    ResourceField{Type: "map", KeyType: "string", ValueType: "int"},
+						"boolPtr":         ResourceField{Type: "bool", Pointer: true},
+					},
+					OutputType:   &ResourceField{Type: "array", ElemType: "loginInfo"},
+					SubResources: map[string]ResourceFields{},
+				},
+				ResourceAction{
+					Name:         "count",
+					OutputType:   &ResourceField{Type: "int"},
+					SubResources: map[string]ResourceFields{},
+				},
 			},
-			2,
+			4,
 		},
 		{
 			ActionErr{},
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path"
 	"reflect"
+	"strconv"
 	"strings"
 
 	slice "github.com/linkingthing/cement/slice"
@@ -17,6 +18,11 @@ import (
 const (
 	requiredTag    = "required"
 	isDomainTag    = "isDomain"
+	patternTag     = "pattern="
+	defaultTag     = "default="
+	readOnlyTag    = "readOnly"
+	immutableTag   = "immutable"
+	writeOnlyTag   = "writeOnly"
 	optionsTag     = "options="
 	descriptionTag = "description="
 	docFileSuffix  = ".json"
@@ -48,8 +54,20 @@ type ResourceField struct {
 	KeyType     string   `json:"keyType,omitempty"`
 	ValueType   string   `json:"valueType,omitempty"`
 	Description []string `json:"description,omitempty"`
+	Pattern     string   `json:"pattern,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	ReadOnly    bool     `json:"readOnly,omitempty"`
+	Immutable   bool     `json:"immutable,omitempty"`
+	WriteOnly   bool     `json:"writeOnly,omitempty"`
+	//go field is a pointer, or its json tag has omitempty, they are
+	//kept by the generated client
+	Pointer   bool `json:"pointer,omitempty"`
+	OmitEmpty bool `json:"omitEmpty,omitempty"`
 }
 
+// format validators are added to description with their names
+var formatTags = []string{"isIPv4", "isIPv6", "isCIDR", "isMAC", "isEmail", "isURL", "isUUID"}
+
 func NewResourceDocument(name string, kind resource.ResourceKind, handler resource.Handler, parents []string) (*ResourceDocument, error) {
 	resource := &ResourceDocument{
 		ResourceType:       name,
@@ -103,6 +121,8 @@ func buildResourceFields(subResources map[string]ResourceFields, t reflect.Type)
 		if resourceField, err := buildResourceField(typ, tag); err != nil {
 			return nil, fmt.Errorf("field %s has %s", name, err.Error())
 		} else {
+			resourceField.Pointer = typ.Kind() == reflect.Ptr
+			resourceField.OmitEmpty = slice.SliceIndex(strings.Split(tag.Get("json"), ","), "omitempty") > 0
 			resourceFields[jsonName] = resourceField
 		}
 
@@ -124,6 +144,11 @@ func buildResourceField(t reflect.Type, tag reflect.StructTag) (ResourceField, e
 	resourceField := ResourceField{
 		Type:        typ,
 		Description: parseTag(tag, false),
+		Pattern:     parsePattern(tag),
+		Default:     parseDefault(tag),
+		ReadOnly:    hasBoolTag(tag, readOnlyTag),
+		Immutable:   hasBoolTag(tag, immutableTag),
+		WriteOnly:   hasBoolTag(tag, writeOnlyTag),
 	}
 	if !ignore {
 		if valueRange := parseTag(tag, true); len(valueRange) > 0 {
@@ -155,7 +180,7 @@ func buildResourceField(t reflect.Type, tag reflect.StructTag) (ResourceField, e
 
 func parseTag(tag reflect.StructTag, isOptions bool) []string {
 	var tags []string
-	restTags := strings.Split(tag.Get("rest"), ",")
+	restTags := util.SplitRestTag(tag.Get("rest"))
 	for _, t := range restTags {
 		if isOptions {
 			if strings.HasPrefix(t, optionsTag) {
@@ -172,7 +197,42 @@ func parseTag(tag reflect.StructTag, isOptions bool) []string {
 			if strings.HasPrefix(t, isDomainTag) {
 				tags = append(tags, isDomainTag)
 			}
+			if name, value, hasValue := strings.Cut(t, "="); slice.SliceIndex(formatTags, name) != -1 {
+				if enabled, _ := strconv.ParseBool(value); hasValue == false || enabled {
+					tags = append(tags, name)
+				}
+			}
 		}
 	}
 	return tags
 }
+
+func parsePattern(tag reflect.StructTag) string {
+	for _, t := range util.SplitRestTag(tag.Get("rest")) {
+		if strings.HasPrefix(t, patternTag) {
+			return strings.TrimPrefix(t, patternTag)
+		}
+	}
+	return ""
+}
+
+// default value is kept as it's written in tag, like a|b for slice
+func parseDefault(tag reflect.StructTag) string {
+	for _, t := range util.SplitRestTag(tag.Get("rest")) {
+		if strings.HasPrefix(t, defaultTag) {
+			return strings.TrimPrefix(t, defaultTag)
+		}
+	}
+	return ""
+}
+
+// bool tag like readOnly or readOnly=true
+func hasBoolTag(tag reflect.StructTag, name string) bool {
+	for _, t := range util.SplitRestTag(tag.Get("rest")) {
+		if tagName, value, hasValue := strings.Cut(t, "="); tagName == name {
+			enabled, _ := strconv.ParseBool(value)
+			return hasValue == false || enabled
+		}
+	}
+	return false
+}
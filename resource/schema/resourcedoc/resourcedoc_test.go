@@ -0,0 +1,91 @@
+package resourcedoc
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	ut "github.com/linkingthing/cement/unittest"
+)
+
+type Interface struct {
+	Address  string   `json:"address" rest:"required=true,isIPv4"`
+	Subnets  []string `json:"subnets" rest:"isCIDR=true"`
+	Mac      string   `json:"mac" rest:"isMAC=false"`
+	Name     string   `json:"name" rest:"description=nic name,pattern=^[a-z]+(,[a-z]+)*$"`
+	Contacts []string `json:"contacts" rest:"isEmail,isURL"`
+	Serial   string   `json:"serial" rest:"readOnly"`
+	Vendor   string   `json:"vendor" rest:"required=true,immutable=true"`
+	Secret   string   `json:"secret" rest:"writeOnly,readOnly=false"`
+}
+
+func TestFieldValidators(t *testing.T) {
+	typ := reflect.TypeOf(Interface{})
+	expects := map[string]ResourceField{
+		"Address":  {Type: "string", Description: []string{"required", "isIPv4"}},
+		"Subnets":  {Type: "array", ElemType: "string", Description: []string{"isCIDR"}},
+		"Mac":      {Type: "string"},
+		"Name":     {Type: "string", Description: []string{"nic name"}, Pattern: "^[a-z]+(,[a-z]+)*$"},
+		"Contacts": {Type: "array", ElemType: "string", Description: []string{"isEmail", "isURL"}},
+		"Serial":   {Type: "string", ReadOnly: true},
+		"Vendor":   {Type: "string", Description: []string{"required"}, Immutable: true},
+		"Secret":   {Type: "string", WriteOnly: true},
+	}
+
+	for name, expect := range expects {
+		f, _ := typ.FieldByName(name)
+		field, err := buildResourceField(f.Type, f.Tag)
+		ut.Assert(t, err == nil, "")
+		ut.Equal(t, field, expect)
+	}
+}
+
+type Lease struct {
+	Ratio    float64            `json:"ratio"`
+	Expire   time.Time          `json:"expire"`
+	Address  netip.Addr         `json:"address"`
+	Comment  *string            `json:"comment"`
+	Servers  []netip.Addr       `json:"servers" rest:"default=10.0.0.1|10.0.0.2"`
+	Scores   map[string]float32 `json:"scores"`
+	Priority *int               `json:"priority" rest:"default=5"`
+}
+
+func TestLeafFieldTypes(t *testing.T) {
+	typ := reflect.TypeOf(Lease{})
+	expects := map[string]ResourceField{
+		"Ratio":    {Type: "float"},
+		"Expire":   {Type: "time"},
+		"Address":  {Type: "ip"},
+		"Comment":  {Type: "string"},
+		"Servers":  {Type: "array", ElemType: "ip", Default: "10.0.0.1|10.0.0.2"},
+		"Scores":   {Type: "map", KeyType: "string", ValueType: "float"},
+		"Priority": {Type: "int", Default: "5"},
+	}
+
+	for name, expect := range expects {
+		f, _ := typ.FieldByName(name)
+		field, err := buildResourceField(f.Type, f.Tag)
+		ut.Assert(t, err == nil, "")
+		ut.Equal(t, field, expect)
+	}
+
+	subResources := make(map[string]ResourceFields)
+	_, err := buildResourceFields(subResources, typ)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, len(subResources), 0)
+}
+
+type LeaseSpec struct {
+	Lease   *Lease  `json:"lease,omitempty"`
+	Comment *string `json:"comment"`
+	Tag     string  `json:"tag,omitempty"`
+}
+
+func TestPointerAndOmitEmptyFields(t *testing.T) {
+	fields, err := buildResourceFields(make(map[string]ResourceFields), reflect.TypeOf(LeaseSpec{}))
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, fields["lease"], ResourceField{Type: "lease", Pointer: true, OmitEmpty: true})
+	ut.Equal(t, fields["comment"], ResourceField{Type: "string", Pointer: true})
+	ut.Equal(t, fields["tag"], ResourceField{Type: "string", OmitEmpty: true})
+}
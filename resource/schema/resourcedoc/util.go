@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/linkingthing/gorest/resource"
 	"github.com/linkingthing/gorest/util"
 )
 
@@ -40,16 +41,20 @@ func getStructType(typ reflect.Type) reflect.Type {
 
 func getType(t reflect.Type) string {
 	switch k := util.Inspect(t); k {
-	case util.String, util.Int, util.Uint, util.Bool:
+	case util.String, util.Int, util.Uint, util.Bool, util.Float, util.Time, util.IP:
 		return string(k)
-	case util.StringIntMap, util.StringStringMap, util.StringUintMap, util.StringStructMap, util.StringStructPtrMap:
+	case util.StringIntMap, util.StringStringMap, util.StringUintMap, util.StringFloatMap, util.StringIPMap,
+		util.StringStructMap, util.StringStructPtrMap:
 		return Map
-	case util.IntSlice, util.UintSlice, util.BoolSlice, util.StringSlice, util.StructSlice, util.StructPtrSlice:
+	case util.IntSlice, util.UintSlice, util.BoolSlice, util.StringSlice, util.FloatSlice, util.TimeSlice, util.IPSlice,
+		util.StructSlice, util.StructPtrSlice:
 		return Array
 	case util.Struct:
 		return LowerFirstCharacter(t.Name())
-	case util.StructPtr, util.BoolPtr:
+	case util.StructPtr:
 		return LowerFirstCharacter(t.Elem().Name())
+	case util.BoolPtr, util.IntPtr, util.UintPtr, util.StringPtr, util.FloatPtr, util.TimePtr, util.IPPtr:
+		return getType(t.Elem())
 	default:
 		return Unknow
 	}
@@ -61,6 +66,12 @@ func getElemType(t reflect.Type) string {
 		return string(util.Int)
 	case util.UintSlice, util.StringUintMap:
 		return string(util.Uint)
+	case util.FloatSlice, util.StringFloatMap:
+		return string(util.Float)
+	case util.TimeSlice:
+		return string(util.Time)
+	case util.IPSlice, util.StringIPMap:
+		return string(util.IP)
 	case util.StructSlice, util.BoolSlice, util.StringSlice, util.StringStringMap, util.StringStructMap:
 		return LowerFirstCharacter(t.Elem().Name())
 	case util.StructPtrSlice, util.StringStructPtrMap:
@@ -88,3 +99,40 @@ func fieldJsonName(name string, tag reflect.StructTag) string {
 	}
 	return name
 }
+
+// FieldNames return json names of the resource fields, fields of
+// embedded structs without json name like ResourceBase are included
+func FieldNames(kind resource.ResourceKind) []string {
+	return fieldNames(reflect.TypeOf(kind))
+}
+
+func fieldNames(t reflect.Type) []string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous == false && field.IsExported() == false {
+			continue
+		}
+
+		jsonName := fieldJsonName(field.Name, field.Tag)
+		if jsonName == ignoreJsonName {
+			continue
+		}
+
+		if field.Anonymous && (jsonName == "" || jsonName == ignoreJsonFlag || jsonName == field.Name) {
+			if ft := field.Type; ft.Kind() == reflect.Struct || (ft.Kind() == reflect.Ptr && ft.Elem().Kind() == reflect.Struct) {
+				names = append(names, fieldNames(ft)...)
+				continue
+			}
+		}
+
+		if field.IsExported() {
+			names = append(names, jsonName)
+		}
+	}
+	return names
+}
@@ -0,0 +1,26 @@
+package resourcedoc
+
+import (
+	"testing"
+
+	ut "github.com/linkingthing/cement/unittest"
+	"github.com/linkingthing/gorest/resource"
+)
+
+type Embedded struct {
+	Zone string `json:"zone"`
+}
+
+type FieldNamesResource struct {
+	resource.ResourceBase `json:",inline"`
+	Embedded
+	Name    string `json:"name,omitempty"`
+	Address string
+	Secret  string `json:"-"`
+	private string
+}
+
+func TestFieldNames(t *testing.T) {
+	ut.Equal(t, FieldNames(FieldNamesResource{}),
+		[]string{"id", "type", "links", "creationTimestamp", "deletionTimestamp", "resourceVersion", "zone", "name", "Address"})
+}
@@ -0,0 +1,188 @@
+package resourcefield
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/linkingthing/gorest/resource"
+	"github.com/linkingthing/gorest/util"
+)
+
+const (
+	RuleReadOnly  = "readOnly"
+	RuleImmutable = "immutable"
+)
+
+const (
+	readOnlyTag  = "readOnly"
+	immutableTag = "immutable"
+	writeOnlyTag = "writeOnly"
+)
+
+// fields of ResourceBase which are set by server
+var readOnlyBaseFields = []string{"creationTimestamp", "deletionTimestamp"}
+
+var resourceBaseType = reflect.TypeOf(resource.ResourceBase{})
+
+// FieldAccess keep the top level fields of a resource with access tags,
+// readOnly fields are set by server, immutable fields can't be modified
+// after creation, writeOnly fields like password aren't returned to client
+type FieldAccess struct {
+	readOnly  []string
+	immutable []string
+	writeOnly []string
+	indexes   map[string][]int
+}
+
+var accesses sync.Map
+
+// AccessOf return the field access of the struct type, the result is
+// cached by type
+func AccessOf(typ reflect.Type) (*FieldAccess, error) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if a, ok := accesses.Load(typ); ok {
+		return a.(*FieldAccess), nil
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("field access on non-struct type")
+	}
+
+	a := &FieldAccess{indexes: make(map[string][]int)}
+	if err := a.addFields(typ, nil); err != nil {
+		return nil, err
+	}
+	accesses.Store(typ, a)
+	return a, nil
+}
+
+func (a *FieldAccess) addFields(typ reflect.Type, index []int) error {
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+		jsonName := fieldJsonName(sf.Name, sf.Tag.Get("json"))
+		if jsonName == "-" {
+			continue
+		} else if jsonName == "" {
+			jsonName = sf.Name
+		}
+
+		//embedded struct without json name shares the json object
+		if sf.Anonymous && jsonName == sf.Name {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if ft == resourceBaseType {
+					a.readOnly = append(a.readOnly, readOnlyBaseFields...)
+				}
+				if err := a.addFields(ft, fieldIndex); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		a.indexes[jsonName] = fieldIndex
+		for _, tag := range util.SplitRestTag(sf.Tag.Get("rest")) {
+			name, value, hasValue := strings.Cut(tag, "=")
+			if name != readOnlyTag && name != immutableTag && name != writeOnlyTag {
+				continue
+			}
+
+			enabled := true
+			if hasValue {
+				var err error
+				if enabled, err = strconv.ParseBool(value); err != nil {
+					return fmt.Errorf("invalid %s value %s of field %s", name, value, sf.Name)
+				}
+			}
+			if enabled == false {
+				continue
+			}
+
+			switch name {
+			case readOnlyTag:
+				a.readOnly = append(a.readOnly, jsonName)
+			case immutableTag:
+				a.immutable = append(a.immutable, jsonName)
+			case writeOnlyTag:
+				a.writeOnly = append(a.writeOnly, jsonName)
+			}
+		}
+	}
+
+	return a.check()
+}
+
+func (a *FieldAccess) check() error {
+	for _, name := range a.readOnly {
+		if a.IsWriteOnly(name) {
+			return fmt.Errorf("field %s can't be both readOnly and writeOnly", name)
+		}
+	}
+	return nil
+}
+
+func (a *FieldAccess) ReadOnlyFields() []string {
+	return a.readOnly
+}
+
+func (a *FieldAccess) ImmutableFields() []string {
+	return a.immutable
+}
+
+func (a *FieldAccess) WriteOnlyFields() []string {
+	return a.writeOnly
+}
+
+func (a *FieldAccess) IsReadOnly(jsonName string) bool {
+	return containsName(a.readOnly, jsonName)
+}
+
+func (a *FieldAccess) IsWriteOnly(jsonName string) bool {
+	return containsName(a.writeOnly, jsonName)
+}
+
+// FieldValue return the field with json name in the struct value, value
+// should be struct or pointer to struct, the returned value is invalid if
+// the field doesn't exist or is in a nil embedded struct
+func (a *FieldAccess) FieldValue(value reflect.Value, jsonName string) reflect.Value {
+	index, ok := a.indexes[jsonName]
+	if ok == false {
+		return reflect.Value{}
+	}
+
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+
+	v, err := value.FieldByIndexErr(index)
+	if err != nil {
+		return reflect.Value{}
+	}
+	return v
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
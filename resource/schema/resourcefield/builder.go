@@ -1,6 +1,7 @@
 package resourcefield
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strings"
@@ -76,19 +77,36 @@ func (b *FieldBuilder) buildField(sf reflect.StructField) error {
 func (b *FieldBuilder) createField(name string, typ reflect.Type, json, rest string) (Field, error) {
 	kind := util.Inspect(typ)
 	switch kind {
-	case util.Uint, util.Int, util.String, util.Bool:
+	case util.Uint, util.Int, util.String, util.Bool, util.Float, util.Time, util.IP:
 		if rest == "" {
 			return nil, nil
 		}
-		if restTags := strings.Split(rest, ","); len(restTags) > 0 {
-			return b.buildLeafField(name, typ, json, restTags)
+		if restTags := util.SplitRestTag(rest); len(restTags) > 0 {
+			f, err := b.buildLeafField(name, typ, json, restTags)
+			if err != nil {
+				return nil, err
+			}
+			return f, setFieldDefault(f, f, typ, restTags)
 		}
-	case util.StringIntMap, util.StringStringMap, util.StringUintMap, util.IntSlice, util.UintSlice, util.StringSlice:
+	case util.IntPtr, util.UintPtr, util.StringPtr, util.BoolPtr, util.FloatPtr, util.TimePtr, util.IPPtr:
 		if rest == "" {
 			return nil, nil
 		}
 
-		restTags := strings.Split(rest, ",")
+		restTags := util.SplitRestTag(rest)
+		f, err := b.buildLeafField(name, typ.Elem(), json, restTags)
+		if err != nil {
+			return nil, err
+		}
+		pf := newPtrLeafField(f)
+		return pf, setFieldDefault(pf, f, typ, restTags)
+	case util.StringIntMap, util.StringStringMap, util.StringUintMap, util.StringFloatMap, util.StringIPMap,
+		util.IntSlice, util.UintSlice, util.StringSlice, util.BoolSlice, util.FloatSlice, util.TimeSlice, util.IPSlice:
+		if rest == "" {
+			return nil, nil
+		}
+
+		restTags := util.SplitRestTag(rest)
 		if len(restTags) == 0 {
 			return nil, nil
 		}
@@ -98,18 +116,24 @@ func (b *FieldBuilder) createField(name string, typ reflect.Type, json, rest str
 			return nil, err
 		}
 
-		if kind == util.IntSlice || kind == util.UintSlice || kind == util.StringSlice {
-			return newSliceLeafField(f), nil
+		var field Field
+		if typ.Kind() == reflect.Slice {
+			field = newSliceLeafField(f)
 		} else {
-			return newMapLeafField(f), nil
+			field = newMapLeafField(f)
 		}
+		return field, setFieldDefault(field, f, typ, restTags)
 	case util.StructPtr:
 		return b.createField(name, typ.Elem(), json, rest)
 	case util.StringStructMap, util.StringStructPtrMap, util.StructSlice, util.StructPtrSlice:
+		if _, ok := defaultOfTags(util.SplitRestTag(rest)); ok {
+			return nil, fmt.Errorf("default value isn't supported by field %s", name)
+		}
+
 		var self Field
 		var err error
 		if rest != "" {
-			self, err = b.buildLeafField(name, typ, json, strings.Split(rest, ","))
+			self, err = b.buildLeafField(name, typ, json, util.SplitRestTag(rest))
 			if err != nil {
 				return nil, err
 			}
@@ -144,9 +168,13 @@ func (b *FieldBuilder) createField(name string, typ reflect.Type, json, rest str
 			return nil, err
 		}
 
+		if _, ok := defaultOfTags(util.SplitRestTag(rest)); ok {
+			return nil, fmt.Errorf("default value isn't supported by field %s", name)
+		}
+
 		if sf != nil {
 			self := newLeafField(name, fieldJsonName(name, json), typ.Kind())
-			if err := fieldParseOptional(self, typ.Kind(), strings.Split(rest, ",")); err != nil {
+			if err := fieldParseOptional(self, typ.Kind(), util.SplitRestTag(rest)); err != nil {
 				return nil, err
 			}
 			sf.Field = self
@@ -171,6 +199,38 @@ func (b *FieldBuilder) buildLeafField(name string, typ reflect.Type, json string
 	return field, nil
 }
 
+// default value is parsed with the field type, and should pass the
+// validators of the field
+func setFieldDefault(field Field, leaf *leafField, typ reflect.Type, restTags []string) error {
+	s, ok := defaultOfTags(restTags)
+	if ok == false {
+		return nil
+	}
+
+	if leaf.IsRequired() {
+		return fmt.Errorf("required field %s shouldn't have default value", leaf.Name())
+	}
+
+	v, err := parseDefault(typ, s)
+	if err != nil {
+		return fmt.Errorf("invalid default value of field %s: %s", leaf.Name(), err.Error())
+	}
+
+	var jsonVal interface{}
+	if data, err := json.Marshal(v.Interface()); err != nil {
+		return fmt.Errorf("marshal default value of field %s failed: %s", leaf.Name(), err.Error())
+	} else if err := json.Unmarshal(data, &jsonVal); err != nil {
+		return fmt.Errorf("unmarshal default value of field %s failed: %s", leaf.Name(), err.Error())
+	}
+
+	if err := field.Validate(v.Interface(), map[string]interface{}{leaf.JsonName(): jsonVal}); err != nil {
+		return fmt.Errorf("default value of field %s is invalid: %s", leaf.Name(), err.Error())
+	}
+
+	leaf.defaultValue = v
+	return nil
+}
+
 func (b *FieldBuilder) addField(field Field) error {
 	for _, old := range b.fields {
 		if old.Name() == field.Name() {
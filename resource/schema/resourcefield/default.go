@@ -0,0 +1,204 @@
+package resourcefield
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultTag          = "default="
+	defaultDelimiter    = "|"
+	defaultKeyDelimiter = ":"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// defaultField is implemented by the fields which may have default value,
+// default value is set to the go struct when the field isn't specified in
+// json data
+type defaultField interface {
+	fillDefault(value reflect.Value, raw map[string]interface{})
+}
+
+// value of default tag, elements of slice are separated by |, like
+// default=a|b, map entries are key:value separated by |, like default=a:1|b:2
+func defaultOfTags(restTags []string) (string, bool) {
+	for _, tag := range restTags {
+		if strings.HasPrefix(tag, defaultTag) {
+			return strings.TrimPrefix(tag, defaultTag), true
+		}
+	}
+	return "", false
+}
+
+func parseDefault(typ reflect.Type, s string) (reflect.Value, error) {
+	switch typ.Kind() {
+	case reflect.Ptr:
+		elem, err := parseDefault(typ.Elem(), s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v := reflect.New(typ.Elem())
+		v.Elem().Set(elem)
+		return v, nil
+	case reflect.Slice:
+		v := reflect.MakeSlice(typ, 0, 0)
+		if s == "" {
+			return v, nil
+		}
+		for _, e := range strings.Split(s, defaultDelimiter) {
+			elem, err := parseDefault(typ.Elem(), e)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v = reflect.Append(v, elem)
+		}
+		return v, nil
+	case reflect.Map:
+		v := reflect.MakeMap(typ)
+		if s == "" {
+			return v, nil
+		}
+		for _, e := range strings.Split(s, defaultDelimiter) {
+			key, val, ok := strings.Cut(e, defaultKeyDelimiter)
+			if ok == false {
+				return reflect.Value{}, fmt.Errorf("map entry %s should be key:value", e)
+			}
+			elem, err := parseDefault(typ.Elem(), val)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v.SetMapIndex(reflect.ValueOf(key).Convert(typ.Key()), elem)
+		}
+		return v, nil
+	}
+
+	return parseScalarDefault(typ, s)
+}
+
+func parseScalarDefault(typ reflect.Type, s string) (reflect.Value, error) {
+	v := reflect.New(typ).Elem()
+	//time.Time and netip.Addr
+	if reflect.PointerTo(typ).Implements(textUnmarshalerType) {
+		if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return reflect.Value{}, err
+		}
+		return v, nil
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.SetFloat(f)
+	default:
+		return reflect.Value{}, fmt.Errorf("default value isn't supported by %v", typ)
+	}
+	return v, nil
+}
+
+// copy the default value, pointer, slice and map shouldn't be shared
+// between resources
+func cloneDefault(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(v.Elem())
+		return c
+	case reflect.Slice:
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(c, v)
+		return c
+	case reflect.Map:
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), iter.Value())
+		}
+		return c
+	}
+	return v
+}
+
+// set default value when field isn't specified or set to null
+func (f *leafField) fillDefault(value reflect.Value, raw map[string]interface{}) {
+	if f.defaultValue.IsValid() == false {
+		return
+	}
+
+	if jsonVal, ok := raw[f.jsonName]; ok && jsonVal != nil {
+		return
+	}
+
+	if value.CanSet() {
+		value.Set(cloneDefault(f.defaultValue))
+	}
+}
+
+// fields of nest struct get default value, even the nest struct isn't
+// specified
+func (f *structField) fillDefault(value reflect.Value, raw map[string]interface{}) {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return
+	}
+
+	if f.Field != nil {
+		nr, _ := raw[f.Field.JsonName()].(map[string]interface{})
+		raw = nr
+	}
+
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		ft := typ.Field(i)
+		if ft.PkgPath != "" {
+			continue
+		}
+
+		if ft.Anonymous {
+			f.fillStructDefault(value.Field(i), raw)
+			continue
+		}
+
+		if field, ok := f.fields[ft.Name].(defaultField); ok {
+			field.fillDefault(value.Field(i), raw)
+		}
+	}
+}
+
+// embedded struct share the json object with its parent
+func (f *structField) fillStructDefault(value reflect.Value, raw map[string]interface{}) {
+	embed := &structField{fields: f.fields}
+	embed.fillDefault(value, raw)
+}
@@ -0,0 +1,100 @@
+package resourcefield
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/linkingthing/gorest/resource/schema/resourcefield/validator"
+)
+
+const RuleRequired = "required"
+
+// FieldError is the failure of one field, path is the json path of the
+// field like infos[1].size, rule and limit come from the rest tag which
+// is broken, rule is empty if the value doesn't match the field type
+type FieldError struct {
+	Path  string
+	Rule  string
+	Limit interface{}
+	Err   error
+}
+
+func newMissingFieldError(path string) *FieldError {
+	return &FieldError{Path: path, Rule: RuleRequired}
+}
+
+func newFieldError(path string, err error) *FieldError {
+	fe := &FieldError{Path: path, Err: err}
+	var ve *validator.Error
+	if errors.As(err, &ve) {
+		fe.Rule = ve.Rule
+		fe.Limit = ve.Limit
+	}
+	return fe
+}
+
+func (e *FieldError) Error() string {
+	if e.Rule == RuleRequired {
+		return fmt.Sprintf("field %s is missing", e.Path)
+	}
+	return fmt.Sprintf("field %s: %s", e.Path, e.Err.Error())
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors collect failures of all the fields in one value
+type FieldErrors []*FieldError
+
+func (errs FieldErrors) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// add errors returned by nested field, path of which is relative to the
+// prefix
+func (errs *FieldErrors) add(prefix string, err error) {
+	if err == nil {
+		return
+	}
+
+	var fes FieldErrors
+	var fe *FieldError
+	if errors.As(err, &fes) {
+		for _, e := range fes {
+			errs.add(prefix, e)
+		}
+	} else if errors.As(err, &fe) {
+		nfe := *fe
+		nfe.Path = joinPath(prefix, fe.Path)
+		*errs = append(*errs, &nfe)
+	} else {
+		*errs = append(*errs, newFieldError(prefix, err))
+	}
+}
+
+func (errs FieldErrors) toError() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func joinPath(prefix, path string) string {
+	if prefix == "" {
+		return path
+	} else if path == "" || strings.HasPrefix(path, "[") {
+		return prefix + path
+	} else {
+		return prefix + "." + path
+	}
+}
+
+func indexPath(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
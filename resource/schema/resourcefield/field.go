@@ -25,14 +25,16 @@ var _ Field = &sliceLeafField{}
 var _ Field = &sliceStructField{}
 var _ Field = &mapLeafField{}
 var _ Field = &mapStructField{}
+var _ Field = &ptrLeafField{}
 
 // field with type, int, string, boolean
 type leafField struct {
-	name       string
-	jsonName   string
-	kind       reflect.Kind
-	required   bool
-	validators []validator.Validator
+	name         string
+	jsonName     string
+	kind         reflect.Kind
+	required     bool
+	validators   []validator.Validator
+	defaultValue reflect.Value
 }
 
 func newLeafField(name, jsonName string, kind reflect.Kind) *leafField {
@@ -67,26 +69,56 @@ func (f *leafField) SetValidators(validators []validator.Validator) {
 func (f *leafField) Validate(val interface{}, raw map[string]interface{}) error {
 	if _, ok := raw[f.JsonName()]; !ok {
 		if f.IsRequired() {
-			return fmt.Errorf("field %s is missing", f.jsonName)
+			return newMissingFieldError(f.jsonName)
 		} else {
 			return nil
 		}
 	}
 
 	if reflect.ValueOf(val).Kind() != f.kind {
-		return fmt.Errorf("field %s has invalid invalid kind", f.jsonName)
+		return newFieldError(f.jsonName, fmt.Errorf("invalid kind"))
 	}
 
-	return f.doValidate(val)
+	return f.doValidate(f.jsonName, val).toError()
 }
 
-func (f *leafField) doValidate(val interface{}) error {
+// all the validators are applied, and the failures are collected
+func (f *leafField) doValidate(path string, val interface{}) FieldErrors {
+	var errs FieldErrors
 	for _, validator := range f.validators {
 		if err := validator.Validate(val); err != nil {
-			return err
+			errs = append(errs, newFieldError(path, err))
 		}
 	}
-	return nil
+	return errs
+}
+
+// ptrLeafField is optional field with pointer to leaf type, null in json
+// is same with not specified, validators are applied to the pointed value
+type ptrLeafField struct {
+	*leafField
+}
+
+func newPtrLeafField(inner *leafField) *ptrLeafField {
+	return &ptrLeafField{
+		leafField: inner,
+	}
+}
+
+func (f *ptrLeafField) Validate(val interface{}, raw map[string]interface{}) error {
+	if jsonVal, ok := raw[f.JsonName()]; ok == false || jsonVal == nil {
+		if f.IsRequired() {
+			return newMissingFieldError(f.JsonName())
+		}
+		return nil
+	}
+
+	value := reflect.ValueOf(val)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return newFieldError(f.JsonName(), fmt.Errorf("runtime value isn't synchronize with json data"))
+	}
+
+	return f.doValidate(f.JsonName(), value.Elem().Interface()).toError()
 }
 
 type sliceLeafField struct {
@@ -107,16 +139,16 @@ func (f *sliceLeafField) Validate(val interface{}, raw map[string]interface{}) e
 
 	value := reflect.ValueOf(val)
 	if value.Kind() != reflect.Slice {
-		return fmt.Errorf("runtime value of %s isn't synchronize with json data", f.leafField.JsonName())
+		return newFieldError(f.leafField.JsonName(), fmt.Errorf("runtime value isn't synchronize with json data"))
 	}
+
+	var errs FieldErrors
 	if specified {
 		for i := 0; i < value.Len(); i++ {
-			if err := f.leafField.doValidate(value.Index(i).Interface()); err != nil {
-				return err
-			}
+			errs = append(errs, f.leafField.doValidate(indexPath(f.leafField.JsonName(), i), value.Index(i).Interface())...)
 		}
 	}
-	return nil
+	return errs.toError()
 }
 
 func fieldIsSpecifiedWithKind(f Field, raw map[string]interface{}, kind reflect.Kind) (bool, interface{}, error) {
@@ -128,22 +160,22 @@ func fieldIsSpecifiedWithKind(f Field, raw map[string]interface{}, kind reflect.
 
 	if f.IsRequired() {
 		if !specified {
-			return specified, nil, fmt.Errorf("field %s is missing", f.JsonName())
+			return specified, nil, newMissingFieldError(f.JsonName())
 		}
 	}
 
 	if specified {
 		v := reflect.ValueOf(jsonVal)
 		if !v.IsValid() {
-			return specified, nil, fmt.Errorf("field %s has invalid value", f.JsonName())
+			return specified, nil, newFieldError(f.JsonName(), fmt.Errorf("invalid value"))
 		}
 
 		if v.Kind() != kind {
-			return specified, nil, fmt.Errorf("field %s isn't %v", f.JsonName(), kind)
+			return specified, nil, newFieldError(f.JsonName(), fmt.Errorf("isn't %v", kind))
 		}
 
 		if v.Len() == 0 && f.IsRequired() {
-			return specified, nil, fmt.Errorf("field %s with empty slice ", f.JsonName())
+			return specified, nil, newMissingFieldError(f.JsonName())
 		}
 	}
 	return specified, jsonVal, nil
@@ -174,21 +206,20 @@ func (f *sliceStructField) Validate(val interface{}, raw map[string]interface{})
 	value := reflect.ValueOf(val)
 	jsonValue := reflect.ValueOf(jsonVal)
 	if value.Kind() != reflect.Slice || value.Len() != jsonValue.Len() {
-		return fmt.Errorf("runtime value of %s isn't synchronize with json data", f.Field.JsonName())
+		return newFieldError(f.Field.JsonName(), fmt.Errorf("runtime value isn't synchronize with json data"))
 	}
 
+	var errs FieldErrors
 	for i := 0; i < value.Len(); i++ {
-		elemVal := jsonValue.Index(i).Interface()
-		elemRaw, ok := elemVal.(map[string]interface{})
+		path := indexPath(f.Field.JsonName(), i)
+		elemRaw, ok := jsonValue.Index(i).Interface().(map[string]interface{})
 		if !ok {
-			return fmt.Errorf("elem of field %s is not a struct", f.Field.JsonName())
-		}
-		if err := f.inner.Validate(value.Index(i).Interface(), elemRaw); err != nil {
-			return err
+			errs.add(path, fmt.Errorf("elem is not a struct"))
+			continue
 		}
+		errs.add(path, f.inner.Validate(value.Index(i).Interface(), elemRaw))
 	}
-
-	return nil
+	return errs.toError()
 }
 
 type mapLeafField struct {
@@ -212,15 +243,16 @@ func (f *mapLeafField) Validate(val interface{}, raw map[string]interface{}) err
 
 	value := reflect.ValueOf(val)
 	if value.Kind() != reflect.Map {
-		return fmt.Errorf("runtime value of %s isn't synchronize with json data", f.leafField.JsonName())
+		return newFieldError(f.leafField.JsonName(), fmt.Errorf("runtime value isn't synchronize with json data"))
 	}
+
+	var errs FieldErrors
 	iter := value.MapRange()
 	for iter.Next() {
-		if err := f.leafField.doValidate(iter.Value().Interface()); err != nil {
-			return err
-		}
+		path := joinPath(f.leafField.JsonName(), iter.Key().String())
+		errs = append(errs, f.leafField.doValidate(path, iter.Value().Interface())...)
 	}
-	return nil
+	return errs.toError()
 }
 
 type mapStructField struct {
@@ -245,24 +277,26 @@ func (f *mapStructField) Validate(val interface{}, raw map[string]interface{}) e
 		return nil
 	}
 
-	jsonValue := reflect.ValueOf(jsonVal)
+	jsonMap, _ := jsonVal.(map[string]interface{})
 	value := reflect.ValueOf(val)
-	if value.Kind() != reflect.Map || jsonValue.Len() != value.Len() {
-		return fmt.Errorf("runtime value of %s isn't synchronize with json data", f.Field.JsonName())
+	if value.Kind() != reflect.Map || len(jsonMap) != value.Len() {
+		return newFieldError(f.Field.JsonName(), fmt.Errorf("runtime value isn't synchronize with json data"))
 	}
 
-	ji := jsonValue.MapRange()
-	vi := value.MapRange()
-	for ji.Next() && vi.Next() {
-		elemRaw, ok := (ji.Value().Interface()).(map[string]interface{})
+	//elements are matched by key, iteration order of maps is random
+	var errs FieldErrors
+	iter := value.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+		path := joinPath(f.Field.JsonName(), key)
+		elemRaw, ok := jsonMap[key].(map[string]interface{})
 		if !ok {
-			return fmt.Errorf("value of field %s is not a struct", f.Field.JsonName())
-		}
-		if err := f.inner.Validate(vi.Value().Interface(), elemRaw); err != nil {
-			return err
+			errs.add(path, fmt.Errorf("value is not a struct"))
+			continue
 		}
+		errs.add(path, f.inner.Validate(iter.Value().Interface(), elemRaw))
 	}
-	return nil
+	return errs.toError()
 }
 
 type structField struct {
@@ -287,20 +321,27 @@ func (f *structField) Validate(val interface{}, raw map[string]interface{}) erro
 		}
 
 		if f.Field.IsRequired() && !hasField {
-			return fmt.Errorf("struct field %s is missing", jsonName)
+			return newMissingFieldError(jsonName)
 		}
 		//field isn't speicifed
 		if !hasField {
 			return nil
 		}
 
-		if nr, ok := jsonVal.(map[string]interface{}); ok {
-			raw = nr
-		} else {
-			return fmt.Errorf("value of field %s in json data is not a struct", jsonName)
+		nr, ok := jsonVal.(map[string]interface{})
+		if ok == false {
+			return newFieldError(jsonName, fmt.Errorf("value in json data is not a struct"))
 		}
+
+		var errs FieldErrors
+		errs.add(jsonName, f.validateFields(val, nr))
+		return errs.toError()
 	}
 
+	return f.validateFields(val, raw)
+}
+
+func (f *structField) validateFields(val interface{}, raw map[string]interface{}) error {
 	value := reflect.ValueOf(val)
 	//only handle one level redirect
 	if value.Kind() == reflect.Ptr {
@@ -309,6 +350,8 @@ func (f *structField) Validate(val interface{}, raw map[string]interface{}) erro
 	if value.Kind() != reflect.Struct {
 		return fmt.Errorf("struct field with non-sturct but %v", value.Kind())
 	}
+
+	var errs FieldErrors
 	typ := value.Type()
 	for i := 0; i < typ.NumField(); i++ {
 		ft := typ.Field(i)
@@ -317,18 +360,64 @@ func (f *structField) Validate(val interface{}, raw map[string]interface{}) erro
 			continue
 		}
 
+		//fields of embedded struct are in the same json object
 		if ft.Anonymous {
-			if err := f.Validate(value.Field(i).Interface(), raw); err != nil {
-				return err
+			if err := f.validateFields(value.Field(i).Interface(), raw); err != nil {
+				errs.add("", err)
 			}
 			continue
 		}
 
 		if field, ok := f.fields[ft.Name]; ok {
-			if err := field.Validate(value.Field(i).Interface(), raw); err != nil {
-				return err
+			errs.add("", field.Validate(value.Field(i).Interface(), raw))
+		}
+	}
+	return errs.toError()
+}
+
+// validate top level fields which are specified in raw,
+// set a field to nil in raw means it's removed
+func (f *structField) validateSpecified(val interface{}, raw map[string]interface{}) error {
+	value := reflect.ValueOf(val)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("struct field with non-sturct but %v", value.Kind())
+	}
+
+	var errs FieldErrors
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		ft := typ.Field(i)
+		if ft.PkgPath != "" {
+			continue
+		}
+
+		if ft.Anonymous {
+			errs.add("", f.validateSpecified(value.Field(i).Interface(), raw))
+			continue
+		}
+
+		field, ok := f.fields[ft.Name]
+		if !ok {
+			continue
+		}
+
+		jsonVal, specified := raw[field.JsonName()]
+		if !specified {
+			continue
+		}
+
+		if jsonVal == nil {
+			if field.IsRequired() {
+				errs.add("", newMissingFieldError(field.JsonName()))
 			}
+			continue
 		}
+
+		errs.add("", field.Validate(value.Field(i).Interface(), raw))
 	}
-	return nil
+	return errs.toError()
 }
@@ -2,10 +2,16 @@ package resourcefield
 
 import (
 	"encoding/json"
+	"errors"
 	ut "github.com/linkingthing/cement/unittest"
+	"net/netip"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/linkingthing/gorest/resource"
+	"github.com/linkingthing/gorest/resource/schema/resourcefield/validator"
 )
 
 func TestFieldBuild(t *testing.T) {
@@ -424,3 +430,287 @@ func TestPointerNestedStruct(t *testing.T) {
 	err = sf.Validate(storage, raw)
 	ut.Assert(t, err != nil, "lvm is missing")
 }
+
+func TestCollectFieldErrors(t *testing.T) {
+	type Info struct {
+		Size int `json:"size" rest:"min=1,max=10"`
+	}
+
+	type Disk struct {
+		Name   string            `json:"name" rest:"required=true,minLen=2"`
+		Driver string            `json:"driver" rest:"options=lvm|ceph"`
+		Infos  []Info            `json:"infos"`
+		Parts  map[string]Info   `json:"parts"`
+		Labels map[string]string `json:"labels" rest:"maxLen=3"`
+	}
+
+	sf, err := NewBuilder().Build(reflect.TypeOf(Disk{}))
+	ut.Assert(t, err == nil, "")
+
+	disk := Disk{
+		Driver: "nfs",
+		Infos:  []Info{{Size: 2}, {Size: 10}},
+		Parts:  map[string]Info{"p1": {Size: 0}},
+		Labels: map[string]string{"app": "test"},
+	}
+	rawByte, _ := json.Marshal(disk)
+	raw := make(map[string]interface{})
+	json.Unmarshal(rawByte, &raw)
+	delete(raw, "name")
+
+	err = sf.Validate(disk, raw)
+	var errs FieldErrors
+	ut.Assert(t, errors.As(err, &errs), "should get field errors but %v", err)
+
+	results := make(map[string]*FieldError)
+	for _, fe := range errs {
+		results[fe.Path] = fe
+	}
+	ut.Equal(t, len(results), 5)
+	ut.Equal(t, results["name"].Rule, RuleRequired)
+	ut.Equal(t, results["driver"].Rule, validator.RuleOptions)
+	ut.Equal(t, results["driver"].Limit, []string{"lvm", "ceph"})
+	ut.Equal(t, results["infos[1].size"].Rule, validator.RuleMax)
+	ut.Equal(t, results["infos[1].size"].Limit, int64(10))
+	ut.Equal(t, results["parts.p1.size"].Rule, validator.RuleMin)
+	ut.Equal(t, results["labels.app"].Rule, validator.RuleMaxLen)
+	ut.Equal(t, results["name"].Error(), "field name is missing")
+}
+
+func TestValidateFloatTimeIPAndPointer(t *testing.T) {
+	type Route struct {
+		Gateway netip.Addr `json:"gateway" rest:"required=true,isIPv4"`
+		Weight  *float64   `json:"weight,omitempty" rest:"min=0.1,max=1"`
+	}
+
+	type Lease struct {
+		Ratio    float32              `json:"ratio" rest:"min=0,max=0.5"`
+		Expire   time.Time            `json:"expire" rest:"required=true,after=now"`
+		Address  netip.Addr           `json:"address" rest:"isIPv6"`
+		Comment  *string              `json:"comment,omitempty" rest:"minLen=2"`
+		Priority *int                 `json:"priority" rest:"required=true,min=1,max=10"`
+		Routes   []Route              `json:"routes,omitempty"`
+		Backups  map[string]Route     `json:"backups,omitempty"`
+		Servers  []netip.Addr         `json:"servers,omitempty" rest:"isIPv4"`
+		Scores   map[string]float64   `json:"scores,omitempty" rest:"min=0,max=100"`
+		Windows  []time.Time          `json:"windows,omitempty" rest:"before=2100-01-01T00:00:00Z"`
+		Ignored  map[string]time.Time `json:"ignored,omitempty"`
+	}
+
+	sf, err := NewBuilder().Build(reflect.TypeOf(Lease{}))
+	ut.Assert(t, err == nil, "build failed %v", err)
+
+	weight := 0.5
+	priority := 5
+	valid := Lease{
+		Ratio:    0.25,
+		Expire:   time.Now().Add(time.Hour),
+		Address:  netip.MustParseAddr("2001:db8::1"),
+		Priority: &priority,
+		Routes:   []Route{{Gateway: netip.MustParseAddr("10.0.0.1"), Weight: &weight}},
+		Backups:  map[string]Route{"b": {Gateway: netip.MustParseAddr("10.0.0.2")}},
+		Servers:  []netip.Addr{netip.MustParseAddr("10.0.0.3")},
+		Scores:   map[string]float64{"a": 99.5},
+		Windows:  []time.Time{time.Now()},
+	}
+	rawByte, _ := json.Marshal(valid)
+	raw := make(map[string]interface{})
+	json.Unmarshal(rawByte, &raw)
+	ut.Assert(t, sf.Validate(valid, raw) == nil, "get err %v", sf.Validate(valid, raw))
+
+	//nil pointer isn't validated unless it is required
+	delete(raw, "priority")
+	valid.Priority = nil
+	err = sf.Validate(valid, raw)
+	var fes FieldErrors
+	ut.Assert(t, errors.As(err, &fes), "")
+	ut.Equal(t, len(fes), 1)
+	ut.Equal(t, fes[0].Path, "priority")
+	ut.Equal(t, fes[0].Rule, RuleRequired)
+
+	invalidWeight := 1.5
+	shortComment := "a"
+	invalidPriority := 20
+	invalid := valid
+	invalid.Ratio = 0.5
+	invalid.Expire = time.Now().Add(-time.Hour)
+	invalid.Address = netip.MustParseAddr("10.0.0.1")
+	invalid.Comment = &shortComment
+	invalid.Priority = &invalidPriority
+	invalid.Routes = []Route{{Gateway: netip.MustParseAddr("2001:db8::2"), Weight: &invalidWeight}}
+	invalid.Backups = map[string]Route{"b": {Gateway: netip.MustParseAddr("2001:db8::3")}}
+	invalid.Servers = []netip.Addr{netip.MustParseAddr("2001:db8::4")}
+	invalid.Scores = map[string]float64{"a": 100}
+	invalid.Windows = []time.Time{time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)}
+	rawByte, _ = json.Marshal(invalid)
+	raw = make(map[string]interface{})
+	json.Unmarshal(rawByte, &raw)
+	err = sf.Validate(invalid, raw)
+	ut.Assert(t, errors.As(err, &fes), "")
+
+	rules := make(map[string]string)
+	for _, fe := range fes {
+		rules[fe.Path] = fe.Rule
+	}
+	ut.Equal(t, rules, map[string]string{
+		"ratio":             validator.RuleMax,
+		"expire":            validator.RuleAfter,
+		"address":           validator.RuleIsIPv6,
+		"comment":           validator.RuleMinLen,
+		"priority":          validator.RuleMax,
+		"routes[0].gateway": validator.RuleIsIPv4,
+		"routes[0].weight":  validator.RuleMax,
+		"backups.b.gateway": validator.RuleIsIPv4,
+		"servers[0]":        validator.RuleIsIPv4,
+		"scores.a":          validator.RuleMax,
+		"windows[0]":        validator.RuleBefore,
+	})
+}
+
+func TestValidateFloatWithCustomValidator(t *testing.T) {
+	validator.MustRegisterValidator("halfStep", func(v interface{}) error {
+		if f := reflect.ValueOf(v).Float(); f*2 != float64(int64(f*2)) {
+			return errors.New("value should be multiple of 0.5")
+		}
+		return nil
+	})
+
+	type Pool struct {
+		Ratio  float64   `json:"ratio" rest:"validate=halfStep"`
+		Limits []float64 `json:"limits,omitempty" rest:"validate=halfStep"`
+	}
+
+	sf, err := NewBuilder().Build(reflect.TypeOf(Pool{}))
+	ut.Assert(t, err == nil, "build failed %v", err)
+
+	valid := Pool{Ratio: 1.5, Limits: []float64{0.5, 2}}
+	raw := map[string]interface{}{"ratio": 1.5, "limits": []interface{}{0.5, 2.0}}
+	ut.Assert(t, sf.Validate(valid, raw) == nil, "get err %v", sf.Validate(valid, raw))
+
+	invalid := Pool{Ratio: 1.2, Limits: []float64{0.5, 0.3}}
+	raw = map[string]interface{}{"ratio": 1.2, "limits": []interface{}{0.5, 0.3}}
+	var fes FieldErrors
+	ut.Assert(t, errors.As(sf.Validate(invalid, raw), &fes), "")
+	ut.Equal(t, len(fes), 2)
+	ut.Equal(t, fes[0].Path, "ratio")
+	ut.Equal(t, fes[0].Rule, validator.RuleValidate)
+	ut.Equal(t, fes[1].Path, "limits[1]")
+}
+
+func TestFillDefault(t *testing.T) {
+	type Option struct {
+		Code  uint8  `json:"code" rest:"default=6"`
+		Value string `json:"value" rest:"default=10.0.0.1,isIPv4"`
+	}
+
+	type Base struct {
+		Domain string `json:"domain" rest:"default=example.com,isDomain"`
+	}
+
+	type Pool struct {
+		Base     `json:",inline"`
+		Name     string            `json:"name" rest:"required=true"`
+		Lease    int               `json:"lease" rest:"default=3600,min=60"`
+		Ratio    float64           `json:"ratio" rest:"default=0.5,max=1"`
+		Enabled  bool              `json:"enabled" rest:"default=true"`
+		Gateway  netip.Addr        `json:"gateway" rest:"default=10.0.0.254"`
+		Comment  *string           `json:"comment" rest:"default=none,minLen=2"`
+		Servers  []string          `json:"servers" rest:"default=10.0.0.2|10.0.0.3,isIPv4"`
+		Labels   map[string]string `json:"labels" rest:"default=env:prod|tier:web"`
+		Option   Option            `json:"option"`
+		Optional *Option           `json:"optional"`
+	}
+
+	sf, err := NewBuilder().Build(reflect.TypeOf(Pool{}))
+	ut.Assert(t, err == nil, "build failed %v", err)
+	rf := newResourceField(sf)
+
+	var p Pool
+	raw := map[string]interface{}{"name": "p1", "lease": float64(120), "labels": nil, "option": map[string]interface{}{"code": float64(3)}}
+	p.Name = "p1"
+	p.Lease = 120
+	p.Option.Code = 3
+	rf.FillDefault(&p, raw)
+	ut.Equal(t, p.Domain, "example.com")
+	ut.Equal(t, p.Lease, 120)
+	ut.Equal(t, p.Ratio, 0.5)
+	ut.Equal(t, p.Enabled, true)
+	ut.Equal(t, p.Gateway, netip.MustParseAddr("10.0.0.254"))
+	ut.Equal(t, *p.Comment, "none")
+	ut.Equal(t, p.Servers, []string{"10.0.0.2", "10.0.0.3"})
+	ut.Equal(t, p.Labels, map[string]string{"env": "prod", "tier": "web"})
+	ut.Equal(t, p.Option, Option{Code: 3, Value: "10.0.0.1"})
+	ut.Assert(t, p.Optional == nil, "nil struct pointer shouldn't be created")
+
+	//default value isn't shared
+	var p2 Pool
+	rf.FillDefault(&p2, map[string]interface{}{})
+	p2.Servers[0] = "10.0.0.4"
+	p2.Labels["env"] = "dev"
+	*p2.Comment = "changed"
+	ut.Equal(t, p.Servers[0], "10.0.0.2")
+	ut.Equal(t, p.Labels["env"], "prod")
+	ut.Equal(t, *p.Comment, "none")
+
+	for _, typ := range []reflect.Type{
+		reflect.TypeOf(struct {
+			Lease int `rest:"default=30,min=60"`
+		}{}),
+		reflect.TypeOf(struct {
+			Lease int `rest:"default=abc"`
+		}{}),
+		reflect.TypeOf(struct {
+			Servers []string `rest:"default=10.0.0.1|abc,isIPv4"`
+		}{}),
+		reflect.TypeOf(struct {
+			Labels map[string]string `rest:"default=env"`
+		}{}),
+		reflect.TypeOf(struct {
+			Name string `rest:"required=true,default=abc"`
+		}{}),
+		reflect.TypeOf(struct {
+			Option Option `rest:"default=abc"`
+		}{}),
+		reflect.TypeOf(struct {
+			Options []Option `rest:"default=abc"`
+		}{}),
+	} {
+		_, err := NewBuilder().Build(typ)
+		ut.Assert(t, err != nil, "%v should fail", typ)
+	}
+}
+
+func TestAccessOf(t *testing.T) {
+	type Secret struct {
+		Token string `json:"token" rest:"writeOnly=true"`
+	}
+
+	type User struct {
+		resource.ResourceBase `json:",inline"`
+		*Secret
+		Name   string `json:"name" rest:"required=true,immutable"`
+		Serial string `json:"serial" rest:"readOnly"`
+		Role   string `json:"role" rest:"readOnly=false"`
+	}
+
+	a, err := AccessOf(reflect.TypeOf(&User{}))
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, a.ReadOnlyFields(), []string{"creationTimestamp", "deletionTimestamp", "serial"})
+	ut.Equal(t, a.ImmutableFields(), []string{"name"})
+	ut.Equal(t, a.WriteOnlyFields(), []string{"token"})
+
+	u := &User{Name: "u1"}
+	ut.Equal(t, a.FieldValue(reflect.ValueOf(u), "name").Interface(), "u1")
+	ut.Assert(t, a.FieldValue(reflect.ValueOf(u), "token").IsValid() == false, "nil embedded struct")
+	u.Secret = &Secret{Token: "t"}
+	ut.Equal(t, a.FieldValue(reflect.ValueOf(u), "token").Interface(), "t")
+
+	_, err = AccessOf(reflect.TypeOf(struct {
+		Name string `rest:"readOnly=yes"`
+	}{}))
+	ut.Assert(t, err != nil, "")
+	_, err = AccessOf(reflect.TypeOf(struct {
+		Name string `rest:"readOnly,writeOnly"`
+	}{}))
+	ut.Assert(t, err != nil, "")
+}
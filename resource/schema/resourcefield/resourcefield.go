@@ -6,6 +6,11 @@ import (
 
 type ResourceField interface {
 	Validate(interface{}, map[string]interface{}) error
+	//only validate the fields specified in raw, it's used by patch
+	ValidateSpecified(interface{}, map[string]interface{}) error
+	//set default value of the fields which aren't specified in raw,
+	//value should be pointer to struct
+	FillDefault(interface{}, map[string]interface{})
 }
 
 func New(typ reflect.Type) (ResourceField, error) {
@@ -33,3 +38,16 @@ func newResourceField(field Field) *resourceField {
 func (f *resourceField) Validate(value interface{}, raw map[string]interface{}) error {
 	return f.field.Validate(value, raw)
 }
+
+func (f *resourceField) ValidateSpecified(value interface{}, raw map[string]interface{}) error {
+	if sf, ok := f.field.(*structField); ok {
+		return sf.validateSpecified(value, raw)
+	}
+	return f.field.Validate(value, raw)
+}
+
+func (f *resourceField) FillDefault(value interface{}, raw map[string]interface{}) {
+	if df, ok := f.field.(defaultField); ok {
+		df.fillDefault(reflect.ValueOf(value), raw)
+	}
+}
@@ -6,12 +6,16 @@ import (
 	"github.com/linkingthing/gorest/util"
 )
 
-var builders []ValidatorBuilder = []ValidatorBuilder{
+var builders []ValidatorBuilder = append([]ValidatorBuilder{
 	&domainNameValidatorBuilder{},
 	&stringLenRangeValidatorBuilder{},
 	&intRangeValidatorBuilder{},
+	&floatRangeValidatorBuilder{},
+	&timeRangeValidatorBuilder{},
 	&optionValidatorBuilder{},
-}
+	&patternValidatorBuilder{},
+	&customValidatorBuilder{},
+}, formatValidatorBuilders...)
 
 func Build(fieldType reflect.Type, tags []string) ([]Validator, error) {
 	var vs []Validator
@@ -0,0 +1,125 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/linkingthing/gorest/util"
+)
+
+const (
+	validatePrefix    = "validate="
+	validateDelimiter = "|"
+)
+
+// ValidateFunc check one value of the field, for slice and map, it is
+// called with each element, return *Error to specify rule and limit
+type ValidateFunc func(interface{}) error
+
+var (
+	customValidatorsLock sync.RWMutex
+	customValidators     = make(map[string]ValidateFunc)
+)
+
+// RegisterValidator add a named validator which is referenced by rest tag
+// like validate=name, validators should be registered before the resource
+// kinds are imported
+func RegisterValidator(name string, fn ValidateFunc) error {
+	if name == "" || strings.ContainsAny(name, validateDelimiter+",=") {
+		return fmt.Errorf("invalid validator name %s", name)
+	}
+	if fn == nil {
+		return fmt.Errorf("validator %s has no validate function", name)
+	}
+
+	customValidatorsLock.Lock()
+	defer customValidatorsLock.Unlock()
+	if _, ok := customValidators[name]; ok {
+		return fmt.Errorf("duplicate validator %s", name)
+	}
+	customValidators[name] = fn
+	return nil
+}
+
+func MustRegisterValidator(name string, fn ValidateFunc) {
+	if err := RegisterValidator(name, fn); err != nil {
+		panic("register validator failed:" + err.Error())
+	}
+}
+
+func getCustomValidator(name string) (ValidateFunc, bool) {
+	customValidatorsLock.RLock()
+	defer customValidatorsLock.RUnlock()
+	fn, ok := customValidators[name]
+	return fn, ok
+}
+
+type customValidator struct {
+	names []string
+	fns   []ValidateFunc
+}
+
+type customValidatorBuilder struct{}
+
+var _ ValidatorBuilder = &customValidatorBuilder{}
+
+// rule of the error is validate and limit is the validator name, unless
+// the validator returns *Error
+func (v *customValidator) Validate(val interface{}) error {
+	kind := util.Inspect(reflect.TypeOf(val))
+	if kind != util.String && kind != util.Int && kind != util.Uint && kind != util.Float && kind != util.Bool {
+		return fmt.Errorf("validate apply to non-basic type: %v", kind)
+	}
+
+	for i, fn := range v.fns {
+		if err := fn(val); err != nil {
+			var ve *Error
+			if errors.As(err, &ve) {
+				return ve
+			}
+			return &Error{
+				Rule:    RuleValidate,
+				Limit:   v.names[i],
+				Message: err.Error(),
+			}
+		}
+	}
+	return nil
+}
+
+func (b *customValidatorBuilder) FromTags(tags []string) (Validator, error) {
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, validatePrefix) {
+			v := &customValidator{}
+			for _, name := range strings.Split(strings.TrimPrefix(tag, validatePrefix), validateDelimiter) {
+				fn, ok := getCustomValidator(name)
+				if ok == false {
+					return nil, fmt.Errorf("unknown validator %s", name)
+				}
+				v.names = append(v.names, name)
+				v.fns = append(v.fns, fn)
+			}
+			return v, nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *customValidatorBuilder) SupportKind(kind util.Kind) bool {
+	return kind == util.String ||
+		kind == util.Int ||
+		kind == util.Uint ||
+		kind == util.Float ||
+		kind == util.Bool ||
+		kind == util.StringSlice ||
+		kind == util.IntSlice ||
+		kind == util.UintSlice ||
+		kind == util.FloatSlice ||
+		kind == util.StringStringMap ||
+		kind == util.StringIntMap ||
+		kind == util.StringUintMap ||
+		kind == util.StringFloatMap
+}
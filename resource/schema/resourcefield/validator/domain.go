@@ -36,11 +36,17 @@ func (v *domainNameValidator) Validate(val interface{}) error {
 
 func validateDomain(s string) error {
 	if len(s) > DNS1123SubdomainMaxLength {
-		return fmt.Errorf("exceed max domain name len limitation(253)")
+		return &Error{
+			Rule:    RuleIsDomain,
+			Message: fmt.Sprintf("exceed max domain name len limitation(%d)", DNS1123SubdomainMaxLength),
+		}
 	}
 
 	if !dns1123SubdomainRegexp.MatchString(s) {
-		return fmt.Errorf("subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character")
+		return &Error{
+			Rule:    RuleIsDomain,
+			Message: "subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character",
+		}
 	}
 
 	return nil
@@ -0,0 +1,34 @@
+package validator
+
+// rules of the validators, same with the name of rest tags
+const (
+	RuleOptions  = "options"
+	RuleMin      = "min"
+	RuleMax      = "max"
+	RuleMinLen   = "minLen"
+	RuleMaxLen   = "maxLen"
+	RuleIsDomain = "isDomain"
+	RulePattern  = "pattern"
+	RuleIsIPv4   = "isIPv4"
+	RuleIsIPv6   = "isIPv6"
+	RuleIsCIDR   = "isCIDR"
+	RuleIsMAC    = "isMAC"
+	RuleIsEmail  = "isEmail"
+	RuleIsURL    = "isURL"
+	RuleIsUUID   = "isUUID"
+	RuleValidate = "validate"
+	RuleAfter    = "after"
+	RuleBefore   = "before"
+)
+
+// Error is returned when the value breaks the rule of a validator,
+// limit is the bound of the rule, like max value or options
+type Error struct {
+	Rule    string
+	Limit   interface{}
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
@@ -0,0 +1,89 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/linkingthing/gorest/util"
+)
+
+type floatRangeValidator struct {
+	min *float64
+	max *float64
+}
+
+type floatRangeValidatorBuilder struct{}
+
+func newFloatRangeValidator(min, max *float64) Validator {
+	return &floatRangeValidator{
+		min: min,
+		max: max,
+	}
+}
+
+func (v *floatRangeValidator) Validate(val interface{}) error {
+	value := reflect.ValueOf(val)
+	kind := util.Inspect(value.Type())
+	if kind != util.Float {
+		return fmt.Errorf("float range apply to non-float type:%v", kind)
+	}
+
+	f := value.Float()
+	if v.min != nil && f < *v.min {
+		return &Error{
+			Rule:    RuleMin,
+			Limit:   *v.min,
+			Message: fmt.Sprintf("exceed the range limit, (%v should >= %v)", f, *v.min),
+		}
+	}
+
+	if v.max != nil && f >= *v.max {
+		return &Error{
+			Rule:    RuleMax,
+			Limit:   *v.max,
+			Message: fmt.Sprintf("exceed the range limit, (%v should < %v)", f, *v.max),
+		}
+	}
+	return nil
+}
+
+func (b *floatRangeValidatorBuilder) FromTags(tags []string) (Validator, error) {
+	var min, max *float64
+	for _, tag := range tags {
+		var bound **float64
+		var value string
+		if strings.HasPrefix(tag, minPrefix) {
+			bound, value = &min, strings.TrimPrefix(tag, minPrefix)
+		} else if strings.HasPrefix(tag, maxPrefix) {
+			bound, value = &max, strings.TrimPrefix(tag, maxPrefix)
+		} else {
+			continue
+		}
+
+		if *bound != nil {
+			return nil, fmt.Errorf("float range has duplicate tag %s", tag)
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s isn't valid float:%s", tag, err.Error())
+		}
+		*bound = &f
+	}
+
+	if min == nil && max == nil {
+		return nil, nil
+	}
+
+	if min != nil && max != nil && *min >= *max {
+		return nil, fmt.Errorf("min value should smaller than max")
+	}
+	return newFloatRangeValidator(min, max), nil
+}
+
+func (b *floatRangeValidatorBuilder) SupportKind(kind util.Kind) bool {
+	return kind == util.Float ||
+		kind == util.FloatSlice ||
+		kind == util.StringFloatMap
+}
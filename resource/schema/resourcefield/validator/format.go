@@ -0,0 +1,158 @@
+package validator
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/linkingthing/gorest/util"
+)
+
+// formatValidator check the string is in the format of the rule, tag of
+// the rule is like isIPv4 or isIPv4=true, checkIP is used for netip.Addr
+type formatValidator struct {
+	rule    string
+	check   func(string) error
+	checkIP func(netip.Addr) error
+}
+
+type formatValidatorBuilder struct {
+	validator *formatValidator
+}
+
+var formatValidatorBuilders = []ValidatorBuilder{
+	&formatValidatorBuilder{validator: &formatValidator{rule: RuleIsIPv4, check: checkIPv4, checkIP: checkAddrIPv4}},
+	&formatValidatorBuilder{validator: &formatValidator{rule: RuleIsIPv6, check: checkIPv6, checkIP: checkAddrIPv6}},
+	newFormatValidatorBuilder(RuleIsCIDR, checkCIDR),
+	newFormatValidatorBuilder(RuleIsMAC, checkMAC),
+	newFormatValidatorBuilder(RuleIsEmail, checkEmail),
+	newFormatValidatorBuilder(RuleIsURL, checkURL),
+	newFormatValidatorBuilder(RuleIsUUID, checkUUID),
+}
+
+func newFormatValidatorBuilder(rule string, check func(string) error) ValidatorBuilder {
+	return &formatValidatorBuilder{validator: &formatValidator{rule: rule, check: check}}
+}
+
+func (v *formatValidator) Validate(val interface{}) error {
+	value := reflect.ValueOf(val)
+	var err error
+	switch kind := util.Inspect(value.Type()); {
+	case kind == util.String:
+		err = v.check(value.String())
+	case kind == util.IP && v.checkIP != nil:
+		err = v.checkIP(value.Interface().(netip.Addr))
+	default:
+		return fmt.Errorf("%s apply to unsupported type: %v", v.rule, kind)
+	}
+
+	if err != nil {
+		return &Error{
+			Rule:    v.rule,
+			Message: fmt.Sprintf("%v isn't valid: %s", val, err.Error()),
+		}
+	}
+	return nil
+}
+
+func (b *formatValidatorBuilder) FromTags(tags []string) (Validator, error) {
+	rule := b.validator.rule
+	for _, tag := range tags {
+		if tag == rule {
+			return b.validator, nil
+		} else if strings.HasPrefix(tag, rule+"=") {
+			enabled, err := strconv.ParseBool(strings.TrimPrefix(tag, rule+"="))
+			if err != nil {
+				return nil, fmt.Errorf("%s value isn't valid bool:%s", rule, err.Error())
+			}
+			if enabled {
+				return b.validator, nil
+			}
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *formatValidatorBuilder) SupportKind(kind util.Kind) bool {
+	if b.validator.checkIP != nil && (kind == util.IP || kind == util.IPSlice || kind == util.StringIPMap) {
+		return true
+	}
+	return kind == util.String ||
+		kind == util.StringSlice ||
+		kind == util.StringStringMap
+}
+
+func checkIPv4(s string) error {
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return err
+	}
+	return checkAddrIPv4(addr)
+}
+
+func checkIPv6(s string) error {
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return err
+	}
+	return checkAddrIPv6(addr)
+}
+
+func checkAddrIPv4(addr netip.Addr) error {
+	if addr.Is4() == false {
+		return fmt.Errorf("not an ipv4 address")
+	}
+	return nil
+}
+
+func checkAddrIPv6(addr netip.Addr) error {
+	if addr.Is6() == false {
+		return fmt.Errorf("not an ipv6 address")
+	}
+	return nil
+}
+
+func checkCIDR(s string) error {
+	_, err := netip.ParsePrefix(s)
+	return err
+}
+
+func checkMAC(s string) error {
+	_, err := net.ParseMAC(s)
+	return err
+}
+
+// only the bare address is valid, name like "Bob <bob@example.com>" isn't
+func checkEmail(s string) error {
+	if addr, err := mail.ParseAddress(s); err != nil {
+		return err
+	} else if addr.Address != s {
+		return fmt.Errorf("not a bare email address")
+	}
+	return nil
+}
+
+func checkURL(s string) error {
+	if u, err := url.Parse(s); err != nil {
+		return err
+	} else if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("scheme or host is missing")
+	}
+	return nil
+}
+
+var uuidRegexp = regexp.MustCompile("^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$")
+
+func checkUUID(s string) error {
+	if uuidRegexp.MatchString(s) == false {
+		return fmt.Errorf("not in the form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx")
+	}
+	return nil
+}
@@ -40,11 +40,19 @@ func (v *intRangeValidator) Validate(val interface{}) error {
 
 func (v *intRangeValidator) validateValueRange(i int64) error {
 	if v.min != nil && i < *v.min {
-		return fmt.Errorf("exceed the range limit, (%v should >= %v)", i, *v.min)
+		return &Error{
+			Rule:    RuleMin,
+			Limit:   *v.min,
+			Message: fmt.Sprintf("exceed the range limit, (%v should >= %v)", i, *v.min),
+		}
 	}
 
 	if v.max != nil && i >= *v.max {
-		return fmt.Errorf("exceed the range limit, (%v should < %v)", i, *v.max)
+		return &Error{
+			Rule:    RuleMax,
+			Limit:   *v.max,
+			Message: fmt.Sprintf("exceed the range limit, (%v should < %v)", i, *v.max),
+		}
 	}
 	return nil
 }
@@ -0,0 +1,61 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/linkingthing/gorest/util"
+)
+
+const patternPrefix = "pattern="
+
+type patternValidator struct {
+	pattern *regexp.Regexp
+}
+
+type patternValidatorBuilder struct{}
+
+var _ ValidatorBuilder = &patternValidatorBuilder{}
+
+func (v *patternValidator) Validate(val interface{}) error {
+	value := reflect.ValueOf(val)
+	kind := util.Inspect(value.Type())
+	if kind != util.String {
+		return fmt.Errorf("pattern apply to non-string type: %v", kind)
+	}
+
+	if v.pattern.MatchString(value.String()) == false {
+		return &Error{
+			Rule:    RulePattern,
+			Limit:   v.pattern.String(),
+			Message: fmt.Sprintf("%s doesn't match pattern %s", value.String(), v.pattern.String()),
+		}
+	}
+	return nil
+}
+
+func (b *patternValidatorBuilder) FromTags(tags []string) (Validator, error) {
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, patternPrefix) {
+			pattern := strings.TrimPrefix(tag, patternPrefix)
+			if pattern == "" {
+				return nil, fmt.Errorf("pattern is empty")
+			}
+
+			reg, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("pattern isn't valid regular expression:%s", err.Error())
+			}
+			return &patternValidator{pattern: reg}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *patternValidatorBuilder) SupportKind(kind util.Kind) bool {
+	return kind == util.String ||
+		kind == util.StringSlice ||
+		kind == util.StringStringMap
+}
@@ -38,10 +38,18 @@ func (v *stringLenRangeValidator) Validate(val interface{}) error {
 func (v *stringLenRangeValidator) validateStringLen(s string) error {
 	l := int64(len(s))
 	if v.minLen != nil && l < *v.minLen {
-		return fmt.Errorf("exceed the range limit, (string len %v should >= %v)", l, *v.minLen)
+		return &Error{
+			Rule:    RuleMinLen,
+			Limit:   *v.minLen,
+			Message: fmt.Sprintf("exceed the range limit, (string len %v should >= %v)", l, *v.minLen),
+		}
 	}
 	if v.maxLen != nil && l >= *v.maxLen {
-		return fmt.Errorf("exceed the range limit, (string len %v should < %v)", l, *v.maxLen)
+		return &Error{
+			Rule:    RuleMaxLen,
+			Limit:   *v.maxLen,
+			Message: fmt.Sprintf("exceed the range limit, (string len %v should < %v)", l, *v.maxLen),
+		}
 	}
 	return nil
 }
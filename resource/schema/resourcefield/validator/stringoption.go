@@ -32,7 +32,11 @@ func (v *optionValidator) Validate(val interface{}) error {
 	}
 	sv := value.String()
 	if slice.SliceIndex(v.options, sv) == -1 {
-		return fmt.Errorf("%s isn't included in options %v", sv, v.options)
+		return &Error{
+			Rule:    RuleOptions,
+			Limit:   v.options,
+			Message: fmt.Sprintf("%s isn't included in options %v", sv, v.options),
+		}
 	}
 	return nil
 }
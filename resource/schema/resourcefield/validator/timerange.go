@@ -0,0 +1,103 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/linkingthing/gorest/util"
+)
+
+const (
+	afterPrefix  = "after="
+	beforePrefix = "before="
+	timeNow      = "now"
+)
+
+// timeRangeValidator check the time is after or before a bound, bound is
+// now or a RFC3339 time, now is evaluated when the value is validated
+type timeRangeValidator struct {
+	after  string
+	before string
+}
+
+type timeRangeValidatorBuilder struct{}
+
+func (v *timeRangeValidator) Validate(val interface{}) error {
+	value := reflect.ValueOf(val)
+	kind := util.Inspect(value.Type())
+	if kind != util.Time {
+		return fmt.Errorf("time range apply to non-time type:%v", kind)
+	}
+
+	t := value.Interface().(time.Time)
+	if v.after != "" {
+		if after := boundTime(v.after); t.After(after) == false {
+			return &Error{
+				Rule:    RuleAfter,
+				Limit:   v.after,
+				Message: fmt.Sprintf("exceed the range limit, (%s should after %s)", t.Format(time.RFC3339), v.after),
+			}
+		}
+	}
+
+	if v.before != "" {
+		if before := boundTime(v.before); t.Before(before) == false {
+			return &Error{
+				Rule:    RuleBefore,
+				Limit:   v.before,
+				Message: fmt.Sprintf("exceed the range limit, (%s should before %s)", t.Format(time.RFC3339), v.before),
+			}
+		}
+	}
+	return nil
+}
+
+func boundTime(bound string) time.Time {
+	if bound == timeNow {
+		return time.Now()
+	}
+	t, _ := time.Parse(time.RFC3339, bound)
+	return t
+}
+
+func (b *timeRangeValidatorBuilder) FromTags(tags []string) (Validator, error) {
+	v := &timeRangeValidator{}
+	for _, tag := range tags {
+		var bound *string
+		var value string
+		if strings.HasPrefix(tag, afterPrefix) {
+			bound, value = &v.after, strings.TrimPrefix(tag, afterPrefix)
+		} else if strings.HasPrefix(tag, beforePrefix) {
+			bound, value = &v.before, strings.TrimPrefix(tag, beforePrefix)
+		} else {
+			continue
+		}
+
+		if *bound != "" {
+			return nil, fmt.Errorf("time range has duplicate tag %s", tag)
+		}
+		if value != timeNow {
+			if _, err := time.Parse(time.RFC3339, value); err != nil {
+				return nil, fmt.Errorf("%s should be now or RFC3339 time:%s", tag, err.Error())
+			}
+		}
+		*bound = value
+	}
+
+	if v.after == "" && v.before == "" {
+		return nil, nil
+	}
+
+	if v.after != timeNow && v.before != timeNow && v.after != "" && v.before != "" &&
+		boundTime(v.after).Before(boundTime(v.before)) == false {
+		return nil, fmt.Errorf("after value should earlier than before")
+	}
+	return v, nil
+}
+
+func (b *timeRangeValidatorBuilder) SupportKind(kind util.Kind) bool {
+	return kind == util.Time ||
+		kind == util.TimeSlice
+}
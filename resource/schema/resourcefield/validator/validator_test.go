@@ -1,28 +1,33 @@
 package validator
 
 import (
+	"fmt"
+	"net/netip"
 	"reflect"
-	"strings"
 	"testing"
+	"time"
 
 	ut "github.com/linkingthing/cement/unittest"
+	"github.com/linkingthing/gorest/util"
 )
 
 func TestBuildValidator(t *testing.T) {
 	type MyOption string
 	type testStruct struct {
-		DomainName            string     `json:"domainName" rest:"isDomain=true"`
-		StringWithOption      MyOption   `json:"stringWithOption,omitempty" rest:"required=true,options=lvm|ceph"`
-		StringWithLenLimit    string     `json:"stringWithLenLimit" rest:"minLen=2,maxLen=10"`
-		IntWithRange          uint32     `json:"intWithRange" rest:"min=1,max=1000"`
-		StringSliceWithDomain []string   `json:"stringSliceWithDomain,omitempty" rest:"required=true,isDomain=true"`
-		StringSliceWithOption []MyOption `json:"stringSliceWithOption,omitempty" rest:"required=true,options=lvm|ceph"`
+		DomainName            string            `json:"domainName" rest:"isDomain=true"`
+		StringWithOption      MyOption          `json:"stringWithOption,omitempty" rest:"required=true,options=lvm|ceph"`
+		StringWithLenLimit    string            `json:"stringWithLenLimit" rest:"minLen=2,maxLen=10"`
+		IntWithRange          uint32            `json:"intWithRange" rest:"min=1,max=1000"`
+		StringSliceWithDomain []string          `json:"stringSliceWithDomain,omitempty" rest:"required=true,isDomain=true"`
+		StringSliceWithOption []MyOption        `json:"stringSliceWithOption,omitempty" rest:"required=true,options=lvm|ceph"`
+		StringWithPattern     string            `json:"stringWithPattern" rest:"pattern=^[a-z]{1,3}$"`
+		StringMapWithIPv4     map[string]string `json:"stringMapWithIPv4" rest:"isIPv4"`
 	}
 
 	st := reflect.TypeOf(testStruct{})
 	for i := 0; i < st.NumField(); i++ {
 		f := st.Field(i)
-		tags := strings.Split(f.Tag.Get("rest"), ",")
+		tags := util.SplitRestTag(f.Tag.Get("rest"))
 		ut.Assert(t, len(tags) > 0, "")
 		validator, err := Build(f.Type, tags)
 		ut.Assert(t, err == nil && validator != nil, "")
@@ -37,12 +42,17 @@ func TestBuildValidator(t *testing.T) {
 
 		StringWithInvalidLenLimit string `rest:"minLen=12,maxLen=12"`
 		StringWithEmptyInterval   string `rest:"minLen=,maxLen="`
+		StringWithInvalidPattern  string `rest:"pattern=[a-z"`
+		StringWithEmptyPattern    string `rest:"pattern="`
+		IntWithIPv4               int    `rest:"isIPv4"`
+		StringWithInvalidIPv4     string `rest:"isIPv4=yes"`
+		StringWithIPv4Disabled    string `rest:"isIPv4=false"`
 	}
 
 	st = reflect.TypeOf(testStruct2{})
 	for i := 0; i < st.NumField(); i++ {
 		f := st.Field(i)
-		tags := strings.Split(f.Tag.Get("rest"), ",")
+		tags := util.SplitRestTag(f.Tag.Get("rest"))
 		validators, _ := Build(f.Type, tags)
 		ut.Assert(t, len(validators) == 0, "tag should has error %v", tags)
 	}
@@ -132,6 +142,174 @@ func TestDomainValidator(t *testing.T) {
 	testValidator(t, []string{"xxxx"}, []string{"isDomain=true"}, cases)
 }
 
+func TestPatternValidator(t *testing.T) {
+	cases := []testCase{
+		{"eth0", true},
+		{"eth0,eth1", true},
+		{"Eth0", false},
+		{"eth0,", false},
+		{"", false},
+	}
+
+	testValidator(t, "xxxx", []string{"pattern=^[a-z]+[0-9]*(,[a-z]+[0-9]*)*$"}, cases)
+	testValidator(t, []string{"xxxx"}, util.SplitRestTag("pattern=^[a-z]+[0-9]*(,[a-z]+[0-9]*)*$"), cases)
+}
+
+func TestFormatValidator(t *testing.T) {
+	formatCases := map[string][]testCase{
+		"isIPv4": {
+			{"10.0.0.1", true},
+			{"10.0.0.256", false},
+			{"2001:db8::1", false},
+			{"10.0.0.0/8", false},
+		},
+		"isIPv6": {
+			{"2001:db8::1", true},
+			{"::ffff:10.0.0.1", true},
+			{"10.0.0.1", false},
+			{"2001:db8::g", false},
+		},
+		"isCIDR": {
+			{"10.0.0.0/8", true},
+			{"2001:db8::/32", true},
+			{"10.0.0.1", false},
+			{"10.0.0.0/33", false},
+		},
+		"isMAC": {
+			{"00:1a:2b:3c:4d:5e", true},
+			{"00-1A-2B-3C-4D-5E", true},
+			{"00:1a:2b:3c:4d", false},
+		},
+		"isEmail": {
+			{"admin@example.com", true},
+			{"Admin <admin@example.com>", false},
+			{"admin", false},
+		},
+		"isURL": {
+			{"https://example.com/path?a=1", true},
+			{"http://10.0.0.1:8080", true},
+			{"/path", false},
+			{"example.com", false},
+		},
+		"isUUID": {
+			{"123e4567-e89b-12d3-a456-426614174000", true},
+			{"123E4567-E89B-12D3-A456-426614174000", true},
+			{"123e4567e89b12d3a456426614174000", false},
+			{"123e4567-e89b-12d3-a456-42661417400g", false},
+		},
+	}
+
+	for tag, cases := range formatCases {
+		testValidator(t, "xxxx", []string{tag}, cases)
+		testValidator(t, []string{"xxxx"}, []string{tag + "=true"}, cases)
+		testValidator(t, map[string]string{"key": "xxxx"}, []string{tag}, cases)
+	}
+}
+
+func TestFloatRangeValidator(t *testing.T) {
+	cases := []testCase{
+		{0.5, true},
+		{0.1, true},
+		{0.09, false},
+		{2.5, false},
+	}
+	testValidator(t, float64(1), []string{"min=0.1", "max=2.5"}, cases)
+	testValidator(t, []float64{1}, []string{"min=0.1", "max=2.5"}, cases)
+	testValidator(t, map[string]float64{"a": 1}, []string{"min=0.1", "max=2.5"}, cases)
+
+	for _, tags := range [][]string{{"min=a"}, {"min=2", "max=1"}, {"min=1", "min=2"}} {
+		_, err := Build(reflect.TypeOf(float32(1)), tags)
+		ut.Assert(t, err != nil, "tags %v should fail", tags)
+	}
+}
+
+func TestTimeRangeValidator(t *testing.T) {
+	now := time.Now()
+	cases := []testCase{
+		{now.Add(time.Hour), true},
+		{now.Add(-time.Hour), false},
+		{time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC), false},
+	}
+	testValidator(t, now, []string{"after=now", "before=2100-01-01T00:00:00Z"}, cases)
+	testValidator(t, []time.Time{now}, []string{"after=now", "before=2100-01-01T00:00:00Z"}, cases)
+
+	cases = []testCase{
+		{now.Add(-time.Hour), true},
+		{now.Add(time.Hour), false},
+		{time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), false},
+	}
+	testValidator(t, now, []string{"after=2000-01-01T00:00:00Z", "before=now"}, cases)
+
+	for _, tags := range [][]string{{"after=yesterday"}, {"after=2100-01-01T00:00:00Z", "before=2000-01-01T00:00:00Z"}} {
+		_, err := Build(reflect.TypeOf(now), tags)
+		ut.Assert(t, err != nil, "tags %v should fail", tags)
+	}
+}
+
+func TestIPFormatValidator(t *testing.T) {
+	cases := []testCase{
+		{netip.MustParseAddr("10.0.0.1"), true},
+		{netip.MustParseAddr("2001:db8::1"), false},
+	}
+	testValidator(t, netip.Addr{}, []string{"isIPv4"}, cases)
+	testValidator(t, []netip.Addr{}, []string{"isIPv4"}, cases)
+	testValidator(t, map[string]netip.Addr{}, []string{"isIPv4"}, cases)
+
+	validators, err := Build(reflect.TypeOf(netip.Addr{}), []string{"isCIDR", "isUUID"})
+	ut.Assert(t, err == nil && len(validators) == 0, "")
+}
+
+func TestCustomValidator(t *testing.T) {
+	MustRegisterValidator("even", func(v interface{}) error {
+		if reflect.ValueOf(v).Int()%2 != 0 {
+			return fmt.Errorf("%v isn't even", v)
+		}
+		return nil
+	})
+	MustRegisterValidator("lessThan100", func(v interface{}) error {
+		if reflect.ValueOf(v).Int() >= 100 {
+			return &Error{Rule: RuleMax, Limit: 100, Message: fmt.Sprintf("%v should < 100", v)}
+		}
+		return nil
+	})
+	ut.Assert(t, RegisterValidator("even", func(interface{}) error { return nil }) != nil, "")
+	ut.Assert(t, RegisterValidator("a|b", func(interface{}) error { return nil }) != nil, "")
+	ut.Assert(t, RegisterValidator("nil", nil) != nil, "")
+
+	cases := []testCase{
+		{2, true},
+		{3, false},
+		{100, false},
+	}
+	testValidator(t, int(10), []string{"validate=even|lessThan100"}, cases)
+	testValidator(t, []int{10}, []string{"validate=even|lessThan100"}, cases)
+	testValidator(t, map[string]int{"a": 10}, []string{"validate=even|lessThan100"}, cases)
+
+	validators, _ := Build(reflect.TypeOf(10), []string{"validate=even|lessThan100"})
+	err := validators[0].Validate(3)
+	ut.Equal(t, err, error(&Error{Rule: RuleValidate, Limit: "even", Message: "3 isn't even"}))
+	err = validators[0].Validate(102)
+	ut.Equal(t, err, error(&Error{Rule: RuleMax, Limit: 100, Message: "102 should < 100"}))
+
+	_, err = Build(reflect.TypeOf(10), []string{"validate=unknown"})
+	ut.Assert(t, err != nil, "")
+
+	MustRegisterValidator("positive", func(v interface{}) error {
+		if reflect.ValueOf(v).Float() <= 0 {
+			return fmt.Errorf("%v isn't positive", v)
+		}
+		return nil
+	})
+	cases = []testCase{
+		{0.5, true},
+		{0.0, false},
+		{-1.5, false},
+	}
+	testValidator(t, float64(1), []string{"validate=positive"}, cases)
+	testValidator(t, []float64{1}, []string{"validate=positive"}, cases)
+	testValidator(t, map[string]float64{"a": 1}, []string{"validate=positive"}, cases)
+}
+
 func testValidator(t *testing.T, fieldValue interface{}, tags []string, cases []testCase) {
 	validators, err := Build(reflect.TypeOf(fieldValue), tags)
 	ut.Assert(t, err == nil && len(validators) == 1, "")
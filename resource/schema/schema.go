@@ -17,12 +17,18 @@ import (
 type Schema struct {
 	version          *resource.APIVersion
 	fields           resourcefield.ResourceField
+	access           *resourcefield.FieldAccess
 	actions          []resource.Action
 	handler          resource.Handler
 	resourceKind     resource.ResourceKind
 	resourceName     string
 	resourceKindName string
+	fieldNames       map[string]struct{}
 	children         []*Schema
+	//converter to the hub kind, nil if the kind is handled by its own handler
+	converter *resource.Converter
+	//fields of action inputs with rest tags, key is action name
+	actionFields map[string]resourcefield.ResourceField
 }
 
 func NewSchema(version *resource.APIVersion, kind resource.ResourceKind, handler resource.Handler) (*Schema, error) {
@@ -39,13 +45,48 @@ func NewSchema(version *resource.APIVersion, kind resource.ResourceKind, handler
 		return nil, err
 	}
 
+	actionFields := make(map[string]resourcefield.ResourceField)
+	for _, action := range kind.GetActions() {
+		//only struct input has fields to validate
+		if action.Input == nil {
+			continue
+		} else if k := util.Inspect(reflect.TypeOf(action.Input)); k != util.Struct && k != util.StructPtr {
+			continue
+		}
+		if f, err := resourcefield.New(reflect.TypeOf(action.Input)); err != nil {
+			return nil, fmt.Errorf("input of action %s is invalid: %s", action.Name, err.Error())
+		} else if f != nil {
+			actionFields[action.Name] = f
+		}
+	}
+
+	access, err := resourcefield.AccessOf(gt)
+	if err != nil {
+		return nil, err
+	}
+
+	//update gets current resource to keep readOnly fields and check
+	//immutable fields
+	if handler != nil && handler.GetUpdateHandler() != nil && handler.GetGetHandler() == nil &&
+		(len(access.ReadOnlyFields()) > 0 || len(access.ImmutableFields()) > 0) {
+		return nil, fmt.Errorf("handler has update method but without get method")
+	}
+
+	fieldNames := make(map[string]struct{})
+	for _, name := range resourcedoc.FieldNames(kind) {
+		fieldNames[name] = struct{}{}
+	}
+
 	return &Schema{
 		version:          version,
 		fields:           fields,
+		access:           access,
+		actionFields:     actionFields,
 		handler:          handler,
 		resourceKind:     kind,
 		resourceName:     resource.DefaultResourceName(kind),
 		resourceKindName: resource.DefaultKindName(kind),
+		fieldNames:       fieldNames,
 	}, nil
 }
 
@@ -65,7 +106,7 @@ func (s *Schema) GetChildren() []*Schema {
 	return s.children
 }
 
-func (s *Schema) CreateResourceFromPathSegments(parent resource.Resource, segments []string, method, action string, body []byte) (resource.Resource, *goresterr.APIError) {
+func (s *Schema) CreateResourceFromPathSegments(parent resource.Resource, segments []string, method, action string, body []byte, strict bool) (resource.Resource, *goresterr.APIError) {
 	segmentCount := len(segments)
 	if segmentCount == 0 {
 		return parent, nil
@@ -89,14 +130,14 @@ func (s *Schema) CreateResourceFromPathSegments(parent resource.Resource, segmen
 	if segmentCount > 1 {
 		if err := util.ValidateString(segments[1]); err != nil {
 			return nil, goresterr.NewAPIError(goresterr.InvalidFormat,
-				goresterr.ErrorMessage{MessageEN: fmt.Sprintf("invalid resource %s id", r.GetType())})
+				*goresterr.NewLocalizedErrorMessage("", goresterr.MessageInvalidResourceID, goresterr.Params{"type": r.GetType()}))
 		} else {
 			r.SetID(segments[1])
 		}
 	}
 
 	if segmentCount <= 2 {
-		if err := s.validateAndFillResource(r, method, action, body); err != nil {
+		if err := s.validateAndFillResource(r, method, action, body, strict); err != nil {
 			return nil, err
 		} else {
 			r.SetType(resource.DefaultKindName(s.resourceKind))
@@ -108,57 +149,83 @@ func (s *Schema) CreateResourceFromPathSegments(parent resource.Resource, segmen
 	}
 
 	for _, child := range s.children {
-		if r, err := child.CreateResourceFromPathSegments(r, segments[2:], method, action, body); err != nil {
+		if r, err := child.CreateResourceFromPathSegments(r, segments[2:], method, action, body, strict); err != nil {
 			return nil, err
 		} else if r != nil {
 			return r, nil
 		}
 	}
 	return nil, goresterr.NewAPIError(goresterr.NotFound,
-		goresterr.ErrorMessage{MessageEN: fmt.Sprintf("%s is not a child of %s", segments[2], s.resourceName)})
+		*goresterr.NewLocalizedErrorMessage("", goresterr.MessageNotChild,
+			goresterr.Params{"child": segments[2], "parent": s.resourceName}))
 }
 
-func (s *Schema) validateAndFillResource(r resource.Resource, method, action string, body []byte) *goresterr.APIError {
+func (s *Schema) validateAndFillResource(r resource.Resource, method, action string, body []byte, strict bool) *goresterr.APIError {
 	if method == http.MethodPost && action != "" {
-		if action_, err := s.parseAction(action, body); err != nil {
+		if action_, err := s.parseAction(action, body, strict); err != nil {
 			return err
 		} else {
 			r.SetAction(action_)
 		}
 	} else if method == http.MethodPost || method == http.MethodPut {
 		if body != nil {
-			json.Unmarshal(body, r)
+			if err := resource.DecodeJSON(body, r, strict); err != nil {
+				return err
+			}
 		}
-		if s.fields != nil {
-			objMap := make(map[string]interface{})
-			if body != nil {
-				if err := json.Unmarshal(body, &objMap); err != nil {
-					return goresterr.NewAPIError(goresterr.InvalidBodyContent,
-						goresterr.ErrorMessage{MessageEN: fmt.Sprintf("request body isn't a string map:%s", err.Error())})
-				}
+		objMap := make(map[string]interface{})
+		if body != nil {
+			if err := json.Unmarshal(body, &objMap); err != nil {
+				return goresterr.NewAPIError(goresterr.InvalidBodyContent,
+					*goresterr.NewLocalizedErrorMessage("", goresterr.MessageInvalidJSON, goresterr.Params{"reason": err.Error()}))
 			}
+		}
+		if err := s.stripReadOnlyFields(r, objMap, strict); err != nil {
+			return err
+		}
+		if s.fields != nil {
 			if err := s.fields.Validate(r, objMap); err != nil {
-				return goresterr.NewAPIError(goresterr.InvalidBodyContent, goresterr.ErrorMessage{MessageEN: err.Error()})
+				return fieldValidateError(err)
 			}
+			s.fields.FillDefault(r, objMap)
+		}
+		return validateResource(r, method)
+	}
+	return nil
+}
+
+func validateResource(r resource.Resource, method string) *goresterr.APIError {
+	if v, ok := r.(resource.ResourceValidator); ok {
+		if err := v.Validate(method); err != nil {
+			return resourceValidateError(err)
 		}
 	}
 	return nil
 }
 
-func (s *Schema) parseAction(name string, body []byte) (*resource.Action, *goresterr.APIError) {
+func (s *Schema) parseAction(name string, body []byte, strict bool) (*resource.Action, *goresterr.APIError) {
 	if s.handler.GetActionHandler() == nil {
 		return nil, goresterr.NewAPIError(goresterr.NotFound,
-			goresterr.ErrorMessage{MessageEN: fmt.Sprintf("no handler for action %s", name)})
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageNoHandler, goresterr.Params{"method": "action " + name}))
 
 	}
 
 	actions := s.resourceKind.GetActions()
 	for i, action := range actions {
 		if action.Name == name {
-			if action.Input != nil {
+			if action.Input != nil && strict {
+				if err := resource.DecodeJSON(body, action.Input, true); err != nil {
+					return nil, err
+				}
+			} else if action.Input != nil {
 				if err := json.Unmarshal(body, action.Input); err != nil {
 					return nil, goresterr.NewAPIError(goresterr.InvalidBodyContent,
-						goresterr.ErrorMessage{MessageEN: fmt.Sprintf("failed to parse action params: %s", err.Error())})
+						*goresterr.NewLocalizedErrorMessage("", goresterr.MessageInvalidActionInput, goresterr.Params{"action": name, "reason": err.Error()}))
+				}
+			}
+			if fields, ok := s.actionFields[name]; ok {
+				if err := validateActionInput(fields, action.Input, body); err != nil {
+					return nil, err
 				}
 			}
 			a := actions[i]
@@ -167,7 +234,33 @@ func (s *Schema) parseAction(name string, body []byte) (*resource.Action, *gores
 	}
 
 	return nil, goresterr.NewAPIError(goresterr.NotFound,
-		goresterr.ErrorMessage{MessageEN: fmt.Sprintf("unknown action %s", name)})
+		*goresterr.NewLocalizedErrorMessage("", goresterr.MessageUnknownAction, goresterr.Params{"action": name}))
+}
+
+func validateActionInput(fields resourcefield.ResourceField, input interface{}, body []byte) *goresterr.APIError {
+	objMap := make(map[string]interface{})
+	if len(body) != 0 {
+		if err := json.Unmarshal(body, &objMap); err != nil {
+			return goresterr.NewAPIError(goresterr.InvalidBodyContent,
+				*goresterr.NewLocalizedErrorMessage("", goresterr.MessageInvalidJSON, goresterr.Params{"reason": err.Error()}))
+		}
+	}
+	if err := fields.Validate(input, objMap); err != nil {
+		return fieldValidateError(err)
+	}
+	fields.FillDefault(input, objMap)
+	return nil
+}
+
+func (s *Schema) ValidateFields(fields []string) *goresterr.APIError {
+	for _, field := range fields {
+		if _, ok := s.fieldNames[field]; ok == false {
+			return goresterr.NewAPIError(goresterr.InvalidFormat,
+				*goresterr.NewLocalizedErrorMessage(fmt.Sprintf("%s has no field %s", s.resourceKindName, field),
+					goresterr.MessageInvalidQuery, goresterr.Params{"name": resource.FilterNameFields}))
+		}
+	}
+	return nil
 }
 
 func (s *Schema) AddChild(child *Schema) error {
@@ -290,7 +383,11 @@ func (s *Schema) AddLinksToResourceCollection(rs *resource.ResourceCollection, h
 		r.SetLinks(s.generateResourceLinks(r, cl))
 	}
 
-	rs.SetLinks(map[resource.ResourceLinkType]resource.ResourceLink{resource.SelfLink: resource.ResourceLink(cl)})
+	links := map[resource.ResourceLinkType]resource.ResourceLink{resource.SelfLink: resource.ResourceLink(cl)}
+	if nextQuery := rs.GetNextQuery(); nextQuery != "" {
+		links[resource.NextLink] = resource.ResourceLink(cl + "?" + nextQuery)
+	}
+	rs.SetLinks(links)
 	return nil
 }
 
@@ -318,13 +415,21 @@ func (s *Schema) generateResourceLinks(r resource.Resource, parentLink string) m
 }
 
 func (s *Schema) WriteJsonDoc(path string) error {
+	doc, err := s.ResourceDocument()
+	if err != nil {
+		return err
+	}
+	return doc.WriteJsonFile(path)
+}
+
+func (s *Schema) ResourceDocument() (*resourcedoc.ResourceDocument, error) {
+	return resourcedoc.NewResourceDocument(s.resourceKindName, s.resourceKind, s.handler, s.parentKindNames())
+}
+
+func (s *Schema) parentKindNames() []string {
 	var parents []string
 	for _, parent := range s.resourceKind.GetParents() {
 		parents = append(parents, resource.DefaultKindName(parent))
 	}
-	resource, err := resourcedoc.NewResourceDocument(s.resourceKindName, s.resourceKind, s.handler, parents)
-	if err != nil {
-		return err
-	}
-	return resource.WriteJsonFile(path)
+	return parents
 }
@@ -2,7 +2,6 @@ package schema
 
 import (
 	"fmt"
-	"io/ioutil"
 	"net/http"
 
 	goresterr "github.com/linkingthing/gorest/error"
@@ -10,7 +9,8 @@ import (
 )
 
 type SchemaManager struct {
-	schemas []*VersionedSchemas
+	schemas   []*VersionedSchemas
+	discovery bool
 }
 
 var _ resource.SchemaManager = &SchemaManager{}
@@ -56,25 +56,35 @@ func (m *SchemaManager) CreateResourceFromRequest(req *http.Request) (resource.R
 	}
 
 	var body []byte
-	if (req.Method == http.MethodPost || req.Method == http.MethodPut) && req.Body != nil {
-		var err error
-		body, err = ioutil.ReadAll(req.Body)
-		if err != nil {
-			return nil, goresterr.NewAPIError(goresterr.InvalidBodyContent,
-				goresterr.ErrorMessage{MessageEN: fmt.Sprintf("failed to read request body: %s", err.Error())})
+	if (req.Method == http.MethodPost || req.Method == http.MethodPut || req.Method == http.MethodPatch) && req.Body != nil {
+		//content type of patch is checked when the patch is parsed
+		if req.Method != http.MethodPatch {
+			if err := resource.CheckJSONContentType(req); err != nil {
+				return nil, err
+			}
+		}
+
+		var err *goresterr.APIError
+		if body, err = resource.ReadRequestBody(req); err != nil {
+			return nil, err
 		}
-		defer req.Body.Close()
 	}
 
+	strict := resource.GetBodyOptions(req).Strict
 	for _, vs := range m.schemas {
-		if r, err := vs.CreateResourceFromRequest(req.Method, path, body, action); err != nil {
+		if r, err := vs.CreateResourceFromRequest(req.Method, path, body, action, strict); err != nil {
 			return nil, err
 		} else if r != nil {
-			return r, err
+			if req.Method == http.MethodPatch {
+				if err := parsePatch(req, r, body, strict); err != nil {
+					return nil, err
+				}
+			}
+			return r, nil
 		}
 	}
 	return nil, goresterr.NewAPIError(goresterr.NotFound,
-		goresterr.ErrorMessage{MessageEN: fmt.Sprintf("%s has unknown api version", req.URL.Path)})
+		*goresterr.NewLocalizedErrorMessage("", goresterr.MessageUnknownAPIVersion, goresterr.Params{"url": req.URL.Path}))
 }
 
 func (m *SchemaManager) GetSchema(v *resource.APIVersion, kind resource.ResourceKind) resource.Schema {
@@ -89,7 +99,10 @@ func (m *SchemaManager) GenerateResourceRoute() resource.ResourceRoute {
 	for _, vs := range m.schemas {
 		route = route.Merge(vs.GenerateResourceRoute())
 	}
-	return route
+	if m.discovery == false {
+		return route
+	}
+	return route.Merge(m.generateDiscoveryRoute())
 }
 
 func (m *SchemaManager) WriteJsonDocs(v *resource.APIVersion, path string) error {
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	ut "github.com/linkingthing/cement/unittest"
+	goresterr "github.com/linkingthing/gorest/error"
 	"github.com/linkingthing/gorest/resource"
 )
 
@@ -54,6 +55,22 @@ func TestGenerateResourceRoute(t *testing.T) {
 			ut.Equal(t, urls, expectDeleteAndPutPaths)
 		}
 	}
+
+	//discovery routes are only for GET
+	mgr.SetDiscovery(true)
+	expectGetPaths := append([]string{
+		"/apis",
+		"/apis/testing/v1",
+		"/apis/testing/v1/schemas/:kind",
+	}, expectGetAndPostPaths...)
+	sort.StringSlice(expectGetPaths).Sort()
+	route := mgr.GenerateResourceRoute()
+	getPaths := route[http.MethodGet]
+	sort.StringSlice(getPaths).Sort()
+	ut.Equal(t, getPaths, expectGetPaths)
+	postPaths := route[http.MethodPost]
+	sort.StringSlice(postPaths).Sort()
+	ut.Equal(t, postPaths, expectGetAndPostPaths)
 }
 
 func TestCreateResourceFromRequest(t *testing.T) {
@@ -220,7 +237,7 @@ func TestAddResourceCollectionLink(t *testing.T) {
 	for _, tc := range cases {
 		req, _ := http.NewRequest(http.MethodGet, tc.url, nil)
 		r, _ := mgr.CreateResourceFromRequest(req)
-		coll, err := resource.NewResourceCollection(nil, tc.children)
+		coll, err := resource.NewResourceCollection(&resource.Context{Resource: r}, tc.children)
 		ut.Assert(t, err == nil, "get err %v", err)
 		err = r.GetSchema().(*Schema).AddLinksToResourceCollection(coll, "http://127.0.0.1:5555")
 		ut.Assert(t, err == nil, "")
@@ -230,3 +247,16 @@ func TestAddResourceCollectionLink(t *testing.T) {
 		}
 	}
 }
+
+type updateOnlyHandler struct{}
+
+func (h *updateOnlyHandler) Update(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	return ctx.Resource, nil
+}
+
+func TestImportUpdateWithoutGet(t *testing.T) {
+	mgr := NewSchemaManager()
+	mgr.MustImport(&version, Cluster{}, &resource.DumbHandler{})
+	//creationTimestamp of ResourceBase is readOnly
+	ut.Assert(t, mgr.Import(&version, Volume{}, &updateOnlyHandler{}) != nil, "update without get should be rejected")
+}
@@ -30,33 +30,42 @@ func (s *VersionedSchemas) VersionEquals(v *resource.APIVersion) bool {
 }
 
 func (s *VersionedSchemas) Import(kind resource.ResourceKind, handler resource.Handler) error {
+	_, err := s.importSchema(kind, handler)
+	return err
+}
+
+func (s *VersionedSchemas) importSchema(kind resource.ResourceKind, handler resource.Handler) (*Schema, error) {
 	schema, err := NewSchema(s.version, kind, handler)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	parents := kind.GetParents()
 	for _, parent := range parents {
 		parentSchema := s.GetSchema(parent)
 		if parentSchema == nil {
-			return fmt.Errorf("%s who is parent of %s hasn't been imported", resource.DefaultKindName(parent), resource.DefaultKindName(kind))
+			return nil, fmt.Errorf("%s who is parent of %s hasn't been imported", resource.DefaultKindName(parent), resource.DefaultKindName(kind))
 		} else {
 			if err := parentSchema.AddChild(schema); err != nil {
-				return err
+				return nil, err
 			}
 		}
 	}
 
 	if len(parents) == 0 {
-		return s.addTopleveSchema(schema)
+		if err := s.addTopleveSchema(schema); err != nil {
+			return nil, err
+		}
 	}
 
-	return nil
+	return schema, nil
 }
 
 var multiSlashRegexp = regexp.MustCompile("//+")
 
-func (s *VersionedSchemas) CreateResourceFromRequest(method, path string, body []byte, action string) (resource.Resource, *goresterr.APIError) {
+// in strict mode, unknown fields and fields with mismatched type in body
+// are rejected
+func (s *VersionedSchemas) CreateResourceFromRequest(method, path string, body []byte, action string, strict bool) (resource.Resource, *goresterr.APIError) {
 	if strings.HasPrefix(path, s.versionUrl) == false {
 		return nil, nil
 	}
@@ -68,7 +77,8 @@ func (s *VersionedSchemas) CreateResourceFromRequest(method, path string, body [
 	}
 
 	if len(path) == 0 {
-		return nil, goresterr.NewAPIError(goresterr.InvalidFormat, goresterr.ErrorMessage{MessageEN: "no schema name in url"})
+		return nil, goresterr.NewAPIError(goresterr.InvalidFormat,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageNoResourceInURL, goresterr.Params{"url": s.versionUrl}))
 	} else {
 		path = path[1:] //get rid of first '/'
 	}
@@ -83,18 +93,18 @@ func (s *VersionedSchemas) CreateResourceFromRequest(method, path string, body [
 	segmentCount := len(segments)
 	if segmentCount == 0 {
 		return nil, goresterr.NewAPIError(goresterr.NotFound,
-			goresterr.ErrorMessage{MessageEN: "only api version without any resource"})
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageNoResourceInURL, goresterr.Params{"url": s.versionUrl}))
 	}
 
 	for _, schema := range s.toplevelSchemas {
-		if r, err := schema.CreateResourceFromPathSegments(nil, segments, method, action, body); err != nil {
+		if r, err := schema.CreateResourceFromPathSegments(nil, segments, method, action, body, strict); err != nil {
 			return nil, err
 		} else if r != nil {
 			return r, nil
 		}
 	}
 	return nil, goresterr.NewAPIError(goresterr.NotFound,
-		goresterr.ErrorMessage{MessageEN: fmt.Sprintf("no resource with kind %s", segments[0])})
+		*goresterr.NewLocalizedErrorMessage("", goresterr.MessageUnknownResourceKind, goresterr.Params{"kind": segments[0]}))
 }
 
 func (s *VersionedSchemas) addTopleveSchema(schema *Schema) error {
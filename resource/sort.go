@@ -0,0 +1,195 @@
+package resource
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/linkingthing/gorest/error"
+)
+
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+const FilterNameSort = "sort"
+
+// createTime is an alias of creationTimestamp which is stored as create_time
+const (
+	sortFieldCreateTime        = "createTime"
+	sortFieldCreationTimestamp = "creationTimestamp"
+)
+
+type Sort struct {
+	Field string
+	Order SortOrder
+}
+
+// sort=-createTime,name means order by createTime desc then name asc
+func parseSorts(values []string) ([]Sort, *error.APIError) {
+	var sorts []Sort
+	for _, value := range values {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			order := SortAsc
+			if strings.HasPrefix(field, "-") {
+				order = SortDesc
+				field = field[1:]
+			} else if strings.HasPrefix(field, "+") {
+				field = field[1:]
+			}
+
+			if field == "" {
+				return nil, error.NewAPIError(error.InvalidFormat,
+					*error.NewLocalizedErrorMessage(fmt.Sprintf("sort %s has empty field", value),
+						error.MessageInvalidQuery, error.Params{"name": FilterNameSort}))
+			}
+			sorts = append(sorts, Sort{Field: field, Order: order})
+		}
+	}
+	return sorts, nil
+}
+
+func validateSorts(r Resource, sorts []Sort) *error.APIError {
+	if r == nil || len(sorts) == 0 {
+		return nil
+	}
+
+	typ := reflect.TypeOf(r)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	for _, s := range sorts {
+		if _, ok := FindSortField(typ, s.Field); ok == false {
+			return error.NewAPIError(error.InvalidFormat,
+				*error.NewLocalizedErrorMessage(fmt.Sprintf("unknown sort field %s", s.Field),
+					error.MessageInvalidQuery, error.Params{"name": FilterNameSort}))
+		}
+	}
+	return nil
+}
+
+// field is matched by json name or go field name, inline embedded
+// structs like ResourceBase are searched too, stores should resolve
+// the sort fields with it to accept the same fields as validateSorts
+func FindSortField(typ reflect.Type, name string) ([]int, bool) {
+	if typ.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	if name == sortFieldCreateTime {
+		name = sortFieldCreationTimestamp
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Anonymous {
+			if index, ok := FindSortField(field.Type, name); ok {
+				return append([]int{i}, index...), true
+			}
+			continue
+		}
+
+		if field.IsExported() == false {
+			continue
+		}
+
+		jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if jsonName == "-" {
+			continue
+		}
+
+		if jsonName == name || strings.EqualFold(field.Name, name) {
+			return []int{i}, true
+		}
+	}
+	return nil, false
+}
+
+func sortResources(resources []Resource, sorts []Sort) {
+	if len(resources) < 2 || len(sorts) == 0 {
+		return
+	}
+
+	typ := reflect.TypeOf(resources[0]).Elem()
+	indexes := make([][]int, 0, len(sorts))
+	for _, s := range sorts {
+		index, ok := FindSortField(typ, s.Field)
+		if ok == false {
+			return
+		}
+		indexes = append(indexes, index)
+	}
+
+	sort.SliceStable(resources, func(i, j int) bool {
+		vi := reflect.ValueOf(resources[i]).Elem()
+		vj := reflect.ValueOf(resources[j]).Elem()
+		for k, s := range sorts {
+			c := compareSortValue(vi.FieldByIndex(indexes[k]), vj.FieldByIndex(indexes[k]))
+			if c == 0 {
+				continue
+			}
+
+			if s.Order == SortDesc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// nil pointer is less than any value
+func compareSortValue(a, b reflect.Value) int {
+	if a.Kind() == reflect.Ptr {
+		if a.IsNil() || b.IsNil() {
+			return compareBool(a.IsNil() == false, b.IsNil() == false)
+		}
+		a, b = a.Elem(), b.Elem()
+	}
+
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareOrdered(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compareOrdered(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return compareOrdered(a.Float(), b.Float())
+	case reflect.String:
+		return compareOrdered(a.String(), b.String())
+	case reflect.Bool:
+		return compareBool(a.Bool(), b.Bool())
+	case reflect.Struct:
+		if a.Type().ConvertibleTo(timeType) {
+			return a.Convert(timeType).Interface().(time.Time).Compare(b.Convert(timeType).Interface().(time.Time))
+		}
+	}
+
+	return compareOrdered(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+}
+
+func compareOrdered[T int64 | uint64 | float64 | string](a, b T) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func compareBool(a, b bool) int {
+	if a == b {
+		return 0
+	} else if a {
+		return 1
+	}
+	return -1
+}
@@ -0,0 +1,76 @@
+package resource
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	ut "github.com/linkingthing/cement/unittest"
+)
+
+func TestParseSorts(t *testing.T) {
+	sorts, err := parseSorts([]string{"-createTime, name", "+number"})
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sorts, []Sort{
+		{Field: "createTime", Order: SortDesc},
+		{Field: "name", Order: SortAsc},
+		{Field: "number", Order: SortAsc},
+	})
+
+	_, err = parseSorts([]string{"name,,age"})
+	ut.Assert(t, err != nil, "")
+	_, err = parseSorts([]string{"-"})
+	ut.Assert(t, err != nil, "")
+
+	ut.Assert(t, validateSorts(&dumbResource{}, []Sort{{Field: "createTime"}, {Field: "Number"}, {Field: "id"}}) == nil, "")
+	ut.Assert(t, validateSorts(&dumbResource{}, []Sort{{Field: "name"}}) != nil, "")
+}
+
+func TestSortCollection(t *testing.T) {
+	now := time.Now()
+	var rs []*dumbResource
+	for i, number := range []int{3, 1, 3, 2} {
+		r := &dumbResource{Number: number}
+		r.SetID(string(rune('a' + i)))
+		r.SetCreationTimestamp(now.Add(time.Duration(i) * time.Second))
+		rs = append(rs, r)
+	}
+
+	collection := &dumbResource{}
+	collection.SetType("dumbresource")
+	cases := []struct {
+		sorts []Sort
+		ids   []string
+	}{
+		{[]Sort{{Field: "number", Order: SortAsc}}, []string{"b", "d", "a", "c"}},
+		{[]Sort{{Field: "number", Order: SortDesc}, {Field: "createTime", Order: SortDesc}}, []string{"c", "a", "d", "b"}},
+		{[]Sort{{Field: "id", Order: SortDesc}}, []string{"d", "c", "b", "a"}},
+	}
+
+	for _, tc := range cases {
+		req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v1/dumbresources", nil)
+		ctx := &Context{Request: req, Resource: collection, sorts: tc.sorts}
+		rc, err := NewResourceCollection(ctx, rs)
+		ut.Assert(t, err == nil, "")
+		var ids []string
+		for _, r := range rc.GetResources() {
+			ids = append(ids, r.GetID())
+		}
+		ut.Equal(t, ids, tc.ids)
+	}
+
+	//resources paginated by store aren't sorted again
+	pagination := &Pagination{PageSize: 4, PageNum: 1}
+	pagination.SetTotal(8)
+	ctx := &Context{Resource: collection, sorts: cases[0].sorts, pagination: pagination}
+	rc, err := NewResourceCollection(ctx, rs)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, rc.GetResources()[0].GetID(), "a")
+
+	//resources sorted by store aren't sorted again
+	ctx = &Context{Resource: collection, sorts: cases[0].sorts}
+	ctx.SetSortsApplied()
+	rc, err = NewResourceCollection(ctx, rs)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, rc.GetResources()[0].GetID(), "a")
+}
@@ -9,37 +9,45 @@ import (
 
 	goresterr "github.com/linkingthing/gorest/error"
 	"github.com/linkingthing/gorest/resource"
+	"github.com/linkingthing/gorest/resource/schema/resourcefield"
 )
 
-func restHandler(ctx *resource.Context) *goresterr.APIError {
+func restHandler(ctx *resource.Context, events EventSource) *goresterr.APIError {
 	if ctx.Resource.GetAction() != nil {
 		return handleAction(ctx)
 	}
 
 	switch ctx.Method {
 	case http.MethodGet:
+		if ctx.IsWatch() {
+			return handleWatch(ctx, events)
+		}
 		return handleList(ctx)
 	case http.MethodPost:
-		return handleCreate(ctx)
+		return handleCreate(ctx, events)
 	case http.MethodPut:
-		return handleUpdate(ctx)
+		return handleUpdate(ctx, events)
+	case http.MethodPatch:
+		return handlePatch(ctx, events)
 	case http.MethodDelete:
-		return handleDelete(ctx)
+		return handleDelete(ctx, events)
 	default:
-		return goresterr.NewAPIError(goresterr.NotFound, goresterr.ErrorMessage{MessageEN: "no found request handler"})
+		return goresterr.NewAPIError(goresterr.NotFound,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageUnsupportedMethod, goresterr.Params{"method": ctx.Request.Method}))
 	}
 }
 
-func handleCreate(ctx *resource.Context) *goresterr.APIError {
+func handleCreate(ctx *resource.Context, events EventSource) *goresterr.APIError {
 	schema := ctx.Resource.GetSchema()
 	handler := schema.GetHandler().GetCreateHandler()
 	if handler == nil {
-		return goresterr.NewAPIError(goresterr.NotFound, goresterr.ErrorMessage{MessageEN: "no handler for create"})
+		return goresterr.NewAPIError(goresterr.NotFound,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageNoHandler, goresterr.Params{"method": "create"}))
 	}
 
 	r, err := handler(ctx)
 	if err != nil {
-		return err.Localization(ctx.IsAcceptLanguageZH())
+		return err
 	}
 
 	ctx.Resource.SetID(r.GetID())
@@ -47,19 +55,22 @@ func handleCreate(ctx *resource.Context) *goresterr.APIError {
 	httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
 	if err := schema.AddLinksToResource(r, httpSchemeAndHost); err != nil {
 		return goresterr.NewAPIError(goresterr.ServerError,
-			goresterr.ErrorMessage{MessageEN: fmt.Sprintf("generate links failed:%s", err.Error())})
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageGenerateLinksFailed, goresterr.Params{"reason": err.Error()}))
 	}
+	publishEvent(ctx, events, EventAdded, r)
+	setETag(ctx.Response, r)
 	return WriteResponse(ctx.Response, http.StatusCreated, r)
 }
 
-func handleDelete(ctx *resource.Context) *goresterr.APIError {
+func handleDelete(ctx *resource.Context, events EventSource) *goresterr.APIError {
 	handler := ctx.Resource.GetSchema().GetHandler().GetDeleteHandler()
 	if handler == nil {
-		return goresterr.NewAPIError(goresterr.NotFound, goresterr.ErrorMessage{MessageEN: "no handler for delete"})
+		return goresterr.NewAPIError(goresterr.NotFound,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageNoHandler, goresterr.Params{"method": "delete"}))
 	}
 
 	if err := handler(ctx); err != nil {
-		return err.Localization(ctx.IsAcceptLanguageZH())
+		return err
 	}
 
 	kind, ok := ctx.Resource.(resource.ResourceKind)
@@ -70,27 +81,117 @@ func handleDelete(ctx *resource.Context) *goresterr.APIError {
 	if kind.SupportAsyncDelete() {
 		status = http.StatusAccepted
 	}
+
+	httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
+	if err := ctx.Resource.GetSchema().AddLinksToResource(ctx.Resource, httpSchemeAndHost); err == nil {
+		publishEvent(ctx, events, EventDeleted, ctx.Resource)
+	}
 	return WriteResponse(ctx.Response, status, nil)
 }
 
-func handleUpdate(ctx *resource.Context) *goresterr.APIError {
+func handleUpdate(ctx *resource.Context, events EventSource) *goresterr.APIError {
 	schema := ctx.Resource.GetSchema()
 	handler := schema.GetHandler().GetUpdateHandler()
 	if handler == nil {
-		return goresterr.NewAPIError(goresterr.NotFound, goresterr.ErrorMessage{MessageEN: "no handler for update"})
+		return goresterr.NewAPIError(goresterr.NotFound,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageNoHandler, goresterr.Params{"method": "update"}))
+	}
+
+	//readOnly fields are reset when the request body is parsed, they and
+	//immutable fields need current resource
+	hasAccessFields := schema.HasReadOnlyFields() || schema.HasImmutableFields()
+	getHandler := schema.GetHandler().GetGetHandler()
+	if getHandler == nil && hasAccessFields {
+		return goresterr.NewAPIError(goresterr.NotFound,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageNoHandler, goresterr.Params{"method": "get"}))
+	}
+
+	var current resource.Resource
+	if getHandler != nil && (hasAccessFields || hasExpectedResourceVersion(ctx)) {
+		r, err := getHandler(ctx)
+		if err != nil {
+			return err
+		}
+		if isNilResource(r) == false {
+			current = r
+		}
+	}
+
+	if err := checkResourceVersion(ctx, ctx.Resource, current); err != nil {
+		return err
+	}
+
+	if current != nil {
+		schema.RestoreReadOnlyFields(ctx.Resource, current)
+		if err := schema.CheckImmutableFields(ctx.Resource, current); err != nil {
+			return err
+		}
+	}
+
+	r, err := handler(ctx)
+	if err != nil {
+		return err
+	}
+
+	httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
+	if err := schema.AddLinksToResource(r, httpSchemeAndHost); err != nil {
+		return goresterr.NewAPIError(goresterr.ServerError,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageGenerateLinksFailed, goresterr.Params{"reason": err.Error()}))
+	}
+	r.SetType(ctx.Resource.GetType())
+	publishEvent(ctx, events, EventModified, r)
+	setETag(ctx.Response, r)
+	return WriteResponse(ctx.Response, http.StatusOK, r)
+}
+
+func handlePatch(ctx *resource.Context, events EventSource) *goresterr.APIError {
+	schema := ctx.Resource.GetSchema()
+	handler := schema.GetHandler().GetPatchHandler()
+	if handler == nil {
+		return goresterr.NewAPIError(goresterr.NotFound,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageNoHandler, goresterr.Params{"method": "patch"}))
+	}
+
+	getHandler := schema.GetHandler().GetGetHandler()
+	if getHandler == nil {
+		return goresterr.NewAPIError(goresterr.NotFound,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageNoHandler, goresterr.Params{"method": "get"}))
 	}
 
+	current, err := getHandler(ctx)
+	if err != nil {
+		return err
+	}
+
+	if isNilResource(current) {
+		return goresterr.NewAPIError(goresterr.NotFound,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageResourceNotFound,
+				goresterr.Params{"type": ctx.Resource.GetType(), "id": ctx.Resource.GetID()}))
+	}
+
+	patched, err := schema.ApplyPatch(ctx.Resource, current)
+	if err != nil {
+		return err
+	}
+
+	if err := checkResourceVersion(ctx, patched, current); err != nil {
+		return err
+	}
+
+	ctx.Resource = patched
 	r, err := handler(ctx)
 	if err != nil {
-		return err.Localization(ctx.IsAcceptLanguageZH())
+		return err
 	}
 
 	httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
 	if err := schema.AddLinksToResource(r, httpSchemeAndHost); err != nil {
 		return goresterr.NewAPIError(goresterr.ServerError,
-			goresterr.ErrorMessage{MessageEN: fmt.Sprintf("generate links failed:%s", err.Error())})
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageGenerateLinksFailed, goresterr.Params{"reason": err.Error()}))
 	}
 	r.SetType(ctx.Resource.GetType())
+	publishEvent(ctx, events, EventModified, r)
+	setETag(ctx.Response, r)
 	return WriteResponse(ctx.Response, http.StatusOK, r)
 }
 
@@ -100,38 +201,41 @@ func handleList(ctx *resource.Context) *goresterr.APIError {
 	if ctx.Resource.GetID() == "" {
 		handler := schema.GetHandler().GetListHandler()
 		if handler == nil {
-			return goresterr.NewAPIError(goresterr.NotFound, goresterr.ErrorMessage{MessageEN: "no found for list"})
+			return goresterr.NewAPIError(goresterr.NotFound,
+				*goresterr.NewLocalizedErrorMessage("no found for list", goresterr.MessageNoHandler, goresterr.Params{"method": "list"}))
 		}
 
 		data, err_ := handler(ctx)
 		if err_ != nil {
-			return err_.Localization(ctx.IsAcceptLanguageZH())
+			return err_
 		}
 		rc, err := resource.NewResourceCollection(ctx, data)
 		if err != nil {
-			return goresterr.NewAPIError(goresterr.ServerError, goresterr.ErrorMessage{MessageEN: err.Error()})
+			return goresterr.NewAPIError(goresterr.ServerError,
+				*goresterr.NewLocalizedErrorMessage("", goresterr.MessageInvalidListResult, goresterr.Params{"reason": err.Error()}))
 		}
 
 		httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
 		if err := schema.AddLinksToResourceCollection(rc, httpSchemeAndHost); err != nil {
 			return goresterr.NewAPIError(goresterr.ServerError,
-				goresterr.ErrorMessage{MessageEN: fmt.Sprintf("generate links failed:%s", err.Error())})
+				*goresterr.NewLocalizedErrorMessage("", goresterr.MessageGenerateLinksFailed, goresterr.Params{"reason": err.Error()}))
 		}
 		result = rc
 	} else {
 		handler := schema.GetHandler().GetGetHandler()
 		if handler == nil {
-			return goresterr.NewAPIError(goresterr.NotFound, goresterr.ErrorMessage{MessageEN: "no found for list"})
+			return goresterr.NewAPIError(goresterr.NotFound,
+				*goresterr.NewLocalizedErrorMessage("", goresterr.MessageNoHandler, goresterr.Params{"method": "get"}))
 		}
 		r, err := handler(ctx)
 		if err != nil {
-			return err.Localization(ctx.IsAcceptLanguageZH())
+			return err
 		}
 
-		if r == nil || (reflect.ValueOf(r).Kind() == reflect.Ptr && reflect.ValueOf(r).IsNil()) {
+		if isNilResource(r) {
 			return goresterr.NewAPIError(goresterr.NotFound,
-				goresterr.ErrorMessage{MessageEN: fmt.Sprintf("%s resource with id %s doesn't exist",
-					ctx.Resource.GetType(), ctx.Resource.GetID())})
+				*goresterr.NewLocalizedErrorMessage("", goresterr.MessageResourceNotFound,
+					goresterr.Params{"type": ctx.Resource.GetType(), "id": ctx.Resource.GetID()}))
 		} else {
 			//the resource handler returns mayn't include schema
 			r.SetSchema(ctx.Resource.GetSchema())
@@ -139,32 +243,45 @@ func handleList(ctx *resource.Context) *goresterr.APIError {
 			httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
 			if err := schema.AddLinksToResource(r, httpSchemeAndHost); err != nil {
 				return goresterr.NewAPIError(goresterr.ServerError,
-					goresterr.ErrorMessage{MessageEN: fmt.Sprintf("generate links failed:%s", err.Error())})
+					*goresterr.NewLocalizedErrorMessage("", goresterr.MessageGenerateLinksFailed, goresterr.Params{"reason": err.Error()}))
 			}
 			r.SetType(ctx.Resource.GetType())
 		}
+
+		setETag(ctx.Response, r)
+		if isNotModified(ctx, r) {
+			ctx.Response.WriteHeader(http.StatusNotModified)
+			return nil
+		}
 		result = r
 	}
 
-	return WriteResponse(ctx.Response, http.StatusOK, result)
+	return WriteResponseWithFields(ctx.Response, http.StatusOK, result, ctx.GetFields())
 }
 
 func handleAction(ctx *resource.Context) *goresterr.APIError {
 	handler := ctx.Resource.GetSchema().GetHandler().GetActionHandler()
 	if handler == nil {
-		return goresterr.NewAPIError(goresterr.NotFound, goresterr.ErrorMessage{MessageEN: "no handler for action"})
+		return goresterr.NewAPIError(goresterr.NotFound,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageNoHandler, goresterr.Params{"method": "action"}))
 	}
 
 	result, err := handler(ctx)
 	if err != nil {
-		return err.Localization(ctx.IsAcceptLanguageZH())
+		return err
 	}
 
 	return WriteResponse(ctx.Response, http.StatusOK, result)
 }
 
+func isNilResource(r resource.Resource) bool {
+	return r == nil || (reflect.ValueOf(r).Kind() == reflect.Ptr && reflect.ValueOf(r).IsNil())
+}
+
 const ContentTypeKey = "Content-Type"
 
+// WriteResponse write result as json, writeOnly fields of resource and
+// resources in collection are omitted
 func WriteResponse(resp http.ResponseWriter, status int, result interface{}) *goresterr.APIError {
 	resp.Header().Set(ContentTypeKey, "application/json")
 	resp.WriteHeader(status)
@@ -172,11 +289,130 @@ func WriteResponse(resp http.ResponseWriter, status int, result interface{}) *go
 		return nil
 	}
 
+	result, err := omitWriteOnlyFields(result)
+	if err != nil {
+		return goresterr.NewAPIError(goresterr.ServerError,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageMarshalFailed, goresterr.Params{"reason": err.Error()}))
+	}
+
 	body, err := json.Marshal(result)
 	if err != nil {
 		return goresterr.NewAPIError(goresterr.ServerError,
-			goresterr.ErrorMessage{MessageEN: fmt.Sprintf("marshal failed:%s", err.Error())})
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageMarshalFailed, goresterr.Params{"reason": err.Error()}))
 	}
 	resp.Write(body)
 	return nil
 }
+
+// WriteResponseWithFields same with WriteResponse, but only the fields are
+// kept in the resource, for resource collection, the fields are applied to
+// each resource in it
+func WriteResponseWithFields(resp http.ResponseWriter, status int, result interface{}, fields []string) *goresterr.APIError {
+	if len(fields) == 0 || result == nil {
+		return WriteResponse(resp, status, result)
+	}
+
+	var sparse interface{}
+	var err error
+	if rc, ok := result.(*resource.ResourceCollection); ok {
+		sparse, err = selectCollectionFields(rc, fields)
+	} else {
+		sparse, err = selectFields(result, fields)
+	}
+	if err != nil {
+		return goresterr.NewAPIError(goresterr.ServerError,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageMarshalFailed, goresterr.Params{"reason": err.Error()}))
+	}
+
+	return WriteResponse(resp, status, sparse)
+}
+
+func selectCollectionFields(rc *resource.ResourceCollection, fields []string) (map[string]json.RawMessage, error) {
+	collection, err := toRawMessageMap(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]map[string]json.RawMessage, 0, len(rc.Resources))
+	for _, r := range rc.Resources {
+		sparse, err := selectFields(r, fields)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, sparse)
+	}
+
+	data, err := json.Marshal(resources)
+	if err != nil {
+		return nil, err
+	}
+	collection["data"] = data
+	return collection, nil
+}
+
+// nil fields means all the fields
+func selectFields(r interface{}, fields []string) (map[string]json.RawMessage, error) {
+	all, err := resourceRawMessageMap(r)
+	if err != nil || fields == nil {
+		return all, err
+	}
+
+	sparse := make(map[string]json.RawMessage, len(fields))
+	for _, field := range fields {
+		if value, ok := all[field]; ok {
+			sparse[field] = value
+		}
+	}
+	return sparse, nil
+}
+
+func toRawMessageMap(v interface{}) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]json.RawMessage
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+func omitWriteOnlyFields(result interface{}) (interface{}, error) {
+	switch r := result.(type) {
+	case *resource.ResourceCollection:
+		for _, elem := range r.Resources {
+			if len(writeOnlyFields(elem)) > 0 {
+				return selectCollectionFields(r, nil)
+			}
+		}
+	case resource.Resource:
+		if len(writeOnlyFields(r)) > 0 {
+			return resourceRawMessageMap(r)
+		}
+	}
+	return result, nil
+}
+
+func resourceRawMessageMap(r interface{}) (map[string]json.RawMessage, error) {
+	m, err := toRawMessageMap(r)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range writeOnlyFields(r) {
+		delete(m, field)
+	}
+	return m, nil
+}
+
+func writeOnlyFields(r interface{}) []string {
+	if v := reflect.ValueOf(r); r == nil || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return nil
+	}
+
+	access, err := resourcefield.AccessOf(reflect.TypeOf(r))
+	if err != nil {
+		return nil
+	}
+	return access.WriteOnlyFields()
+}
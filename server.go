@@ -13,18 +13,48 @@ type HandlersChain []HandlerFunc
 type EndHandlerFunc func(*resource.Context, *goresterr.APIError) *goresterr.APIError
 type EndHandlersChain []EndHandlerFunc
 
+const DefaultMaxBodySize = 10 << 20
+
 type Server struct {
-	Schemas     resource.SchemaManager
-	handlers    HandlersChain
-	endHandlers EndHandlersChain
+	Schemas       resource.SchemaManager
+	handlers      HandlersChain
+	endHandlers   EndHandlersChain
+	events        EventSource
+	bodyOptions   resource.BodyOptions
+	panicReporter PanicReporter
 }
 
 func NewAPIServer(schemas resource.SchemaManager) *Server {
 	return &Server{
-		Schemas: schemas,
+		Schemas:       schemas,
+		events:        NewMemoryEventSource(),
+		bodyOptions:   resource.BodyOptions{MaxSize: DefaultMaxBodySize},
+		panicReporter: defaultPanicReporter,
 	}
 }
 
+// request body larger than MaxSize is rejected, and in strict mode unknown
+// fields and fields with mismatched type in request body are rejected
+func (s *Server) SetBodyOptions(opts resource.BodyOptions) {
+	s.bodyOptions = opts
+}
+
+// events of create/update/patch/delete are published to the event
+// source, and watch requests get events from it, set nil to disable watch
+func (s *Server) SetEventSource(events EventSource) {
+	s.events = events
+}
+
+func (s *Server) GetEventSource() EventSource {
+	return s.events
+}
+
+// panics in handlers are recovered and reported to the reporter, the
+// default one prints the stack to stderr, set nil to disable report
+func (s *Server) SetPanicReporter(reporter PanicReporter) {
+	s.panicReporter = reporter
+}
+
 func (s *Server) Use(h HandlerFunc) {
 	s.handlers = append(s.handlers, h)
 }
@@ -34,27 +64,74 @@ func (s *Server) EndUse(h EndHandlerFunc) {
 }
 
 func (s *Server) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	ctx, err := resource.NewContext(rw, req, s.Schemas)
+	req = resource.WithBodyOptions(req, s.bodyOptions)
+	ctx, err, finished, aborted := s.serve(wrapResponseWriter(rw), req)
+	if finished == false {
+		s.runEndHandlers(req, ctx, err)
+	}
+
+	if aborted {
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// panic in end handlers is reported, the remaining end handlers are skipped
+func (s *Server) runEndHandlers(req *http.Request, ctx *resource.Context, err *goresterr.APIError) {
+	defer func() {
+		if recovered := recover(); recovered == http.ErrAbortHandler {
+			panic(recovered)
+		} else if recovered != nil {
+			s.reportPanic(getRequestID(req), req, ctx, recovered)
+		}
+	}()
+
+	for _, h := range s.endHandlers {
+		if err := h(ctx, err); err != nil {
+			return
+		}
+	}
+}
+
+// finished is true if the request fails before the rest handler is called,
+// end handlers are skipped in this case, if panic happens after the context
+// is created, end handlers still get the server error, aborted is true if
+// the panic happens after the response is started
+func (s *Server) serve(rw http.ResponseWriter, req *http.Request) (ctx *resource.Context, err *goresterr.APIError, finished bool, aborted bool) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err, aborted = s.recoverPanic(rw, req, ctx, recovered)
+			finished = ctx == nil
+		}
+	}()
+
+	//discovery is disabled by default, middlewares aren't called for it
+	if doc, err := s.Schemas.Discover(req); err != nil {
+		err = err.Localization(resource.RequestAcceptLanguage(req))
+		WriteResponse(rw, err.Status, err)
+		return nil, err, true, false
+	} else if doc != nil {
+		WriteResponse(rw, http.StatusOK, doc)
+		return nil, nil, true, false
+	}
+
+	ctx, err = resource.NewContext(rw, req, s.Schemas)
 	if err != nil {
 		WriteResponse(rw, err.Status, err)
-		return
+		return nil, err, true, false
 	}
 
 	for _, h := range s.handlers {
 		if err := h(ctx); err != nil {
+			err = err.Localization(ctx.AcceptLanguage())
 			WriteResponse(rw, err.Status, err)
-			return
+			return ctx, err, true, false
 		}
 	}
 
-	err = restHandler(ctx)
+	err = restHandler(ctx, s.events)
 	if err != nil {
+		err = err.Localization(ctx.AcceptLanguage())
 		WriteResponse(rw, err.Status, err)
 	}
-
-	for _, h := range s.endHandlers {
-		if err := h(ctx, err); err != nil {
-			return
-		}
-	}
+	return ctx, err, false, false
 }
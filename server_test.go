@@ -1,9 +1,15 @@
 package gorest
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	ut "github.com/linkingthing/cement/unittest"
 	goresterr "github.com/linkingthing/gorest/error"
@@ -83,3 +89,616 @@ func TestContextPassChain(t *testing.T) {
 	s.ServeHTTP(w, req)
 	ut.Equal(t, w.Code, http.StatusNoContent)
 }
+
+type Baz struct {
+	resource.ResourceBase `json:",inline"`
+	Name                  string `json:"name" rest:"required=true,minLen=2,maxLen=10"`
+	Size                  int    `json:"size" rest:"min=1,max=100"`
+}
+
+type bazHandler struct {
+	baz *Baz
+}
+
+func (h *bazHandler) Get(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	if ctx.Resource.GetID() != h.baz.GetID() {
+		return nil, nil
+	}
+	return h.baz, nil
+}
+
+func (h *bazHandler) List(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+	return []*Baz{h.baz}, nil
+}
+
+func (h *bazHandler) Patch(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	h.baz = ctx.Resource.(*Baz)
+	return h.baz, nil
+}
+
+func (h *bazHandler) Update(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	baz := ctx.Resource.(*Baz)
+	if version := baz.GetResourceVersion(); version != 0 && version != h.baz.GetResourceVersion() {
+		return nil, goresterr.NewAPIError(goresterr.Conflict, goresterr.ErrorMessage{MessageEN: "stale version"})
+	}
+	baz.SetResourceVersion(h.baz.GetResourceVersion() + 1)
+	h.baz = baz
+	return baz, nil
+}
+
+func TestPatch(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	baz := &Baz{Name: "baz", Size: 10}
+	baz.SetID("b1")
+	handler := &bazHandler{baz: baz}
+	schemas.MustImport(&version, Baz{}, handler)
+	s := NewAPIServer(schemas)
+
+	req, _ := http.NewRequest(http.MethodPatch, "/apis/testing/v1/bazs/b1", bytes.NewBufferString(`{"size":20}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	var result Baz
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &result) == nil, "")
+	ut.Equal(t, result.Name, "baz")
+	ut.Equal(t, result.Size, 20)
+	ut.Equal(t, handler.baz.Size, 20)
+
+	req, _ = http.NewRequest(http.MethodPatch, "/apis/testing/v1/bazs/b1", bytes.NewBufferString(`[{"op":"replace","path":"/name","value":"b"}]`))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.InvalidBodyContent.Status)
+	ut.Equal(t, handler.baz.Name, "baz")
+
+	req, _ = http.NewRequest(http.MethodPatch, "/apis/testing/v1/bazs/b2", bytes.NewBufferString(`{"size":20}`))
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusNotFound)
+}
+
+func TestSparseFields(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	baz := &Baz{Name: "baz", Size: 10}
+	baz.SetID("b1")
+	schemas.MustImport(&version, Baz{}, &bazHandler{baz: baz})
+	s := NewAPIServer(schemas)
+
+	req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v1/bazs/b1?fields=name,links", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	var result map[string]interface{}
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &result) == nil, "")
+	ut.Equal(t, len(result), 2)
+	ut.Equal(t, result["name"], "baz")
+	ut.Assert(t, result["links"] != nil, "")
+
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/bazs?fields=id,size", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	var collection struct {
+		Type  string                   `json:"type"`
+		Links map[string]string        `json:"links"`
+		Data  []map[string]interface{} `json:"data"`
+	}
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &collection) == nil, "")
+	ut.Equal(t, collection.Type, "collection")
+	ut.Assert(t, collection.Links["self"] != "", "")
+	ut.Equal(t, collection.Data, []map[string]interface{}{{"id": "b1", "size": float64(10)}})
+
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/bazs?fields=name,color", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.InvalidFormat.Status)
+}
+
+func TestResourceVersion(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	baz := &Baz{Name: "baz", Size: 10}
+	baz.SetID("b1")
+	baz.SetResourceVersion(1)
+	handler := &bazHandler{baz: baz}
+	schemas.MustImport(&version, Baz{}, handler)
+	s := NewAPIServer(schemas)
+
+	req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v1/bazs/b1", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, w.Header().Get(ETagKey), `"1"`)
+
+	req.Header.Set(IfNoneMatchKey, `W/"1"`)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusNotModified)
+	ut.Equal(t, w.Body.Len(), 0)
+
+	req, _ = http.NewRequest(http.MethodPut, "/apis/testing/v1/bazs/b1", bytes.NewBufferString(`{"name":"baz","size":20}`))
+	req.Header.Set(IfMatchKey, `"1"`)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, w.Header().Get(ETagKey), `"2"`)
+	ut.Equal(t, handler.baz.Size, 20)
+
+	//stale version in If-Match header or body
+	req, _ = http.NewRequest(http.MethodPut, "/apis/testing/v1/bazs/b1", bytes.NewBufferString(`{"name":"baz","size":30}`))
+	req.Header.Set(IfMatchKey, `"1"`)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.Conflict.Status)
+
+	req, _ = http.NewRequest(http.MethodPut, "/apis/testing/v1/bazs/b1", bytes.NewBufferString(`{"name":"baz","size":30,"resourceVersion":1}`))
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.Conflict.Status)
+	ut.Equal(t, handler.baz.Size, 20)
+
+	req, _ = http.NewRequest(http.MethodPatch, "/apis/testing/v1/bazs/b1", bytes.NewBufferString(`{"size":40}`))
+	req.Header.Set(IfMatchKey, `"1", "3"`)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.Conflict.Status)
+
+	req.Header.Set(IfMatchKey, "*")
+	req.Body = io.NopCloser(bytes.NewBufferString(`{"size":40}`))
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, handler.baz.Size, 40)
+	ut.Equal(t, w.Header().Get(ETagKey), `"2"`)
+}
+
+func TestBodyOptions(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	baz := &Baz{Name: "baz", Size: 10}
+	baz.SetID("b1")
+	handler := &bazHandler{baz: baz}
+	schemas.MustImport(&version, Baz{}, handler)
+	s := NewAPIServer(schemas)
+
+	put := func(body, contentType string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPut, "/apis/testing/v1/bazs/b1", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Accept-Language", "zh-CN")
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		return w
+	}
+
+	ut.Equal(t, put(`{"name":"baz","size":20}`, "text/plain").Code, goresterr.UnsupportedMediaType.Status)
+	ut.Equal(t, put(`{"name":"baz","size":20,"color":"red"}`, "application/json").Code, http.StatusOK)
+
+	s.SetBodyOptions(resource.BodyOptions{MaxSize: 40, Strict: true})
+	w := put(`{"name":"baz","size":20,"color":"red"}`, "application/json")
+	ut.Equal(t, w.Code, goresterr.InvalidBodyContent.Status)
+	var apiErr goresterr.APIError
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &apiErr) == nil, "")
+	ut.Equal(t, apiErr.Message, "请求体包含未知字段[color]")
+
+	ut.Equal(t, put(`{"name":"baz","size":"20"}`, "application/json").Code, goresterr.InvalidBodyContent.Status)
+	ut.Equal(t, put(`{"name":"baz","size":20,"links":{"self":"/bazs/b1"}}`, "application/json").Code, goresterr.RequestEntityTooLarge.Status)
+	ut.Equal(t, put(`{"name":"baz","size":30}`, "application/json").Code, http.StatusOK)
+	ut.Equal(t, handler.baz.Size, 30)
+}
+
+func TestFieldErrorDetails(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	baz := &Baz{Name: "baz", Size: 10}
+	baz.SetID("b1")
+	schemas.MustImport(&version, Baz{}, &bazHandler{baz: baz})
+	s := NewAPIServer(schemas)
+
+	req, _ := http.NewRequest(http.MethodPut, "/apis/testing/v1/bazs/b1", bytes.NewBufferString(`{"name":"b","size":100}`))
+	req.Header.Set("Accept-Language", "zh-CN")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.InvalidBodyContent.Status)
+
+	var apiErr struct {
+		Code    string                   `json:"code"`
+		Message string                   `json:"message"`
+		Details []map[string]interface{} `json:"details"`
+	}
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &apiErr) == nil, "")
+	ut.Equal(t, apiErr.Code, goresterr.InvalidBodyContent.Code)
+	ut.Equal(t, apiErr.Message, "字段[name]的长度不能小于2; 字段[size]的值必须小于100")
+	ut.Equal(t, apiErr.Details, []map[string]interface{}{
+		{
+			"code":      "MinLengthExceeded",
+			"field":     "name",
+			"rule":      "minLen",
+			"limit":     float64(2),
+			"message":   "字段[name]的长度不能小于2",
+			"messageEN": "field name: exceed the range limit, (string len 1 should >= 2)",
+			"messageCN": "字段[name]的长度不能小于2",
+		},
+		{
+			"code":      "MaxLimitExceeded",
+			"field":     "size",
+			"rule":      "max",
+			"limit":     float64(100),
+			"message":   "字段[size]的值必须小于100",
+			"messageEN": "field size: exceed the range limit, (100 should < 100)",
+			"messageCN": "字段[size]的值必须小于100",
+		},
+	})
+	ut.Equal(t, baz.Name, "baz")
+}
+
+func TestAcceptLanguage(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	baz := &Baz{Name: "baz", Size: 10}
+	baz.SetID("b1")
+	schemas.MustImport(&version, Baz{}, &bazHandler{baz: baz})
+	s := NewAPIServer(schemas)
+
+	put := func(lang string) *goresterr.APIError {
+		req, _ := http.NewRequest(http.MethodPut, "/apis/testing/v1/bazs/b1", bytes.NewBufferString(`{"name":"b","size":20}`))
+		req.Header.Set("Accept-Language", lang)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		ut.Equal(t, w.Code, goresterr.MinLengthExceeded.Status)
+		var apiErr goresterr.APIError
+		ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &apiErr) == nil, "")
+		return &apiErr
+	}
+
+	cases := []struct {
+		lang    string
+		message string
+	}{
+		{"", "field name: exceed the range limit, (string len 1 should >= 2)"},
+		{"ja;q=0.5, ru;q=0.9", "длина поля [name] должна быть не меньше 2"},
+		{"fr, ja", "フィールド[name]の長さは2以上でなければなりません"},
+		{"fr, zh-CN;q=0.8, *;q=0.9", "field name: exceed the range limit, (string len 1 should >= 2)"},
+	}
+	for _, c := range cases {
+		apiErr := put(c.lang)
+		ut.Equal(t, apiErr.Message, c.message)
+		ut.Equal(t, apiErr.Details[0].Message, c.message)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, "/apis/testing/v1/bazs/b1", nil)
+	req.Header.Set("Accept-Language", "ru")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.NotFound.Status)
+	var apiErr goresterr.APIError
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &apiErr) == nil, "")
+	ut.Equal(t, apiErr.Message, "операция delete не поддерживается")
+}
+
+func TestNoGetHandler(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Foo{}, &dumbHandler{})
+	s := NewAPIServer(schemas)
+
+	req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v1/foos/f1", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.NotFound.Status)
+	var apiErr goresterr.APIError
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &apiErr) == nil, "")
+	ut.Equal(t, apiErr.Message, "no handler for get")
+}
+
+// handlers adapted by SchemaManager can't patch without get, but other
+// implementations of resource.Handler could
+type patchOnlyHandler struct {
+	resource.DefaultHandler
+}
+
+func (h *patchOnlyHandler) GetPatchHandler() resource.PatchHandler {
+	return func(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+		return ctx.Resource, nil
+	}
+}
+
+func TestPatchWithoutGetHandler(t *testing.T) {
+	s, err := schema.NewSchema(&version, Baz{}, &patchOnlyHandler{})
+	ut.Assert(t, err == nil, "")
+	baz := &Baz{}
+	baz.SetID("b1")
+	baz.SetSchema(s)
+
+	req, _ := http.NewRequest(http.MethodPatch, "/apis/testing/v1/bazs/b1", bytes.NewBufferString(`{"size":20}`))
+	apiErr := handlePatch(&resource.Context{Request: req, Response: httptest.NewRecorder(), Resource: baz}, nil)
+	ut.Assert(t, apiErr != nil, "")
+	ut.Equal(t, apiErr.Status, goresterr.NotFound.Status)
+	ut.Equal(t, apiErr.Message, "no handler for get")
+}
+
+func TestPanicRecovery(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	//get panics since there is no baz
+	schemas.MustImport(&version, Baz{}, &bazHandler{})
+	s := NewAPIServer(schemas)
+
+	var report *PanicReport
+	s.SetPanicReporter(func(r *PanicReport) {
+		report = r
+	})
+	var endErr *goresterr.APIError
+	s.EndUse(func(ctx *resource.Context, err *goresterr.APIError) *goresterr.APIError {
+		endErr = err
+		return nil
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v1/bazs/b1", nil)
+	req.Header.Set(RequestIDKey, "r1")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.ServerError.Status)
+	ut.Equal(t, w.Header().Get(RequestIDKey), "r1")
+	var apiErr goresterr.APIError
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &apiErr) == nil, "")
+	ut.Equal(t, apiErr.Code, goresterr.ServerError.Code)
+	ut.Equal(t, apiErr.RequestID, "r1")
+
+	ut.Assert(t, report != nil, "")
+	ut.Equal(t, report.RequestID, "r1")
+	ut.Assert(t, report.Context != nil, "")
+	ut.Assert(t, len(report.Stack) > 0, "")
+	ut.Assert(t, endErr != nil, "")
+	ut.Equal(t, endErr.RequestID, "r1")
+
+	//panic in middleware before rest handler
+	s.Use(func(ctx *resource.Context) *goresterr.APIError {
+		panic("middleware panic")
+	})
+	endErr = nil
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/bazs", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.ServerError.Status)
+	ut.Assert(t, w.Header().Get(RequestIDKey) != "", "")
+	ut.Equal(t, report.Recovered, "middleware panic")
+	ut.Assert(t, endErr != nil, "")
+}
+
+func TestPanicAfterResponseStarted(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Baz{}, &bazHandler{})
+	s := NewAPIServer(schemas)
+
+	var report *PanicReport
+	s.SetPanicReporter(func(r *PanicReport) {
+		report = r
+	})
+	s.Use(func(ctx *resource.Context) *goresterr.APIError {
+		ctx.Response.WriteHeader(http.StatusOK)
+		ctx.Response.Write([]byte("{}\n"))
+		panic("stream panic")
+	})
+	//end handlers still get the failure before the connection is aborted
+	var endErr *goresterr.APIError
+	s.EndUse(func(ctx *resource.Context, err *goresterr.APIError) *goresterr.APIError {
+		endErr = err
+		return nil
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v1/bazs?watch=true", nil)
+	w := httptest.NewRecorder()
+	func() {
+		defer func() {
+			ut.Equal(t, recover(), http.ErrAbortHandler)
+		}()
+		s.ServeHTTP(w, req)
+	}()
+
+	//error response isn't appended to the started response
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, w.Body.String(), "{}\n")
+	ut.Equal(t, report.Recovered, "stream panic")
+	ut.Assert(t, endErr != nil, "")
+	ut.Equal(t, endErr.RequestID, report.RequestID)
+}
+
+func TestPanicInEndHandler(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Qux{}, &quxHandler{quxs: map[string]*Qux{}})
+	s := NewAPIServer(schemas)
+
+	var report *PanicReport
+	s.SetPanicReporter(func(r *PanicReport) {
+		report = r
+	})
+	s.EndUse(func(ctx *resource.Context, err *goresterr.APIError) *goresterr.APIError {
+		panic("audit panic")
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v1/quxes", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, report.Recovered, "audit panic")
+}
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (w *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return nil, nil, nil
+}
+
+func TestResponseWriterHijack(t *testing.T) {
+	recorder := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	rw := wrapResponseWriter(recorder)
+	_, ok := rw.(http.Flusher)
+	ut.Assert(t, ok, "flusher should be kept")
+	hijacker, ok := rw.(http.Hijacker)
+	ut.Assert(t, ok, "hijacker should be kept")
+	hijacker.Hijack()
+	ut.Assert(t, recorder.hijacked, "")
+	ut.Assert(t, responseStarted(rw), "")
+
+	_, ok = wrapResponseWriter(httptest.NewRecorder()).(http.Hijacker)
+	ut.Assert(t, ok == false, "")
+}
+
+func TestDiscovery(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Baz{}, &bazHandler{})
+	schemas.SetDiscovery(true)
+	s := NewAPIServer(schemas)
+	called := false
+	s.Use(func(ctx *resource.Context) *goresterr.APIError {
+		called = true
+		return nil
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/apis", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	var groups schema.APIGroupList
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &groups) == nil, "")
+	ut.Equal(t, len(groups.Groups), 1)
+	ut.Equal(t, groups.Groups[0].Name, "testing")
+	ut.Assert(t, called == false, "")
+
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/schemas/baz", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	var doc map[string]interface{}
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &doc) == nil, "")
+	ut.Equal(t, doc["collectionName"], "bazs")
+
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/schemas/qux", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusNotFound)
+
+	schemas.SetDiscovery(false)
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.InvalidFormat.Status)
+}
+
+type Account struct {
+	resource.ResourceBase `json:",inline"`
+	Name                  string `json:"name" rest:"required=true,immutable"`
+	Password              string `json:"password" rest:"writeOnly"`
+	LoginCount            int    `json:"loginCount" rest:"readOnly"`
+	Role                  string `json:"role"`
+}
+
+type accountHandler struct {
+	account *Account
+}
+
+func (h *accountHandler) Create(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	h.account = ctx.Resource.(*Account)
+	h.account.SetID(h.account.Name)
+	return h.account, nil
+}
+
+func (h *accountHandler) Get(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	if h.account == nil || ctx.Resource.GetID() != h.account.GetID() {
+		return nil, nil
+	}
+	return h.account, nil
+}
+
+func (h *accountHandler) List(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+	return []*Account{h.account}, nil
+}
+
+func (h *accountHandler) Update(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	h.account = ctx.Resource.(*Account)
+	return h.account, nil
+}
+
+func (h *accountHandler) Patch(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	h.account = ctx.Resource.(*Account)
+	return h.account, nil
+}
+
+func TestFieldAccess(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	handler := &accountHandler{}
+	schemas.MustImport(&version, Account{}, handler)
+	s := NewAPIServer(schemas)
+
+	serve := func(method, url, body string) (int, map[string]interface{}) {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		var result map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &result)
+		return w.Code, result
+	}
+
+	//readOnly field in body is ignored, writeOnly field isn't returned
+	code, result := serve(http.MethodPost, "/apis/testing/v1/accounts",
+		`{"name":"alice","password":"secret","loginCount":10,"creationTimestamp":"2000-01-01T00:00:00Z"}`)
+	ut.Equal(t, code, http.StatusCreated)
+	ut.Equal(t, handler.account.Password, "secret")
+	ut.Equal(t, handler.account.LoginCount, 0)
+	ut.Assert(t, handler.account.GetCreationTimestamp().IsZero(), "")
+	_, ok := result["password"]
+	ut.Assert(t, ok == false, "password shouldn't be returned")
+	ut.Equal(t, result["name"], "alice")
+
+	handler.account.LoginCount = 3
+	code, result = serve(http.MethodGet, "/apis/testing/v1/accounts/alice", "")
+	ut.Equal(t, code, http.StatusOK)
+	_, ok = result["password"]
+	ut.Assert(t, ok == false, "password shouldn't be returned")
+	ut.Equal(t, result["loginCount"], float64(3))
+
+	req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v1/accounts", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	var collection struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &collection) == nil, "")
+	ut.Equal(t, len(collection.Data), 1)
+	_, ok = collection.Data[0]["password"]
+	ut.Assert(t, ok == false, "password shouldn't be returned")
+
+	//immutable field can't be changed, omitted one keeps the stored value
+	code, result = serve(http.MethodPut, "/apis/testing/v1/accounts/alice", `{"name":"bob","role":"admin"}`)
+	ut.Equal(t, code, goresterr.InvalidBodyContent.Status)
+	details := result["details"].([]interface{})
+	ut.Equal(t, details[0].(map[string]interface{})["field"], "name")
+	ut.Equal(t, details[0].(map[string]interface{})["rule"], "immutable")
+	ut.Equal(t, handler.account.Role, "")
+
+	//readOnly fields keep the stored value on update
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	handler.account.SetCreationTimestamp(created)
+	code, _ = serve(http.MethodPut, "/apis/testing/v1/accounts/alice",
+		`{"name":"alice","role":"admin","loginCount":10,"creationTimestamp":"2000-01-01T00:00:00Z"}`)
+	ut.Equal(t, code, http.StatusOK)
+	ut.Equal(t, handler.account.Role, "admin")
+	ut.Equal(t, handler.account.LoginCount, 3)
+	ut.Assert(t, handler.account.GetCreationTimestamp().Equal(created), "")
+
+	code, _ = serve(http.MethodPatch, "/apis/testing/v1/accounts/alice", `{"name":"bob"}`)
+	ut.Equal(t, code, goresterr.InvalidBodyContent.Status)
+	ut.Equal(t, handler.account.Name, "alice")
+
+	code, _ = serve(http.MethodPatch, "/apis/testing/v1/accounts/alice", `{"role":"guest","loginCount":100}`)
+	ut.Equal(t, code, http.StatusOK)
+	ut.Equal(t, handler.account.Role, "guest")
+	ut.Equal(t, handler.account.LoginCount, 3)
+
+	//readOnly field is rejected in strict mode
+	s.SetBodyOptions(resource.BodyOptions{Strict: true})
+	code, result = serve(http.MethodPost, "/apis/testing/v1/accounts", `{"name":"carol","loginCount":10}`)
+	ut.Equal(t, code, goresterr.InvalidBodyContent.Status)
+	details = result["details"].([]interface{})
+	ut.Equal(t, details[0].(map[string]interface{})["field"], "loginCount")
+	ut.Equal(t, details[0].(map[string]interface{})["rule"], "readOnly")
+}
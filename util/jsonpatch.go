@@ -0,0 +1,301 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	JSONPatchOpAdd     = "add"
+	JSONPatchOpRemove  = "remove"
+	JSONPatchOpReplace = "replace"
+	JSONPatchOpMove    = "move"
+	JSONPatchOpCopy    = "copy"
+	JSONPatchOpTest    = "test"
+)
+
+// rfc 6902 operation, value is kept raw to distinguish null from absent
+type JSONPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// rfc 7386, target and patch are values decoded by encoding/json
+func MergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+
+	for k, v := range patchObj {
+		if v == nil {
+			delete(targetObj, k)
+		} else {
+			targetObj[k] = MergePatch(targetObj[k], v)
+		}
+	}
+	return targetObj
+}
+
+func ParseJSONPatch(data []byte) ([]JSONPatchOperation, error) {
+	var ops []JSONPatchOperation
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil, fmt.Errorf("json patch isn't an operation array:%s", err.Error())
+	}
+
+	for _, op := range ops {
+		if _, err := JSONPointerTokens(op.Path); err != nil {
+			return nil, err
+		}
+
+		switch op.Op {
+		case JSONPatchOpAdd, JSONPatchOpReplace, JSONPatchOpTest:
+			if op.Value == nil {
+				return nil, fmt.Errorf("json patch %s %s has no value", op.Op, op.Path)
+			}
+		case JSONPatchOpMove, JSONPatchOpCopy:
+			if _, err := JSONPointerTokens(op.From); err != nil {
+				return nil, err
+			}
+		case JSONPatchOpRemove:
+		default:
+			return nil, fmt.Errorf("unknown json patch operation %s", op.Op)
+		}
+	}
+	return ops, nil
+}
+
+func ApplyJSONPatch(doc interface{}, ops []JSONPatchOperation) (interface{}, error) {
+	for _, op := range ops {
+		path, err := JSONPointerTokens(op.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		switch op.Op {
+		case JSONPatchOpAdd:
+			var value interface{}
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return nil, fmt.Errorf("json patch value of %s is invalid:%s", op.Path, err.Error())
+			}
+			doc, err = jsonPatchAdd(doc, path, value)
+		case JSONPatchOpRemove:
+			doc, _, err = jsonPatchRemove(doc, path)
+		case JSONPatchOpReplace:
+			var value interface{}
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return nil, fmt.Errorf("json patch value of %s is invalid:%s", op.Path, err.Error())
+			}
+			if doc, _, err = jsonPatchRemove(doc, path); err == nil {
+				doc, err = jsonPatchAdd(doc, path, value)
+			}
+		case JSONPatchOpMove:
+			var from []string
+			var value interface{}
+			if from, err = JSONPointerTokens(op.From); err != nil {
+				return nil, err
+			}
+			if doc, value, err = jsonPatchRemove(doc, from); err == nil {
+				doc, err = jsonPatchAdd(doc, path, value)
+			}
+		case JSONPatchOpCopy:
+			var from []string
+			var value interface{}
+			if from, err = JSONPointerTokens(op.From); err != nil {
+				return nil, err
+			}
+			if value, err = jsonPatchGet(doc, from); err == nil {
+				if value, err = deepCopyJSONValue(value); err == nil {
+					doc, err = jsonPatchAdd(doc, path, value)
+				}
+			}
+		case JSONPatchOpTest:
+			var expect, value interface{}
+			if err := json.Unmarshal(op.Value, &expect); err != nil {
+				return nil, fmt.Errorf("json patch value of %s is invalid:%s", op.Path, err.Error())
+			}
+			if value, err = jsonPatchGet(doc, path); err == nil && !reflect.DeepEqual(value, expect) {
+				err = fmt.Errorf("json patch test on %s failed", op.Path)
+			}
+		default:
+			err = fmt.Errorf("unknown json patch operation %s", op.Op)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// rfc 6901, "" refers to the whole document
+func JSONPointerTokens(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("json pointer %s doesn't start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func jsonPatchGet(doc interface{}, tokens []string) (interface{}, error) {
+	for _, token := range tokens {
+		switch container := doc.(type) {
+		case map[string]interface{}:
+			child, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("json pointer segment %s doesn't exist", token)
+			}
+			doc = child
+		case []interface{}:
+			i, err := jsonPatchArrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = container[i]
+		default:
+			return nil, fmt.Errorf("json pointer segment %s refers to a scalar value", token)
+		}
+	}
+	return doc, nil
+}
+
+func jsonPatchAdd(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	token := tokens[0]
+	switch container := doc.(type) {
+	case map[string]interface{}:
+		if len(tokens) == 1 {
+			container[token] = value
+			return container, nil
+		}
+
+		child, ok := container[token]
+		if !ok {
+			return nil, fmt.Errorf("json pointer segment %s doesn't exist", token)
+		}
+		child, err := jsonPatchAdd(child, tokens[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		container[token] = child
+		return container, nil
+	case []interface{}:
+		if len(tokens) == 1 {
+			i := len(container)
+			if token != "-" {
+				var err error
+				if i, err = jsonPatchArrayIndex(token, len(container)); err != nil {
+					return nil, err
+				}
+			}
+			container = append(container, nil)
+			copy(container[i+1:], container[i:])
+			container[i] = value
+			return container, nil
+		}
+
+		i, err := jsonPatchArrayIndex(token, len(container)-1)
+		if err != nil {
+			return nil, err
+		}
+		child, err := jsonPatchAdd(container[i], tokens[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		container[i] = child
+		return container, nil
+	default:
+		return nil, fmt.Errorf("json pointer segment %s refers to a scalar value", token)
+	}
+}
+
+// return the new document and the removed value
+func jsonPatchRemove(doc interface{}, tokens []string) (interface{}, interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, nil, fmt.Errorf("json patch couldn't remove the whole document")
+	}
+
+	token := tokens[0]
+	switch container := doc.(type) {
+	case map[string]interface{}:
+		child, ok := container[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("json pointer segment %s doesn't exist", token)
+		}
+
+		if len(tokens) == 1 {
+			delete(container, token)
+			return container, child, nil
+		}
+
+		child, removed, err := jsonPatchRemove(child, tokens[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		container[token] = child
+		return container, removed, nil
+	case []interface{}:
+		i, err := jsonPatchArrayIndex(token, len(container)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(tokens) == 1 {
+			removed := container[i]
+			return append(container[:i], container[i+1:]...), removed, nil
+		}
+
+		child, removed, err := jsonPatchRemove(container[i], tokens[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		container[i] = child
+		return container, removed, nil
+	default:
+		return nil, nil, fmt.Errorf("json pointer segment %s refers to a scalar value", token)
+	}
+}
+
+func jsonPatchArrayIndex(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("json pointer segment %s isn't a valid array index", token)
+	}
+
+	if i > max {
+		return 0, fmt.Errorf("json pointer array index %s is out of range", token)
+	}
+	return i, nil
+}
+
+func deepCopyJSONValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var cp interface{}
+	err = json.Unmarshal(data, &cp)
+	return cp, err
+}
@@ -0,0 +1,96 @@
+package util
+
+import (
+	"encoding/json"
+	"testing"
+
+	ut "github.com/linkingthing/cement/unittest"
+)
+
+func decodeJSON(t *testing.T, data string) interface{} {
+	var v interface{}
+	err := json.Unmarshal([]byte(data), &v)
+	ut.Assert(t, err == nil, "invalid json %s", data)
+	return v
+}
+
+func TestMergePatch(t *testing.T) {
+	cases := []struct {
+		target string
+		patch  string
+		result string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tc := range cases {
+		result := MergePatch(decodeJSON(t, tc.target), decodeJSON(t, tc.patch))
+		ut.Equal(t, result, decodeJSON(t, tc.result))
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	cases := []struct {
+		doc     string
+		patch   string
+		result  string
+		isValid bool
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"foo":"bar","baz":"qux"}`, true},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`, true},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":"qux"}]`, `{"foo":["bar","qux"]}`, true},
+		{`{"foo":"bar","baz":"qux"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`, true},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`, true},
+		{`{"foo":"bar","baz":"qux"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"foo":"bar","baz":"boo"}`, true},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`, true},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`, true},
+		{`{"foo":{"bar":1}}`, `[{"op":"copy","from":"/foo","path":"/baz"}]`, `{"foo":{"bar":1},"baz":{"bar":1}}`, true},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			`{"baz":"qux","foo":["a",2,"c"]}`, true},
+		{`{"a/b":0,"m~n":8}`, `[{"op":"replace","path":"/a~1b","value":1},{"op":"remove","path":"/m~0n"}]`, `{"a/b":1}`, true},
+		{`{"foo":null}`, `[{"op":"replace","path":"/foo","value":null}]`, `{"foo":null}`, true},
+		{`{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, "", false},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, "", false},
+		{`{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, "", false},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/2","value":"qux"}]`, "", false},
+		{`{"foo":["bar"]}`, `[{"op":"replace","path":"/foo/01","value":"qux"}]`, "", false},
+	}
+
+	for _, tc := range cases {
+		ops, err := ParseJSONPatch([]byte(tc.patch))
+		ut.Assert(t, err == nil, "parse patch %s failed %v", tc.patch, err)
+		result, err := ApplyJSONPatch(decodeJSON(t, tc.doc), ops)
+		if tc.isValid {
+			ut.Assert(t, err == nil, "apply patch %s failed %v", tc.patch, err)
+			ut.Equal(t, result, decodeJSON(t, tc.result))
+		} else {
+			ut.Assert(t, err != nil, "apply patch %s should fail", tc.patch)
+		}
+	}
+}
+
+func TestParseJSONPatch(t *testing.T) {
+	invalidPatches := []string{
+		`{"op":"add","path":"/a","value":1}`,
+		`[{"op":"append","path":"/a","value":1}]`,
+		`[{"op":"add","path":"a","value":1}]`,
+		`[{"op":"add","path":"/a"}]`,
+		`[{"op":"move","path":"/a","from":"b"}]`,
+	}
+
+	for _, patch := range invalidPatches {
+		_, err := ParseJSONPatch([]byte(patch))
+		ut.Assert(t, err != nil, "patch %s should be invalid", patch)
+	}
+}
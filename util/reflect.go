@@ -2,7 +2,9 @@ package util
 
 import (
 	"fmt"
+	"net/netip"
 	"reflect"
+	"time"
 )
 
 type Kind string
@@ -13,24 +15,43 @@ const (
 	Struct Kind = "struct"
 	Bool   Kind = "bool"
 	String Kind = "string"
+	Float  Kind = "float"
+	Time   Kind = "time"
+	IP     Kind = "ip"
 
 	IntSlice       Kind = "intSlice"
 	UintSlice      Kind = "uintSlice"
 	StringSlice    Kind = "stringSlice"
 	BoolSlice      Kind = "boolSlice"
+	FloatSlice     Kind = "floatSlice"
+	TimeSlice      Kind = "timeSlice"
+	IPSlice        Kind = "ipSlice"
 	StructSlice    Kind = "structSlice"
 	StructPtrSlice Kind = "structPtrSlice"
 
 	StructPtr Kind = "structPtr"
 	BoolPtr   Kind = "boolPtr"
+	IntPtr    Kind = "intPtr"
+	UintPtr   Kind = "uintPtr"
+	StringPtr Kind = "stringPtr"
+	FloatPtr  Kind = "floatPtr"
+	TimePtr   Kind = "timePtr"
+	IPPtr     Kind = "ipPtr"
 
 	StringIntMap       Kind = "stringIntMap"
 	StringUintMap      Kind = "stringUintMap"
 	StringStringMap    Kind = "stringStringMap"
+	StringFloatMap     Kind = "stringFloatMap"
+	StringIPMap        Kind = "stringIPMap"
 	StringStructMap    Kind = "stringStructMap"
 	StringStructPtrMap Kind = "stringStructPtrMap"
 )
 
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	ipType   = reflect.TypeOf(netip.Addr{})
+)
+
 func Inspect(typ reflect.Type) Kind {
 	k := typ.Kind()
 	switch k {
@@ -42,20 +63,40 @@ func Inspect(typ reflect.Type) Kind {
 		return String
 	case reflect.Bool:
 		return Bool
+	case reflect.Float32, reflect.Float64:
+		return Float
 	case reflect.Struct:
-		return Struct
+		return structKind(typ)
 	case reflect.Ptr:
-		vk := typ.Elem().Kind()
-		if vk == reflect.Struct {
+		switch structKind(typ.Elem()) {
+		case Struct:
 			return StructPtr
+		case Time:
+			return TimePtr
+		case IP:
+			return IPPtr
 		}
-		if vk == reflect.Bool {
-			return BoolPtr
+		vkk, isPrimary := PrimartyKind(typ.Elem().Kind())
+		if isPrimary {
+			switch vkk {
+			case Bool:
+				return BoolPtr
+			case Int:
+				return IntPtr
+			case Uint:
+				return UintPtr
+			case String:
+				return StringPtr
+			case Float:
+				return FloatPtr
+			}
 		}
 	case reflect.Map:
 		if typ.Key().Kind() == reflect.String {
 			vk := typ.Elem().Kind()
-			if vk == reflect.Struct {
+			if ek := structKind(typ.Elem()); ek == IP {
+				return StringIPMap
+			} else if ek == Struct {
 				return StringStructMap
 			} else if vk == reflect.Ptr {
 				vk = typ.Elem().Elem().Kind()
@@ -72,6 +113,8 @@ func Inspect(typ reflect.Type) Kind {
 						return StringUintMap
 					case String:
 						return StringStringMap
+					case Float:
+						return StringFloatMap
 					}
 				}
 			}
@@ -79,7 +122,11 @@ func Inspect(typ reflect.Type) Kind {
 		return Kind(fmt.Sprintf("%s%sMap", typ.Name(), typ.Elem().Name()))
 	case reflect.Slice:
 		ek := typ.Elem().Kind()
-		if ek == reflect.Struct {
+		if sk := structKind(typ.Elem()); sk == Time {
+			return TimeSlice
+		} else if sk == IP {
+			return IPSlice
+		} else if sk == Struct {
 			return StructSlice
 		} else if ek == reflect.Ptr {
 			ek = typ.Elem().Elem().Kind()
@@ -96,6 +143,10 @@ func Inspect(typ reflect.Type) Kind {
 					return UintSlice
 				case String:
 					return StringSlice
+				case Bool:
+					return BoolSlice
+				case Float:
+					return FloatSlice
 				}
 			}
 		}
@@ -114,7 +165,24 @@ func PrimartyKind(k reflect.Kind) (Kind, bool) {
 		return String, true
 	case reflect.Bool:
 		return Bool, true
+	case reflect.Float32, reflect.Float64:
+		return Float, true
 	default:
 		return "", false
 	}
 }
+
+// time.Time and netip.Addr are leaf values in json, other structs have
+// fields, kind is empty if typ isn't a struct
+func structKind(typ reflect.Type) Kind {
+	switch {
+	case typ.Kind() != reflect.Struct:
+		return ""
+	case typ == timeType:
+		return Time
+	case typ == ipType:
+		return IP
+	default:
+		return Struct
+	}
+}
@@ -2,8 +2,10 @@ package util
 
 import (
 	//"fmt"
+	"net/netip"
 	"reflect"
 	"testing"
+	"time"
 
 	ut "github.com/linkingthing/cement/unittest"
 )
@@ -46,4 +48,39 @@ func TestValueKind(t *testing.T) {
 	ut.Equal(t, StringStructPtrMap, Inspect(reflect.TypeOf(v)))
 	v = map[string]MyFlag{}
 	ut.Equal(t, StringStringMap, Inspect(reflect.TypeOf(v)))
+
+	v = float32(1)
+	ut.Equal(t, Float, Inspect(reflect.TypeOf(v)))
+	v = time.Time{}
+	ut.Equal(t, Time, Inspect(reflect.TypeOf(v)))
+	v = netip.Addr{}
+	ut.Equal(t, IP, Inspect(reflect.TypeOf(v)))
+
+	v = []float64{}
+	ut.Equal(t, FloatSlice, Inspect(reflect.TypeOf(v)))
+	v = []bool{}
+	ut.Equal(t, BoolSlice, Inspect(reflect.TypeOf(v)))
+	v = []time.Time{}
+	ut.Equal(t, TimeSlice, Inspect(reflect.TypeOf(v)))
+	v = []netip.Addr{}
+	ut.Equal(t, IPSlice, Inspect(reflect.TypeOf(v)))
+	v = map[string]float32{}
+	ut.Equal(t, StringFloatMap, Inspect(reflect.TypeOf(v)))
+	v = map[string]netip.Addr{}
+	ut.Equal(t, StringIPMap, Inspect(reflect.TypeOf(v)))
+
+	v = new(int8)
+	ut.Equal(t, IntPtr, Inspect(reflect.TypeOf(v)))
+	v = new(uint)
+	ut.Equal(t, UintPtr, Inspect(reflect.TypeOf(v)))
+	v = new(MyFlag)
+	ut.Equal(t, StringPtr, Inspect(reflect.TypeOf(v)))
+	v = new(bool)
+	ut.Equal(t, BoolPtr, Inspect(reflect.TypeOf(v)))
+	v = new(float64)
+	ut.Equal(t, FloatPtr, Inspect(reflect.TypeOf(v)))
+	v = &time.Time{}
+	ut.Equal(t, TimePtr, Inspect(reflect.TypeOf(v)))
+	v = &netip.Addr{}
+	ut.Equal(t, IPPtr, Inspect(reflect.TypeOf(v)))
 }
@@ -0,0 +1,22 @@
+package util
+
+import "strings"
+
+const patternTagPrefix = "pattern="
+
+// SplitRestTag split rest tag by comma, the regular expression of pattern
+// may contain comma, so pattern should be the last one, everything after
+// pattern= is its value
+func SplitRestTag(rest string) []string {
+	var tags []string
+	for rest != "" {
+		if strings.HasPrefix(rest, patternTagPrefix) {
+			return append(tags, rest)
+		}
+
+		tag, remain, _ := strings.Cut(rest, ",")
+		tags = append(tags, tag)
+		rest = remain
+	}
+	return tags
+}
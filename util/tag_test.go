@@ -0,0 +1,14 @@
+package util
+
+import (
+	"testing"
+
+	ut "github.com/linkingthing/cement/unittest"
+)
+
+func TestSplitRestTag(t *testing.T) {
+	ut.Equal(t, SplitRestTag(""), []string(nil))
+	ut.Equal(t, SplitRestTag("required=true,minLen=2"), []string{"required=true", "minLen=2"})
+	ut.Equal(t, SplitRestTag("required=true,pattern=^[a-z]{1,3}$"), []string{"required=true", "pattern=^[a-z]{1,3}$"})
+	ut.Equal(t, SplitRestTag("pattern=^a,b$"), []string{"pattern=^a,b$"})
+}
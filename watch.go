@@ -0,0 +1,262 @@
+package gorest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+)
+
+type EventType string
+
+const (
+	EventAdded    EventType = "ADDED"
+	EventModified EventType = "MODIFIED"
+	EventDeleted  EventType = "DELETED"
+)
+
+const (
+	ContentTypeEventStream = "text/event-stream"
+	ContentTypeNDJSON      = "application/x-ndjson"
+)
+
+const defaultWatchChanSize = 100
+
+// heartbeat keeps idle watch connections from being closed by proxies
+var watchHeartbeatInterval = 30 * time.Second
+
+type WatchEvent struct {
+	Type     EventType         `json:"type"`
+	Resource resource.Resource `json:"resource"`
+}
+
+// EventSource delivers resource events to watchers, events are grouped
+// by the key of the collection which the resource belongs to
+type EventSource interface {
+	Publish(key string, event *WatchEvent)
+	//the returned channel is closed if the watcher is too slow, call the
+	//returned function to stop watching
+	Watch(key string) (<-chan *WatchEvent, func())
+}
+
+// key of a collection is the types and ids of its parents and its type,
+// like cluster/c1/node
+func CollectionKey(r resource.Resource) string {
+	segments := []string{r.GetType()}
+	for parent := r.GetParent(); parent != nil; parent = parent.GetParent() {
+		segments = append([]string{parent.GetType(), parent.GetID()}, segments...)
+	}
+	return strings.Join(segments, "/")
+}
+
+type memoryEventSource struct {
+	lock     sync.Mutex
+	watchers map[string]map[chan *WatchEvent]struct{}
+}
+
+// NewMemoryEventSource return an event source which only delivers
+// events in current process, changes made by other processes aren't
+// seen by its watchers
+func NewMemoryEventSource() EventSource {
+	return &memoryEventSource{
+		watchers: make(map[string]map[chan *WatchEvent]struct{}),
+	}
+}
+
+func (s *memoryEventSource) Publish(key string, event *WatchEvent) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for ch := range s.watchers[key] {
+		select {
+		case ch <- event:
+		default:
+			s.removeWatcher(key, ch)
+		}
+	}
+}
+
+func (s *memoryEventSource) Watch(key string) (<-chan *WatchEvent, func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	ch := make(chan *WatchEvent, defaultWatchChanSize)
+	watchers, ok := s.watchers[key]
+	if ok == false {
+		watchers = make(map[chan *WatchEvent]struct{})
+		s.watchers[key] = watchers
+	}
+	watchers[ch] = struct{}{}
+
+	return ch, func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		s.removeWatcher(key, ch)
+	}
+}
+
+func (s *memoryEventSource) removeWatcher(key string, ch chan *WatchEvent) {
+	watchers := s.watchers[key]
+	if _, ok := watchers[ch]; ok == false {
+		return
+	}
+
+	delete(watchers, ch)
+	close(ch)
+	if len(watchers) == 0 {
+		delete(s.watchers, key)
+	}
+}
+
+func publishEvent(ctx *resource.Context, events EventSource, typ EventType, r resource.Resource) {
+	if events != nil {
+		events.Publish(CollectionKey(ctx.Resource), &WatchEvent{Type: typ, Resource: r})
+	}
+}
+
+// current resources are sent as ADDED events first, then the changes
+func handleWatch(ctx *resource.Context, events EventSource) *goresterr.APIError {
+	schema := ctx.Resource.GetSchema()
+	handler := schema.GetHandler().GetListHandler()
+	if handler == nil {
+		return goresterr.NewAPIError(goresterr.NotFound,
+			*goresterr.NewLocalizedErrorMessage("no found for list", goresterr.MessageNoHandler, goresterr.Params{"method": "list"}))
+	}
+
+	if events == nil {
+		return goresterr.NewAPIError(goresterr.MethodNotAllowed,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageWatchNotSupported, nil))
+	}
+
+	//filters apply to the initial list but not the events, reject them
+	//instead of sending the resources which don't match
+	if filters := ctx.GetFilters(); len(filters) > 0 {
+		return goresterr.NewAPIError(goresterr.InvalidFormat,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageInvalidQuery, goresterr.Params{"name": filters[0].Name}))
+	}
+
+	flusher, ok := ctx.Response.(http.Flusher)
+	if ok == false {
+		return goresterr.NewAPIError(goresterr.ServerError,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageStreamingNotSupported, nil))
+	}
+
+	ch, stop := events.Watch(CollectionKey(ctx.Resource))
+	defer stop()
+
+	data, err_ := handler(ctx)
+	if err_ != nil {
+		return err_
+	}
+
+	rc, err := resource.NewResourceCollection(ctx, data)
+	if err != nil {
+		return goresterr.NewAPIError(goresterr.ServerError,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageInvalidListResult, goresterr.Params{"reason": err.Error()}))
+	}
+
+	httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
+	if err := schema.AddLinksToResourceCollection(rc, httpSchemeAndHost); err != nil {
+		return goresterr.NewAPIError(goresterr.ServerError,
+			*goresterr.NewLocalizedErrorMessage("", goresterr.MessageGenerateLinksFailed, goresterr.Params{"reason": err.Error()}))
+	}
+
+	isEventStream := strings.Contains(ctx.Request.Header.Get("Accept"), ContentTypeEventStream)
+	if isEventStream {
+		ctx.Response.Header().Set(ContentTypeKey, ContentTypeEventStream)
+	} else {
+		ctx.Response.Header().Set(ContentTypeKey, ContentTypeNDJSON)
+	}
+	ctx.Response.Header().Set("Cache-Control", "no-cache")
+	ctx.Response.WriteHeader(http.StatusOK)
+
+	for _, r := range rc.GetResources() {
+		if err := writeWatchEvent(ctx.Response, &WatchEvent{Type: EventAdded, Resource: r}, isEventStream); err != nil {
+			return nil
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(watchHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return nil
+		case <-heartbeat.C:
+			if err := writeWatchHeartbeat(ctx.Response, isEventStream); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		case event, ok := <-ch:
+			if ok == false {
+				return nil
+			}
+
+			event, ok = convertWatchEvent(event, schema, httpSchemeAndHost)
+			if ok == false {
+				continue
+			}
+
+			if err := writeWatchEvent(ctx.Response, event, isEventStream); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// events published by the requests to other versions are converted to
+// the kind of the watched schema, events which can't be converted are
+// dropped
+func convertWatchEvent(event *WatchEvent, schema resource.Schema, httpSchemeAndHost string) (*WatchEvent, bool) {
+	r, err := schema.ConvertResource(event.Resource)
+	if err != nil {
+		return nil, false
+	} else if r == event.Resource {
+		return event, true
+	}
+
+	if err := schema.AddLinksToResource(r, httpSchemeAndHost); err != nil {
+		return nil, false
+	}
+	return &WatchEvent{Type: event.Type, Resource: r}, true
+}
+
+// writeOnly fields of the resource are omitted
+func writeWatchEvent(w http.ResponseWriter, event *WatchEvent, isEventStream bool) error {
+	r, err := omitWriteOnlyFields(event.Resource)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(struct {
+		Type     EventType   `json:"type"`
+		Resource interface{} `json:"resource"`
+	}{event.Type, r})
+	if err != nil {
+		return err
+	}
+
+	if isEventStream {
+		_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+	} else {
+		_, err = fmt.Fprintf(w, "%s\n", data)
+	}
+	return err
+}
+
+// sse clients ignore comment lines and ndjson clients ignore empty lines
+func writeWatchHeartbeat(w http.ResponseWriter, isEventStream bool) error {
+	var err error
+	if isEventStream {
+		_, err = fmt.Fprint(w, ": heartbeat\n\n")
+	} else {
+		_, err = fmt.Fprint(w, "\n")
+	}
+	return err
+}
@@ -0,0 +1,194 @@
+package gorest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ut "github.com/linkingthing/cement/unittest"
+	goresterr "github.com/linkingthing/gorest/error"
+	"github.com/linkingthing/gorest/resource"
+	"github.com/linkingthing/gorest/resource/schema"
+)
+
+type Qux struct {
+	resource.ResourceBase `json:",inline"`
+	Name                  string `json:"name" rest:"required=true"`
+}
+
+type quxHandler struct {
+	lock sync.Mutex
+	quxs map[string]*Qux
+}
+
+func (h *quxHandler) Create(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	qux := ctx.Resource.(*Qux)
+	qux.SetID(qux.Name)
+	h.quxs[qux.Name] = qux
+	return qux, nil
+}
+
+func (h *quxHandler) List(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	var quxs []*Qux
+	for _, qux := range h.quxs {
+		quxs = append(quxs, qux)
+	}
+	return quxs, nil
+}
+
+func (h *quxHandler) Delete(ctx *resource.Context) *goresterr.APIError {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	delete(h.quxs, ctx.Resource.GetID())
+	return nil
+}
+
+type watchEvent struct {
+	Type     EventType `json:"type"`
+	Resource struct {
+		ID    string            `json:"id"`
+		Name  string            `json:"name"`
+		Links map[string]string `json:"links"`
+	} `json:"resource"`
+}
+
+func TestWatch(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	q1 := &Qux{Name: "q1"}
+	q1.SetID("q1")
+	schemas.MustImport(&version, Qux{}, &quxHandler{quxs: map[string]*Qux{"q1": q1}})
+	server := httptest.NewServer(NewAPIServer(schemas))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/apis/testing/v1/quxes?watch=true")
+	ut.Assert(t, err == nil, "")
+	defer resp.Body.Close()
+	ut.Equal(t, resp.StatusCode, http.StatusOK)
+	ut.Equal(t, resp.Header.Get(ContentTypeKey), ContentTypeNDJSON)
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() watchEvent {
+		line, err := reader.ReadBytes('\n')
+		ut.Assert(t, err == nil, "")
+		var event watchEvent
+		ut.Assert(t, json.Unmarshal(line, &event) == nil, "invalid event %s", line)
+		return event
+	}
+
+	event := readEvent()
+	ut.Equal(t, event.Type, EventAdded)
+	ut.Equal(t, event.Resource.ID, "q1")
+
+	createResp, err := http.Post(server.URL+"/apis/testing/v1/quxes", "application/json", bytes.NewBufferString(`{"name":"q2"}`))
+	ut.Assert(t, err == nil, "")
+	createResp.Body.Close()
+	ut.Equal(t, createResp.StatusCode, http.StatusCreated)
+	event = readEvent()
+	ut.Equal(t, event.Type, EventAdded)
+	ut.Equal(t, event.Resource.Name, "q2")
+	ut.Assert(t, strings.HasSuffix(event.Resource.Links["remove"], "/apis/testing/v1/quxes/q2"), "")
+
+	req, _ := http.NewRequest(http.MethodDelete, server.URL+"/apis/testing/v1/quxes/q1", nil)
+	deleteResp, err := http.DefaultClient.Do(req)
+	ut.Assert(t, err == nil, "")
+	deleteResp.Body.Close()
+	event = readEvent()
+	ut.Equal(t, event.Type, EventDeleted)
+	ut.Equal(t, event.Resource.ID, "q1")
+	ut.Assert(t, event.Resource.Links["remove"] != "", "")
+}
+
+func TestWatchEventStream(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Qux{}, &quxHandler{quxs: map[string]*Qux{}})
+	server := httptest.NewServer(NewAPIServer(schemas))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/apis/testing/v1/quxes?watch=true", nil)
+	req.Header.Set("Accept", ContentTypeEventStream)
+	resp, err := http.DefaultClient.Do(req)
+	ut.Assert(t, err == nil, "")
+	defer resp.Body.Close()
+	ut.Equal(t, resp.Header.Get(ContentTypeKey), ContentTypeEventStream)
+
+	createResp, err := http.Post(server.URL+"/apis/testing/v1/quxes", "application/json", bytes.NewBufferString(`{"name":"q3"}`))
+	ut.Assert(t, err == nil, "")
+	createResp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, line, "event: ADDED\n")
+	line, err = reader.ReadString('\n')
+	ut.Assert(t, err == nil, "")
+	ut.Assert(t, strings.HasPrefix(line, "data: {"), "")
+}
+
+func TestWatchWithFilter(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Qux{}, &quxHandler{quxs: map[string]*Qux{}})
+	server := httptest.NewServer(NewAPIServer(schemas))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/apis/testing/v1/quxes?watch=true&name=q1")
+	ut.Assert(t, err == nil, "")
+	defer resp.Body.Close()
+	ut.Equal(t, resp.StatusCode, goresterr.InvalidFormat.Status)
+}
+
+func TestWatchHeartbeat(t *testing.T) {
+	interval := watchHeartbeatInterval
+	watchHeartbeatInterval = 10 * time.Millisecond
+	defer func() { watchHeartbeatInterval = interval }()
+
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Qux{}, &quxHandler{quxs: map[string]*Qux{}})
+	server := httptest.NewServer(NewAPIServer(schemas))
+	defer server.Close()
+
+	for accept, heartbeat := range map[string]string{
+		ContentTypeEventStream: ": heartbeat\n",
+		ContentTypeNDJSON:      "\n",
+	} {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/apis/testing/v1/quxes?watch=true", nil)
+		req.Header.Set("Accept", accept)
+		resp, err := http.DefaultClient.Do(req)
+		ut.Assert(t, err == nil, "")
+		line, err := bufio.NewReader(resp.Body).ReadString('\n')
+		resp.Body.Close()
+		ut.Assert(t, err == nil, "")
+		ut.Equal(t, line, heartbeat)
+	}
+}
+
+func TestMemoryEventSource(t *testing.T) {
+	events := NewMemoryEventSource()
+	ch1, stop1 := events.Watch("cluster/c1/node")
+	ch2, stop2 := events.Watch("cluster/c2/node")
+	defer stop2()
+
+	events.Publish("cluster/c1/node", &WatchEvent{Type: EventAdded})
+	ut.Equal(t, (<-ch1).Type, EventAdded)
+	ut.Equal(t, len(ch2), 0)
+
+	stop1()
+	_, ok := <-ch1
+	ut.Assert(t, ok == false, "channel should be closed after stop")
+	stop1()
+
+	//slow watcher is dropped
+	for i := 0; i <= defaultWatchChanSize; i++ {
+		events.Publish("cluster/c2/node", &WatchEvent{Type: EventModified})
+	}
+	for range ch2 {
+	}
+}